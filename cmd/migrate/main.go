@@ -0,0 +1,137 @@
+// migrate 数据库迁移命令行工具
+//
+// 用法:
+//
+//	migrate [选项] up              执行所有待执行的迁移
+//	migrate [选项] down [步数]      回滚迁移，默认回滚1步
+//	migrate [选项] status          查看迁移状态
+//	migrate [选项] plan            打印待执行迁移的SQL，不修改数据库
+//	migrate [选项] new <名称>       创建新的SQL迁移文件
+//
+// 数据库连接从配置文件读取，配置结构与 orm.Config 相同，例如:
+//
+//	database:
+//	  type: mysql
+//	  host: localhost
+//	  port: 3306
+//	  username: root
+//	  password: password
+//	  database: app
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/fastgox/utils/config"
+	"github.com/fastgox/utils/orm"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	configKey := flag.String("key", "database", "数据库配置所在的配置键")
+	envPrefix := flag.String("env-prefix", "", "环境变量前缀，设置后可用环境变量覆盖配置")
+	dir := flag.String("dir", "migrations", "SQL迁移文件目录")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(*configPath, *configKey, *envPrefix, *dir, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage 打印用法
+func usage() {
+	fmt.Fprintf(os.Stderr, `用法: migrate [选项] <命令> [参数]
+
+命令:
+  up              执行所有待执行的迁移
+  down [步数]      回滚迁移，默认回滚1步
+  status          查看迁移状态
+  plan            打印待执行迁移的SQL，不修改数据库
+  new <名称>       创建新的SQL迁移文件
+
+选项:
+`)
+	flag.PrintDefaults()
+}
+
+// run 执行命令
+func run(configPath, configKey, envPrefix, dir string, args []string) error {
+	if len(args) == 0 {
+		usage()
+		return fmt.Errorf("缺少命令")
+	}
+
+	command := args[0]
+	if command == "new" {
+		if len(args) < 2 {
+			return fmt.Errorf("new 命令需要迁移名称")
+		}
+		upPath, downPath, err := orm.CreateSQLMigrationFiles(dir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ 已创建迁移文件:\n  %s\n  %s\n", upPath, downPath)
+		return nil
+	}
+
+	db, err := connect(configPath, configKey, envPrefix)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	manager := orm.NewMigrationManager(db)
+	if err := manager.LoadSQLMigrationsFromDir(dir); err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		return manager.Run()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("无效的回滚步数: %s", args[1])
+			}
+		}
+		return manager.Rollback(steps)
+	case "status":
+		return manager.Status()
+	case "plan":
+		return manager.DryRun().Run()
+	default:
+		usage()
+		return fmt.Errorf("未知命令: %s", command)
+	}
+}
+
+// connect 从配置文件读取数据库配置并连接
+func connect(configPath, configKey, envPrefix string) (*orm.ORM, error) {
+	opts := config.DefaultOptions()
+	opts.ConfigPath = configPath
+	if envPrefix != "" {
+		opts.EnvPrefix = envPrefix
+		opts.AutomaticEnv = true
+	}
+	if err := config.InitWithOptions(opts); err != nil {
+		return nil, err
+	}
+
+	dbConfig := orm.DefaultConfig()
+	if err := config.UnmarshalKey(configKey, dbConfig); err != nil {
+		return nil, fmt.Errorf("读取数据库配置失败: %w", err)
+	}
+
+	db := orm.New(dbConfig)
+	if err := db.Connect(); err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	return db, nil
+}
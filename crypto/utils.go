@@ -9,6 +9,7 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"time"
 
 	"golang.org/x/crypto/pbkdf2"
 )
@@ -310,6 +311,26 @@ func GenerateUUID() (string, error) {
 		bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
 }
 
+// GenerateUUIDv7 生成按时间排序的UUID（版本7），前48位为毫秒级Unix时间戳
+func GenerateUUIDv7() (string, error) {
+	bytes, err := GenerateRandomBytes(16)
+	if err != nil {
+		return "", err
+	}
+
+	ms := uint64(time.Now().UnixMilli())
+	for i := 0; i < 6; i++ {
+		bytes[i] = byte(ms >> (40 - 8*i))
+	}
+
+	// 设置版本和变体位
+	bytes[6] = (bytes[6] & 0x0f) | 0x70 // 版本7
+	bytes[8] = (bytes[8] & 0x3f) | 0x80 // 变体10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x",
+		bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
+}
+
 // SplitBytes 将字节切片分割成指定大小的块
 func SplitBytes(data []byte, chunkSize int) [][]byte {
 	if chunkSize <= 0 {
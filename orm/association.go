@@ -0,0 +1,196 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Association 多对多关联管理器，用于维护中间表记录
+type Association struct {
+	qb          *queryBuilder
+	ownerKey    interface{}
+	relation    Relation
+	relatedType reflect.Type
+	err         error
+}
+
+// Association 获取当前模型指定多对多关联的管理器，模型需通过Model(&record)传入且主键非零
+func (qb *queryBuilder) Association(name string) *Association {
+	// 中间表的读取始终使用主库，避免副本延迟导致重复添加
+	qb.usePrimary = true
+	association := &Association{qb: qb}
+
+	owner := reflect.ValueOf(qb.model)
+	if owner.Kind() != reflect.Ptr || owner.Elem().Kind() != reflect.Struct {
+		association.err = fmt.Errorf("关联管理需要通过Model传入结构体指针")
+		return association
+	}
+	owner = owner.Elem()
+
+	_, relatedType, relation, err := qb.orm.lookupRelation(owner, name)
+	if err != nil {
+		association.err = err
+		return association
+	}
+	if relation.Type != Many2Many {
+		association.err = fmt.Errorf("关联 %s 不是多对多关联", name)
+		return association
+	}
+
+	key := findFieldByColumn(owner, relation.References)
+	if !key.IsValid() || isZeroValue(key) {
+		association.err = fmt.Errorf("模型 %s 的键 %s 为空", owner.Type().Name(), relation.References)
+		return association
+	}
+
+	association.ownerKey = key.Interface()
+	association.relation = relation
+	association.relatedType = relatedType
+	return association
+}
+
+// Attach 添加关联，已存在的关联会被忽略；参数可以是关联模型或其主键值
+func (a *Association) Attach(values ...interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+
+	existing, err := a.attachedKeys()
+	if err != nil {
+		return err
+	}
+
+	d := a.qb.dialect()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)",
+		quoteTableRef(d, a.relation.JoinTable), quoteIdentifier(d, a.relation.JoinForeignKey), quoteIdentifier(d, a.relation.JoinReferences))
+	for _, key := range a.relatedKeys(values) {
+		k := keyString(key)
+		if existing[k] {
+			continue
+		}
+		if _, err := a.qb.exec(query, a.ownerKey, key); err != nil {
+			return err
+		}
+		existing[k] = true
+	}
+	return nil
+}
+
+// Append 添加关联，Attach的别名
+func (a *Association) Append(values ...interface{}) error {
+	return a.Attach(values...)
+}
+
+// Replace 将关联替换为给定集合，Sync的别名
+func (a *Association) Replace(values ...interface{}) error {
+	return a.Sync(values...)
+}
+
+// Clear 移除当前模型的全部关联，不删除关联模型的记录
+func (a *Association) Clear() error {
+	return a.Detach()
+}
+
+// Detach 移除关联，不传参数时移除当前模型的全部关联
+func (a *Association) Detach(values ...interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+
+	pivot := a.qb.tableBuilder(a.relation.JoinTable)
+	pivot.whereEqual(a.relation.JoinForeignKey, a.ownerKey)
+	if len(values) > 0 {
+		keys := a.relatedKeys(values)
+		if len(keys) == 0 {
+			return nil
+		}
+		pivot.WhereIn(a.relation.JoinReferences, keys...)
+	}
+	return pivot.Delete()
+}
+
+// Sync 将关联同步为给定集合：移除不在集合中的关联并添加缺失的关联
+// 不在事务中时自动开启事务
+func (a *Association) Sync(values ...interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.qb.tx != nil {
+		return a.sync(values)
+	}
+
+	tx, err := a.qb.orm.BeginTx(a.qb.context(), nil)
+	if err != nil {
+		return err
+	}
+
+	txQuery := *a.qb
+	txQuery.tx = tx
+	txAssociation := *a
+	txAssociation.qb = &txQuery
+	if err := txAssociation.sync(values); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// sync 执行同步
+func (a *Association) sync(values []interface{}) error {
+	keys := a.relatedKeys(values)
+
+	pivot := a.qb.tableBuilder(a.relation.JoinTable)
+	pivot.whereEqual(a.relation.JoinForeignKey, a.ownerKey)
+	if len(keys) > 0 {
+		pivot.WhereNotIn(a.relation.JoinReferences, keys...)
+	}
+	if err := pivot.Delete(); err != nil {
+		return err
+	}
+
+	return a.Attach(keys...)
+}
+
+// attachedKeys 查询当前模型已关联的键
+func (a *Association) attachedKeys() (map[string]bool, error) {
+	pairs, err := a.qb.pivotPairs(a.relation, []interface{}{a.ownerKey})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		keys[keyString(pair[1])] = true
+	}
+	return keys, nil
+}
+
+// relatedKeys 将关联模型或主键值转换为主键值列表，并去除重复项
+func (a *Association) relatedKeys(values []interface{}) []interface{} {
+	keyColumn := relatedKeyColumn(a.relatedType)
+	seen := make(map[string]bool)
+
+	var keys []interface{}
+	for _, value := range values {
+		key := value
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			field := findFieldByColumn(v, keyColumn)
+			if !field.IsValid() {
+				continue
+			}
+			key = field.Interface()
+		}
+
+		k := keyString(key)
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
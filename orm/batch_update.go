@@ -0,0 +1,163 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// batchUpdateRow 批量更新中的一条记录
+type batchUpdateRow struct {
+	key    interface{}
+	values map[string]interface{}
+}
+
+// UpdateBatch 按键列批量更新记录，每个分块生成一条 UPDATE ... SET col = CASE key WHEN ... END 语句
+// keyColumn为空时使用模型的单列主键；主键列和键列不会被更新，已有条件与键列的IN条件以AND组合
+// 记录数超过批次大小时分块执行，不在事务中时自动开启事务保证整体更新，返回受影响的总行数
+func (qb *queryBuilder) UpdateBatch(data interface{}, keyColumn string) (int64, error) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("UpdateBatch需要结构体切片，实际为 %T", data)
+	}
+	if v.Len() == 0 {
+		return 0, nil
+	}
+	if keyColumn == "" {
+		primary := primaryKeyColumns(data)
+		if len(primary) != 1 {
+			return 0, fmt.Errorf("UpdateBatch需要指定键列或在模型中标记单列主键")
+		}
+		keyColumn = primary[0]
+	}
+
+	if err := qb.beforeUpdate(data); err != nil {
+		return 0, err
+	}
+	data = qb.stampTimestamps(data, true)
+	columns, rows, err := qb.batchUpdateRows(reflect.Indirect(reflect.ValueOf(data)), keyColumn)
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("没有需要更新的列")
+	}
+
+	batchSize := qb.getBatchSize()
+	var affected int64
+	if len(rows) <= batchSize || qb.tx != nil || qb.dryRun != nil {
+		affected, err = qb.updateBatchChunks(keyColumn, columns, rows, batchSize)
+	} else {
+		affected, err = qb.updateBatchInTx(keyColumn, columns, rows, batchSize)
+	}
+	if err != nil {
+		return affected, err
+	}
+	return affected, qb.afterUpdate(data)
+}
+
+// updateBatchInTx 在新开启的事务中分块执行批量更新
+func (qb *queryBuilder) updateBatchInTx(keyColumn string, columns []string, rows []batchUpdateRow, batchSize int) (int64, error) {
+	tx, err := qb.orm.BeginTx(qb.context(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	txQuery := *qb
+	txQuery.tx = tx
+	affected, err := txQuery.updateBatchChunks(keyColumn, columns, rows, batchSize)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// batchUpdateRows 提取每条记录的键值和待更新列的值，返回按首次出现顺序排列的更新列
+func (qb *queryBuilder) batchUpdateRows(v reflect.Value, keyColumn string) ([]string, []batchUpdateRow, error) {
+	skipped := map[string]bool{keyColumn: true}
+	for _, column := range primaryKeyColumns(v.Interface()) {
+		skipped[column] = true
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	rows := make([]batchUpdateRow, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		itemColumns, itemValues := qb.extractColumnsAndValues(v.Index(i).Interface())
+		row := batchUpdateRow{values: make(map[string]interface{}, len(itemColumns))}
+		for j, column := range itemColumns {
+			if column == keyColumn {
+				row.key = itemValues[j]
+			}
+			if skipped[column] {
+				continue
+			}
+			row.values[column] = itemValues[j]
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+		if row.key == nil {
+			return nil, nil, fmt.Errorf("第%d条记录缺少键列 %s 的值", i+1, keyColumn)
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, nil
+}
+
+// updateBatchChunks 按批次大小分块执行批量更新
+func (qb *queryBuilder) updateBatchChunks(keyColumn string, columns []string, rows []batchUpdateRow, batchSize int) (int64, error) {
+	var total int64
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		query, args := qb.buildBatchUpdateSQL(keyColumn, columns, rows[start:end])
+		affected, err := qb.execAffected(query, args...)
+		if err != nil {
+			return total, err
+		}
+		total += affected
+	}
+	return total, nil
+}
+
+// buildBatchUpdateSQL 构建按键列取值的CASE表达式批量更新SQL
+// 记录中没有的列保留原值；ELSE子句同时使PostgreSQL能推断参数类型
+func (qb *queryBuilder) buildBatchUpdateSQL(keyColumn string, columns []string, rows []batchUpdateRow) (string, []interface{}) {
+	chunk := *qb
+	chunk.conditions = append([]QueryCondition{}, qb.conditions...)
+	keys := make([]interface{}, len(rows))
+	for i, row := range rows {
+		keys[i] = row.key
+	}
+	chunk.WhereIn(keyColumn, keys...)
+
+	dialect := qb.dialect()
+	quotedKey := quoteIdentifier(dialect, keyColumn)
+
+	var setParts []string
+	var args []interface{}
+	for _, column := range columns {
+		quoted := quoteIdentifier(dialect, column)
+		var expr strings.Builder
+		expr.WriteString(quoted + " = CASE " + quotedKey)
+		for _, row := range rows {
+			if value, ok := row.values[column]; ok {
+				expr.WriteString(" WHEN ? THEN ?")
+				args = append(args, row.key, value)
+			}
+		}
+		expr.WriteString(" ELSE " + quoted + " END")
+		setParts = append(setParts, expr.String())
+	}
+
+	whereClause, whereArgs := chunk.scoped().buildWhereClause()
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+		quoteTableRef(dialect, qb.tableName), strings.Join(setParts, ", "), whereClause)
+	return query, append(args, whereArgs...)
+}
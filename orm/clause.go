@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sqlClauses 按顺序拼接SQL子句，子句与其参数一同追加，保证占位符与参数顺序一致
+type sqlClauses struct {
+	parts []string
+	args  []interface{}
+}
+
+// add 追加子句及其参数，空子句被忽略
+func (c *sqlClauses) add(clause string, args []interface{}) {
+	if clause == "" {
+		return
+	}
+	c.parts = append(c.parts, clause)
+	c.args = append(c.args, args...)
+}
+
+// build 返回拼接后的SQL和参数
+func (c *sqlClauses) build() (string, []interface{}) {
+	return strings.Join(c.parts, " "), c.args
+}
+
+// groupClause GROUP BY表达式及其参数
+type groupClause struct {
+	expression string
+	args       []interface{}
+}
+
+// build 构建JOIN子句，表名及别名按方言加引号
+func (j JoinClause) build(d Dialect) string {
+	table := quoteTableRef(d, j.Table)
+	if j.Type == "CROSS" || j.Condition == "" {
+		return fmt.Sprintf("%s JOIN %s", j.Type, table)
+	}
+	return fmt.Sprintf("%s JOIN %s ON %s", j.Type, table, j.Condition)
+}
+
+// quoteTableRef 为表引用加引号，支持 "orders o" 和 "orders AS o" 形式的别名
+// 子查询等非简单标识符原样返回
+func quoteTableRef(d Dialect, table string) string {
+	fields := strings.Fields(table)
+	if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		fields = []string{fields[0], fields[2]}
+	}
+
+	switch {
+	case len(fields) == 1 && isSimpleIdentifier(fields[0]):
+		return quoteIdentifier(d, fields[0])
+	case len(fields) == 2 && isSimpleIdentifier(fields[0]) && isSimpleIdentifier(fields[1]):
+		return quoteIdentifier(d, fields[0]) + tableAliasKeyword(d) + quoteIdentifier(d, fields[1])
+	}
+	return table
+}
+
+// tableAliasKeyword 表和派生表别名前的关键字，Oracle不允许在表别名前使用AS
+func tableAliasKeyword(d Dialect) string {
+	if _, ok := d.(*OracleDialect); ok {
+		return " "
+	}
+	return " AS "
+}
+
+// quoteIdentifier 为标识符加引号，带点号的限定名（如 schema.table、o.user_id）逐段加引号
+func quoteIdentifier(d Dialect, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = d.Quote(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteIdentifiers 为多个标识符加引号并以逗号连接
+func quoteIdentifiers(d Dialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(d, name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// isSimpleIdentifier 是否为由字母、数字、下划线和点号组成的标识符
+func isSimpleIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '.' || unicode.IsLetter(r):
+		case unicode.IsDigit(r) && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteColumn 为列名加引号，函数调用、别名等表达式原样返回
+// 用于Select、GroupBy和聚合函数等同时接受列名和表达式的位置
+func quoteColumn(d Dialect, column string) string {
+	if isSimpleIdentifier(column) {
+		return quoteIdentifier(d, column)
+	}
+	return column
+}
+
+// orderDirection 规范化排序方向，只接受ASC和DESC，其余值按ASC处理，避免排序方向被用于注入
+func orderDirection(direction ...string) string {
+	if len(direction) > 0 && strings.EqualFold(strings.TrimSpace(direction[0]), "DESC") {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// comparisonOperators 允许在条件中使用的比较运算符
+var comparisonOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"LIKE": true, "NOT LIKE": true,
+}
+
+// comparisonOperator 校验比较运算符，不在白名单中的运算符按 = 处理
+func comparisonOperator(operator string) string {
+	operator = strings.ToUpper(strings.TrimSpace(operator))
+	if comparisonOperators[operator] {
+		return operator
+	}
+	return "="
+}
@@ -0,0 +1,83 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// valuerType driver.Valuer接口类型
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// isJSONColumn 字段是否声明为JSON列
+func isJSONColumn(tag FieldTag) bool {
+	return strings.EqualFold(tag.Type, "JSON")
+}
+
+// columnValue 获取字段写入数据库的值
+// 实现了driver.Valuer的字段交由驱动转换，JSON列序列化为字符串，nil的JSON字段写入NULL
+func columnValue(field *modelField, fieldValue reflect.Value) interface{} {
+	if fieldValue.Type().Implements(valuerType) {
+		return fieldValue.Interface()
+	}
+	if fieldValue.CanAddr() && reflect.PointerTo(fieldValue.Type()).Implements(valuerType) {
+		return fieldValue.Addr().Interface()
+	}
+
+	if !isJSONColumn(field.tag) {
+		return fieldValue.Interface()
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		if fieldValue.IsNil() {
+			return nil
+		}
+	}
+	data, err := json.Marshal(fieldValue.Interface())
+	if err != nil {
+		return errorValuer{fmt.Errorf("字段 %s 序列化为JSON失败: %w", field.field.Name, err)}
+	}
+	return string(data)
+}
+
+// errorValuer 在执行时返回错误的参数，用于延迟报告值转换错误
+type errorValuer struct {
+	err error
+}
+
+// Value 实现driver.Valuer
+func (v errorValuer) Value() (driver.Value, error) {
+	return nil, v.err
+}
+
+// jsonAssigner 返回JSON列的扫描目标及扫描后的反序列化函数
+func jsonAssigner(field reflect.Value, name string) (interface{}, fieldAssigner) {
+	var raw interface{}
+	return &raw, func() error {
+		var data []byte
+		switch v := raw.(type) {
+		case nil:
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return fmt.Errorf("JSON列 %s 的值类型 %T 无法反序列化", name, raw)
+		}
+
+		if len(data) == 0 || string(data) == "null" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal(data, target.Interface()); err != nil {
+			return fmt.Errorf("JSON列 %s 反序列化失败: %w", name, err)
+		}
+		field.Set(target.Elem())
+		return nil
+	}
+}
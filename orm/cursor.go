@@ -0,0 +1,212 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Cursor 流式游标，逐行从数据库读取结果，避免一次性加载全部数据
+type Cursor struct {
+	rows    *sql.Rows
+	columns []string
+	loc     *time.Location
+}
+
+// Next 移动到下一行，没有更多数据时返回false
+func (c *Cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan 将当前行扫描到结构体指针中
+func (c *Cursor) Scan(dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest必须是结构体指针")
+	}
+
+	elem := destValue.Elem()
+	elem.Set(reflect.Zero(elem.Type()))
+	return scanInto(c.rows, elem, c.columns, c.loc)
+}
+
+// Err 返回迭代过程中的错误
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+// Close 关闭游标
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
+
+// Cursor 执行查询并返回流式游标，调用方负责关闭
+func (qb *queryBuilder) Cursor() (*Cursor, error) {
+	query, args := qb.buildSelectSQL()
+
+	rows, err := qb.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return &Cursor{rows: rows, columns: columns, loc: qb.orm.location()}, nil
+}
+
+// Rows 执行查询并返回原始结果集，调用方负责关闭
+func (qb *queryBuilder) Rows() (*sql.Rows, error) {
+	query, args := qb.buildSelectSQL()
+	return qb.query(query, args...)
+}
+
+// Iterate 逐行读取结果，每行扫描到同一个模型结构体后调用fn，内存占用与结果集大小无关
+// 需要通过Model创建查询构建器；dest在下一行覆盖，需要保留时由fn复制；fn返回错误时停止
+func (qb *queryBuilder) Iterate(fn func(dest interface{}) error) error {
+	modelType := structType(qb.model)
+	if modelType == nil {
+		return fmt.Errorf("Iterate需要通过Model创建查询构建器")
+	}
+
+	cursor, err := qb.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	dest := reflect.New(modelType).Interface()
+	for cursor.Next() {
+		if err := cursor.Scan(dest); err != nil {
+			return err
+		}
+		if err := qb.afterFind(dest); err != nil {
+			return err
+		}
+		if err := fn(dest); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Chunk 按批次查询数据，每批结果写入dest（切片指针）后调用fn
+// 批次通过LIMIT/OFFSET获取，应配合OrderBy保证顺序稳定；fn返回错误时停止
+func (qb *queryBuilder) Chunk(size int, dest interface{}, fn func() error) error {
+	if size < 1 {
+		return fmt.Errorf("批次大小必须大于0")
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest必须是切片指针")
+	}
+	sliceValue := destValue.Elem()
+
+	for offset := 0; ; offset += size {
+		sliceValue.Set(reflect.MakeSlice(sliceValue.Type(), 0, size))
+
+		batch := *qb
+		batch.limitNum = size
+		batch.offsetNum = offset
+		if err := batch.Get(dest); err != nil {
+			return err
+		}
+
+		if sliceValue.Len() == 0 {
+			return nil
+		}
+
+		if err := fn(); err != nil {
+			return err
+		}
+
+		if sliceValue.Len() < size {
+			return nil
+		}
+	}
+}
+
+// FindInBatches 按主键分批查询数据，每批结果写入dest（切片指针）后调用fn，batch从1开始计数
+// 以 主键 > 上一批最后的主键 ORDER BY 主键 LIMIT batchSize 翻页，大表上不会因OFFSET变慢；
+// 已设置的排序和LIMIT/OFFSET被忽略，模型需要单列主键。
+// 不在事务中时每批的fn在独立事务中执行，返回错误时回滚该批并停止；已在事务中时传入当前事务
+func (qb *queryBuilder) FindInBatches(dest interface{}, batchSize int, fn func(tx Tx, batch int) error) error {
+	if batchSize < 1 {
+		return fmt.Errorf("批次大小必须大于0")
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest必须是切片指针")
+	}
+	sliceValue := destValue.Elem()
+
+	elemType := structType(dest)
+	if elemType == nil {
+		return fmt.Errorf("dest必须是结构体切片指针")
+	}
+	meta := metadataOf(elemType)
+	if len(meta.primary) != 1 {
+		return fmt.Errorf("FindInBatches需要模型有单列主键")
+	}
+	keyField := meta.fieldByColumn(meta.primary[0])
+	keyColumn := meta.primary[0]
+	if len(qb.joins) > 0 {
+		keyColumn = qb.tableName + "." + keyColumn
+	}
+
+	var conditions []QueryCondition
+	if len(qb.conditions) > 0 {
+		conditions = []QueryCondition{{Operator: "GROUP", Logic: "AND", Conditions: qb.conditions}}
+	}
+
+	var lastKey interface{}
+	for batch := 1; ; batch++ {
+		sliceValue.Set(reflect.MakeSlice(sliceValue.Type(), 0, batchSize))
+
+		page := *qb
+		page.orders = []OrderClause{{Column: keyColumn, Direction: "ASC"}}
+		page.limitNum = batchSize
+		page.offsetNum = 0
+		page.conditions = conditions
+		if lastKey != nil {
+			page.conditions = append(append([]QueryCondition{}, conditions...), QueryCondition{
+				Column:   keyColumn,
+				Operator: ">",
+				Value:    lastKey,
+				Logic:    "AND",
+			})
+		}
+		if err := page.Get(dest); err != nil {
+			return err
+		}
+
+		count := sliceValue.Len()
+		if count == 0 {
+			return nil
+		}
+		lastKey = reflect.Indirect(sliceValue.Index(count - 1)).FieldByIndex(keyField.index).Interface()
+
+		if err := qb.runBatch(batch, fn); err != nil {
+			return err
+		}
+		if count < batchSize {
+			return nil
+		}
+	}
+}
+
+// runBatch 执行一批数据的处理函数，不在事务中时为该批开启独立事务
+func (qb *queryBuilder) runBatch(batch int, fn func(tx Tx, batch int) error) error {
+	if qb.tx != nil {
+		return fn(qb.tx, batch)
+	}
+	return NewTransactionManager(qb.orm).WithTransactionContext(qb.context(), nil, func(tx Tx) error {
+		return fn(tx, batch)
+	})
+}
@@ -27,6 +27,10 @@ func (dm *DatabaseManager) GetDialect() Dialect {
 		return &SQLiteDialect{}
 	case SQLServer:
 		return &SQLServerDialect{}
+	case ClickHouse:
+		return &ClickHouseDialect{}
+	case Oracle:
+		return &OracleDialect{}
 	default:
 		return &MySQLDialect{} // 默认使用MySQL方言
 	}
@@ -41,10 +45,15 @@ type Dialect interface {
 	PrimaryKey() string
 	CreateTableSQL(tableName string, columns []ColumnDefinition) string
 	DropTableSQL(tableName string) string
+	TruncateTableSQL(tableName string) string
 	AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string
+	ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string
 	DropColumnSQL(tableName, columnName string) string
 	CreateIndexSQL(tableName, indexName string, columns []string, unique bool) string
 	DropIndexSQL(tableName, indexName string) string
+	Placeholder(index int) string
+	LimitOffsetSQL(limit, offset int) string
+	UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string
 }
 
 // ColumnDefinition 列定义
@@ -62,13 +71,118 @@ type ColumnDefinition struct {
 	Comment       string
 	ForeignKey    string
 	References    string
+	OnDelete      string // 外键的删除动作，如 CASCADE、SET NULL、RESTRICT
+	OnUpdate      string // 外键的更新动作
+}
+
+// TableOptions 表级选项，不支持的选项在对应方言中被忽略
+type TableOptions struct {
+	Engine    string // 存储引擎，MySQL如 InnoDB，ClickHouse如 ReplacingMergeTree(version)
+	Charset   string // MySQL默认字符集
+	Collation string // MySQL默认排序规则
+	Comment   string // 表注释，MySQL和ClickHouse写在建表语句中，PostgreSQL和Oracle通过COMMENT ON TABLE设置
+}
+
+// createTableStatements 生成带表级选项的建表语句，部分方言的表注释需要单独的语句
+func createTableStatements(d Dialect, tableName string, columns []ColumnDefinition, options TableOptions) []string {
+	createSQL := d.CreateTableSQL(tableName, columns)
+
+	switch d.(type) {
+	case *MySQLDialect:
+		if options.Engine != "" {
+			createSQL += " ENGINE = " + options.Engine
+		}
+		if options.Charset != "" {
+			createSQL += " DEFAULT CHARSET = " + options.Charset
+		}
+		if options.Collation != "" {
+			createSQL += " COLLATE = " + options.Collation
+		}
+		if options.Comment != "" {
+			createSQL += " COMMENT = " + d.QuoteString(options.Comment)
+		}
+	case *ClickHouseDialect:
+		if options.Engine != "" {
+			createSQL = strings.Replace(createSQL, " ENGINE = MergeTree()", " ENGINE = "+options.Engine, 1)
+		}
+		if options.Comment != "" {
+			createSQL += " COMMENT " + d.QuoteString(options.Comment)
+		}
+	case *PostgreSQLDialect, *OracleDialect:
+		if options.Comment != "" {
+			return []string{createSQL, fmt.Sprintf("COMMENT ON TABLE %s IS %s", d.Quote(tableName), d.QuoteString(options.Comment))}
+		}
+	}
+	return []string{createSQL}
+}
+
+// addColumnConstraints 生成新增列的约束，NOT NULL 仅在有默认值时添加，避免已有数据导致失败
+func addColumnConstraints(definition ColumnDefinition) string {
+	if definition.Default == nil {
+		return ""
+	}
+	constraints := fmt.Sprintf(" DEFAULT %v", definition.Default)
+	if definition.NotNull {
+		constraints = " NOT NULL" + constraints
+	}
+	return constraints
+}
+
+// modifyColumnConstraints 修改列时重新声明的非空约束和默认值
+func modifyColumnConstraints(definition ColumnDefinition) string {
+	var constraints string
+	if definition.NotNull {
+		constraints += " NOT NULL"
+	}
+	if definition.Default != nil {
+		constraints += fmt.Sprintf(" DEFAULT %v", definition.Default)
+	}
+	return constraints
+}
+
+// foreignKeyClauses 生成列定义中声明的外键约束及其删除、更新动作
+// Oracle不支持ON UPDATE，忽略更新动作
+func foreignKeyClauses(d Dialect, columns []ColumnDefinition) []string {
+	_, oracle := d.(*OracleDialect)
+
+	var clauses []string
+	for _, col := range columns {
+		table, column, ok := parseReferences(col.References)
+		if !ok {
+			continue
+		}
+		clause := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+			d.Quote(col.Name), d.Quote(table), d.Quote(column))
+		if col.OnDelete != "" {
+			clause += " ON DELETE " + col.OnDelete
+		}
+		if col.OnUpdate != "" && !oracle {
+			clause += " ON UPDATE " + col.OnUpdate
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// parseReferences 解析外键引用，支持 table.column 和 table(column) 两种写法
+func parseReferences(references string) (string, string, bool) {
+	if references == "" {
+		return "", "", false
+	}
+	if i := strings.Index(references, "("); i > 0 && strings.HasSuffix(references, ")") {
+		return references[:i], references[i+1 : len(references)-1], true
+	}
+	if i := strings.LastIndex(references, "."); i > 0 {
+		return references[:i], references[i+1:], true
+	}
+	return references, "id", true
 }
 
 // MySQLDialect MySQL方言
 type MySQLDialect struct{}
 
 func (d *MySQLDialect) Quote(name string) string {
-	return "`" + name + "`"
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
 
 func (d *MySQLDialect) QuoteString(s string) string {
@@ -83,12 +197,16 @@ func (d *MySQLDialect) DataType(fieldType reflect.Type, size int) string {
 		return "INT"
 	case reflect.Int64:
 		return "BIGINT"
+	case reflect.Uint32:
+		return "INT UNSIGNED"
+	case reflect.Uint, reflect.Uint64:
+		return "BIGINT UNSIGNED"
 	case reflect.Float32:
 		return "FLOAT"
 	case reflect.Float64:
 		return "DOUBLE"
 	case reflect.String:
-		if size > 0 && size <= 255 {
+		if size > 0 && size <= 16383 {
 			return fmt.Sprintf("VARCHAR(%d)", size)
 		}
 		return "TEXT"
@@ -119,6 +237,10 @@ func (d *MySQLDialect) CreateTableSQL(tableName string, columns []ColumnDefiniti
 			part += " NOT NULL"
 		}
 
+		if col.Unique && !col.Primary {
+			part += " UNIQUE"
+		}
+
 		if col.AutoIncrement {
 			part += " " + d.AutoIncrement()
 		}
@@ -142,6 +264,8 @@ func (d *MySQLDialect) CreateTableSQL(tableName string, columns []ColumnDefiniti
 		parts = append(parts, d.PrimaryKey()+" ("+strings.Join(primaryKeys, ", ")+")")
 	}
 
+	parts = append(parts, foreignKeyClauses(d, columns)...)
+
 	return fmt.Sprintf("CREATE TABLE %s (%s)", d.Quote(tableName), strings.Join(parts, ", "))
 }
 
@@ -149,9 +273,19 @@ func (d *MySQLDialect) DropTableSQL(tableName string) string {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", d.Quote(tableName))
 }
 
+func (d *MySQLDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s", d.Quote(tableName))
+}
+
 func (d *MySQLDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
-		d.Quote(tableName), d.Quote(columnName), definition.Type)
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, addColumnConstraints(definition))
+}
+
+// ModifyColumnSQL MySQL的MODIFY COLUMN需要完整的列定义，未声明NOT NULL的列改为可空
+func (d *MySQLDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, modifyColumnConstraints(definition))
 }
 
 func (d *MySQLDialect) DropColumnSQL(tableName, columnName string) string {
@@ -177,11 +311,34 @@ func (d *MySQLDialect) DropIndexSQL(tableName, indexName string) string {
 	return fmt.Sprintf("DROP INDEX %s ON %s", d.Quote(indexName), d.Quote(tableName))
 }
 
+func (d *MySQLDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d *MySQLDialect) LimitOffsetSQL(limit, offset int) string {
+	return limitOffsetSQL(limit, offset)
+}
+
+func (d *MySQLDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quoteTableRef(d, tableName), quoteColumns(d, columns), values)
+
+	var assignments []string
+	for _, col := range updateColumns {
+		assignments = append(assignments, fmt.Sprintf("%s = VALUES(%s)", d.Quote(col), d.Quote(col)))
+	}
+	if len(assignments) == 0 && len(columns) > 0 {
+		// 没有需要更新的列时保持原值，相当于忽略冲突
+		assignments = append(assignments, fmt.Sprintf("%s = %s", d.Quote(columns[0]), d.Quote(columns[0])))
+	}
+
+	return query + " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
 // PostgreSQLDialect PostgreSQL方言
 type PostgreSQLDialect struct{}
 
 func (d *PostgreSQLDialect) Quote(name string) string {
-	return `"` + name + `"`
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
 
 func (d *PostgreSQLDialect) QuoteString(s string) string {
@@ -194,7 +351,7 @@ func (d *PostgreSQLDialect) DataType(fieldType reflect.Type, size int) string {
 		return "BOOLEAN"
 	case reflect.Int, reflect.Int32:
 		return "INTEGER"
-	case reflect.Int64:
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
 		return "BIGINT"
 	case reflect.Float32:
 		return "REAL"
@@ -232,6 +389,10 @@ func (d *PostgreSQLDialect) CreateTableSQL(tableName string, columns []ColumnDef
 			part += " NOT NULL"
 		}
 
+		if col.Unique && !col.Primary {
+			part += " UNIQUE"
+		}
+
 		if col.Default != nil {
 			part += " DEFAULT " + fmt.Sprintf("%v", col.Default)
 		}
@@ -247,6 +408,8 @@ func (d *PostgreSQLDialect) CreateTableSQL(tableName string, columns []ColumnDef
 		parts = append(parts, d.PrimaryKey()+" ("+strings.Join(primaryKeys, ", ")+")")
 	}
 
+	parts = append(parts, foreignKeyClauses(d, columns)...)
+
 	return fmt.Sprintf("CREATE TABLE %s (%s)", d.Quote(tableName), strings.Join(parts, ", "))
 }
 
@@ -254,8 +417,18 @@ func (d *PostgreSQLDialect) DropTableSQL(tableName string) string {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", d.Quote(tableName))
 }
 
+func (d *PostgreSQLDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY", d.Quote(tableName))
+}
+
 func (d *PostgreSQLDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, addColumnConstraints(definition))
+}
+
+// ModifyColumnSQL 只修改列类型，非空约束和默认值保持不变
+func (d *PostgreSQLDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
 		d.Quote(tableName), d.Quote(columnName), definition.Type)
 }
 
@@ -282,11 +455,23 @@ func (d *PostgreSQLDialect) DropIndexSQL(tableName, indexName string) string {
 	return fmt.Sprintf("DROP INDEX IF EXISTS %s", d.Quote(indexName))
 }
 
+func (d *PostgreSQLDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (d *PostgreSQLDialect) LimitOffsetSQL(limit, offset int) string {
+	return limitOffsetSQL(limit, offset)
+}
+
+func (d *PostgreSQLDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	return onConflictUpsertSQL(d, tableName, columns, values, conflictColumns, updateColumns)
+}
+
 // SQLiteDialect SQLite方言
 type SQLiteDialect struct{}
 
 func (d *SQLiteDialect) Quote(name string) string {
-	return "`" + name + "`"
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
 
 func (d *SQLiteDialect) QuoteString(s string) string {
@@ -297,7 +482,7 @@ func (d *SQLiteDialect) DataType(fieldType reflect.Type, size int) string {
 	switch fieldType.Kind() {
 	case reflect.Bool:
 		return "INTEGER"
-	case reflect.Int, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
 		return "INTEGER"
 	case reflect.Float32, reflect.Float64:
 		return "REAL"
@@ -321,11 +506,18 @@ func (d *SQLiteDialect) PrimaryKey() string {
 
 func (d *SQLiteDialect) CreateTableSQL(tableName string, columns []ColumnDefinition) string {
 	var parts []string
+	var primaryKeys []string
+	for _, col := range columns {
+		if col.Primary {
+			primaryKeys = append(primaryKeys, d.Quote(col.Name))
+		}
+	}
 
 	for _, col := range columns {
 		part := d.Quote(col.Name) + " " + col.Type
 
-		if col.Primary {
+		// 单列主键内联声明以支持AUTOINCREMENT，复合主键使用表级约束
+		if col.Primary && len(primaryKeys) == 1 {
 			part += " " + d.PrimaryKey()
 		}
 
@@ -337,6 +529,10 @@ func (d *SQLiteDialect) CreateTableSQL(tableName string, columns []ColumnDefinit
 			part += " NOT NULL"
 		}
 
+		if col.Unique && !col.Primary {
+			part += " UNIQUE"
+		}
+
 		if col.Default != nil {
 			part += " DEFAULT " + fmt.Sprintf("%v", col.Default)
 		}
@@ -344,6 +540,11 @@ func (d *SQLiteDialect) CreateTableSQL(tableName string, columns []ColumnDefinit
 		parts = append(parts, part)
 	}
 
+	if len(primaryKeys) > 1 {
+		parts = append(parts, d.PrimaryKey()+" ("+strings.Join(primaryKeys, ", ")+")")
+	}
+	parts = append(parts, foreignKeyClauses(d, columns)...)
+
 	return fmt.Sprintf("CREATE TABLE %s (%s)", d.Quote(tableName), strings.Join(parts, ", "))
 }
 
@@ -351,9 +552,19 @@ func (d *SQLiteDialect) DropTableSQL(tableName string) string {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", d.Quote(tableName))
 }
 
+// SQLite不支持TRUNCATE，使用不带条件的DELETE
+func (d *SQLiteDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("DELETE FROM %s", d.Quote(tableName))
+}
+
 func (d *SQLiteDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
-		d.Quote(tableName), d.Quote(columnName), definition.Type)
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, addColumnConstraints(definition))
+}
+
+// ModifyColumnSQL SQLite不支持修改列，返回空字符串，自动迁移时跳过
+func (d *SQLiteDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return ""
 }
 
 func (d *SQLiteDialect) DropColumnSQL(tableName, columnName string) string {
@@ -380,11 +591,23 @@ func (d *SQLiteDialect) DropIndexSQL(tableName, indexName string) string {
 	return fmt.Sprintf("DROP INDEX IF EXISTS %s", d.Quote(indexName))
 }
 
+func (d *SQLiteDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d *SQLiteDialect) LimitOffsetSQL(limit, offset int) string {
+	return limitOffsetSQL(limit, offset)
+}
+
+func (d *SQLiteDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	return onConflictUpsertSQL(d, tableName, columns, values, conflictColumns, updateColumns)
+}
+
 // SQLServerDialect SQL Server方言
 type SQLServerDialect struct{}
 
 func (d *SQLServerDialect) Quote(name string) string {
-	return "[" + name + "]"
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
 }
 
 func (d *SQLServerDialect) QuoteString(s string) string {
@@ -397,7 +620,7 @@ func (d *SQLServerDialect) DataType(fieldType reflect.Type, size int) string {
 		return "BIT"
 	case reflect.Int, reflect.Int32:
 		return "INT"
-	case reflect.Int64:
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
 		return "BIGINT"
 	case reflect.Float32:
 		return "REAL"
@@ -439,6 +662,10 @@ func (d *SQLServerDialect) CreateTableSQL(tableName string, columns []ColumnDefi
 			part += " NOT NULL"
 		}
 
+		if col.Unique && !col.Primary {
+			part += " UNIQUE"
+		}
+
 		if col.Default != nil {
 			part += " DEFAULT " + fmt.Sprintf("%v", col.Default)
 		}
@@ -454,6 +681,8 @@ func (d *SQLServerDialect) CreateTableSQL(tableName string, columns []ColumnDefi
 		parts = append(parts, d.PrimaryKey()+" ("+strings.Join(primaryKeys, ", ")+")")
 	}
 
+	parts = append(parts, foreignKeyClauses(d, columns)...)
+
 	return fmt.Sprintf("CREATE TABLE %s (%s)", d.Quote(tableName), strings.Join(parts, ", "))
 }
 
@@ -461,9 +690,23 @@ func (d *SQLServerDialect) DropTableSQL(tableName string) string {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", d.Quote(tableName))
 }
 
+func (d *SQLServerDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s", d.Quote(tableName))
+}
+
 func (d *SQLServerDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD %s %s",
-		d.Quote(tableName), d.Quote(columnName), definition.Type)
+	return fmt.Sprintf("ALTER TABLE %s ADD %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, addColumnConstraints(definition))
+}
+
+// ModifyColumnSQL SQL Server的ALTER COLUMN未指定NULL时列变为可空，按列定义显式声明
+func (d *SQLServerDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	nullable := " NULL"
+	if definition.NotNull {
+		nullable = " NOT NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, nullable)
 }
 
 func (d *SQLServerDialect) DropColumnSQL(tableName, columnName string) string {
@@ -488,3 +731,430 @@ func (d *SQLServerDialect) CreateIndexSQL(tableName, indexName string, columns [
 func (d *SQLServerDialect) DropIndexSQL(tableName, indexName string) string {
 	return fmt.Sprintf("DROP INDEX %s ON %s", d.Quote(indexName), d.Quote(tableName))
 }
+
+func (d *SQLServerDialect) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
+
+// LimitOffsetSQL 使用 OFFSET ... ROWS FETCH NEXT ... ROWS ONLY 分页，SQL Server要求FETCH前必须有OFFSET
+// 该子句只能跟在ORDER BY之后，未排序时查询构建器补充 ORDER BY (SELECT NULL)
+func (d *SQLServerDialect) LimitOffsetSQL(limit, offset int) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+	sql := fmt.Sprintf("OFFSET %d ROWS", offset)
+	if limit > 0 {
+		sql += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit)
+	}
+	return sql
+}
+
+func (d *SQLServerDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	var matches []string
+	for _, col := range conflictColumns {
+		matches = append(matches, fmt.Sprintf("target.%s = source.%s", d.Quote(col), d.Quote(col)))
+	}
+
+	var sourceColumns []string
+	for _, col := range columns {
+		sourceColumns = append(sourceColumns, "source."+d.Quote(col))
+	}
+
+	query := fmt.Sprintf("MERGE INTO %s AS target USING (VALUES %s) AS source (%s) ON %s",
+		quoteTableRef(d, tableName), values, quoteColumns(d, columns), strings.Join(matches, " AND "))
+
+	if len(updateColumns) > 0 {
+		var assignments []string
+		for _, col := range updateColumns {
+			assignments = append(assignments, fmt.Sprintf("target.%s = source.%s", d.Quote(col), d.Quote(col)))
+		}
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(assignments, ", ")
+	}
+
+	query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+		quoteColumns(d, columns), strings.Join(sourceColumns, ", "))
+	return query
+}
+
+// ClickHouseDialect ClickHouse方言
+// ClickHouse不支持自增列、唯一约束、外键和行锁，表默认使用MergeTree引擎并按主键排序
+type ClickHouseDialect struct{}
+
+func (d *ClickHouseDialect) Quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (d *ClickHouseDialect) QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *ClickHouseDialect) DataType(fieldType reflect.Type, size int) string {
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return "Bool"
+	case reflect.Int, reflect.Int32:
+		return "Int32"
+	case reflect.Int64:
+		return "Int64"
+	case reflect.Uint32:
+		return "UInt32"
+	case reflect.Uint, reflect.Uint64:
+		return "UInt64"
+	case reflect.Float32:
+		return "Float32"
+	case reflect.Float64:
+		return "Float64"
+	case reflect.String:
+		return "String"
+	default:
+		if fieldType.String() == "time.Time" {
+			return "DateTime64(3)"
+		}
+		return "String"
+	}
+}
+
+func (d *ClickHouseDialect) AutoIncrement() string {
+	return ""
+}
+
+func (d *ClickHouseDialect) PrimaryKey() string {
+	return "PRIMARY KEY"
+}
+
+func (d *ClickHouseDialect) CreateTableSQL(tableName string, columns []ColumnDefinition) string {
+	var parts []string
+	var primaryKeys []string
+
+	for _, col := range columns {
+		part := d.Quote(col.Name) + " " + col.Type
+
+		if col.NotNull {
+			part += " NOT NULL"
+		}
+
+		if col.Default != nil {
+			part += " DEFAULT " + fmt.Sprintf("%v", col.Default)
+		}
+
+		if col.Comment != "" {
+			part += " COMMENT " + d.QuoteString(col.Comment)
+		}
+
+		parts = append(parts, part)
+
+		if col.Primary {
+			primaryKeys = append(primaryKeys, d.Quote(col.Name))
+		}
+	}
+
+	orderBy := "tuple()"
+	if len(primaryKeys) > 0 {
+		orderBy = "(" + strings.Join(primaryKeys, ", ") + ")"
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (%s) ENGINE = MergeTree() ORDER BY %s",
+		d.Quote(tableName), strings.Join(parts, ", "), orderBy)
+}
+
+func (d *ClickHouseDialect) DropTableSQL(tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", d.Quote(tableName))
+}
+
+func (d *ClickHouseDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s", d.Quote(tableName))
+}
+
+func (d *ClickHouseDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s%s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type, addColumnConstraints(definition))
+}
+
+func (d *ClickHouseDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s",
+		d.Quote(tableName), d.Quote(columnName), definition.Type)
+}
+
+func (d *ClickHouseDialect) DropColumnSQL(tableName, columnName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.Quote(tableName), d.Quote(columnName))
+}
+
+// CreateIndexSQL ClickHouse只支持数据跳数索引，唯一索引按普通索引创建
+func (d *ClickHouseDialect) CreateIndexSQL(tableName, indexName string, columns []string, unique bool) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s) TYPE minmax GRANULARITY 1",
+		d.Quote(tableName), d.Quote(indexName), quoteColumns(d, columns))
+}
+
+func (d *ClickHouseDialect) DropIndexSQL(tableName, indexName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", d.Quote(tableName), d.Quote(indexName))
+}
+
+func (d *ClickHouseDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (d *ClickHouseDialect) LimitOffsetSQL(limit, offset int) string {
+	return limitOffsetSQL(limit, offset)
+}
+
+// UpsertSQL ClickHouse没有冲突更新语法，生成普通INSERT，由ReplacingMergeTree等引擎在合并时去重
+func (d *ClickHouseDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quoteTableRef(d, tableName), quoteColumns(d, columns), values)
+}
+
+// OracleDialect Oracle方言，分页和自增列语法要求Oracle 12c及以上
+type OracleDialect struct{}
+
+func (d *OracleDialect) Quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d *OracleDialect) QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (d *OracleDialect) DataType(fieldType reflect.Type, size int) string {
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return "NUMBER(1)"
+	case reflect.Int, reflect.Int32:
+		return "NUMBER(10)"
+	case reflect.Int64, reflect.Uint32:
+		return "NUMBER(19)"
+	case reflect.Uint, reflect.Uint64:
+		return "NUMBER(20)"
+	case reflect.Float32:
+		return "BINARY_FLOAT"
+	case reflect.Float64:
+		return "BINARY_DOUBLE"
+	case reflect.String:
+		if size > 0 && size <= 4000 {
+			return fmt.Sprintf("VARCHAR2(%d)", size)
+		}
+		return "CLOB"
+	default:
+		if fieldType.String() == "time.Time" {
+			return "TIMESTAMP"
+		}
+		return "CLOB"
+	}
+}
+
+func (d *OracleDialect) AutoIncrement() string {
+	return "GENERATED BY DEFAULT AS IDENTITY"
+}
+
+func (d *OracleDialect) PrimaryKey() string {
+	return "PRIMARY KEY"
+}
+
+// CreateTableSQL Oracle要求DEFAULT写在NOT NULL等约束之前
+func (d *OracleDialect) CreateTableSQL(tableName string, columns []ColumnDefinition) string {
+	var parts []string
+	var primaryKeys []string
+
+	for _, col := range columns {
+		part := d.Quote(col.Name) + " " + col.Type
+
+		if col.AutoIncrement {
+			part += " " + d.AutoIncrement()
+		}
+
+		if col.Default != nil {
+			part += " DEFAULT " + fmt.Sprintf("%v", col.Default)
+		}
+
+		if col.NotNull {
+			part += " NOT NULL"
+		}
+
+		if col.Unique && !col.Primary {
+			part += " UNIQUE"
+		}
+
+		parts = append(parts, part)
+
+		if col.Primary {
+			primaryKeys = append(primaryKeys, d.Quote(col.Name))
+		}
+	}
+
+	if len(primaryKeys) > 0 {
+		parts = append(parts, d.PrimaryKey()+" ("+strings.Join(primaryKeys, ", ")+")")
+	}
+
+	parts = append(parts, foreignKeyClauses(d, columns)...)
+
+	return fmt.Sprintf("CREATE TABLE %s (%s)", d.Quote(tableName), strings.Join(parts, ", "))
+}
+
+// DropTableSQL Oracle不支持DROP TABLE IF EXISTS，通过PL/SQL块忽略表不存在的错误（ORA-00942）
+func (d *OracleDialect) DropTableSQL(tableName string) string {
+	return fmt.Sprintf("BEGIN EXECUTE IMMEDIATE %s; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		d.QuoteString("DROP TABLE "+d.Quote(tableName)))
+}
+
+func (d *OracleDialect) TruncateTableSQL(tableName string) string {
+	return fmt.Sprintf("TRUNCATE TABLE %s", d.Quote(tableName))
+}
+
+func (d *OracleDialect) AddColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	column := d.Quote(columnName) + " " + definition.Type
+	if definition.Default != nil {
+		column += fmt.Sprintf(" DEFAULT %v", definition.Default)
+		if definition.NotNull {
+			column += " NOT NULL"
+		}
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD (%s)", d.Quote(tableName), column)
+}
+
+// ModifyColumnSQL 只修改列类型，重复声明已有的非空约束在Oracle中会报错
+func (d *OracleDialect) ModifyColumnSQL(tableName, columnName string, definition ColumnDefinition) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s)", d.Quote(tableName), d.Quote(columnName), definition.Type)
+}
+
+func (d *OracleDialect) DropColumnSQL(tableName, columnName string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.Quote(tableName), d.Quote(columnName))
+}
+
+func (d *OracleDialect) CreateIndexSQL(tableName, indexName string, columns []string, unique bool) string {
+	indexType := ""
+	if unique {
+		indexType = "UNIQUE "
+	}
+
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)",
+		indexType, d.Quote(indexName), d.Quote(tableName), quoteColumns(d, columns))
+}
+
+func (d *OracleDialect) DropIndexSQL(tableName, indexName string) string {
+	return fmt.Sprintf("DROP INDEX %s", d.Quote(indexName))
+}
+
+func (d *OracleDialect) Placeholder(index int) string {
+	return fmt.Sprintf(":%d", index)
+}
+
+// LimitOffsetSQL 使用 OFFSET ... ROWS FETCH NEXT ... ROWS ONLY 分页
+func (d *OracleDialect) LimitOffsetSQL(limit, offset int) string {
+	var parts []string
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d ROWS", offset))
+	}
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("FETCH NEXT %d ROWS ONLY", limit))
+	}
+	return strings.Join(parts, " ")
+}
+
+// UpsertSQL 使用MERGE语句，Oracle不支持VALUES多行构造，每行转换为 SELECT ... FROM dual 后以UNION ALL合并
+func (d *OracleDialect) UpsertSQL(tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	rows := strings.Split(strings.TrimSuffix(strings.TrimPrefix(values, "("), ")"), "), (")
+	selects := make([]string, len(rows))
+	for i, row := range rows {
+		fields := strings.Split(row, ", ")
+		for j := range fields {
+			if j < len(columns) {
+				fields[j] += " AS " + d.Quote(columns[j])
+			}
+		}
+		selects[i] = "SELECT " + strings.Join(fields, ", ") + " FROM dual"
+	}
+
+	var matches []string
+	for _, col := range conflictColumns {
+		matches = append(matches, fmt.Sprintf("target.%s = source.%s", d.Quote(col), d.Quote(col)))
+	}
+
+	var sourceColumns []string
+	for _, col := range columns {
+		sourceColumns = append(sourceColumns, "source."+d.Quote(col))
+	}
+
+	query := fmt.Sprintf("MERGE INTO %s target USING (%s) source ON (%s)",
+		quoteTableRef(d, tableName), strings.Join(selects, " UNION ALL "), strings.Join(matches, " AND "))
+
+	if len(updateColumns) > 0 {
+		var assignments []string
+		for _, col := range updateColumns {
+			assignments = append(assignments, fmt.Sprintf("target.%s = source.%s", d.Quote(col), d.Quote(col)))
+		}
+		query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(assignments, ", ")
+	}
+
+	query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		quoteColumns(d, columns), strings.Join(sourceColumns, ", "))
+	return query
+}
+
+// quoteColumns 引用列名并以逗号连接
+func quoteColumns(dialect Dialect, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = dialect.Quote(col)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// onConflictUpsertSQL 生成 INSERT ... ON CONFLICT 语句（PostgreSQL、SQLite）
+func onConflictUpsertSQL(dialect Dialect, tableName string, columns []string, values string, conflictColumns, updateColumns []string) string {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT",
+		quoteTableRef(dialect, tableName), quoteColumns(dialect, columns), values)
+
+	if len(conflictColumns) > 0 {
+		query += " (" + quoteColumns(dialect, conflictColumns) + ")"
+	}
+
+	if len(updateColumns) == 0 {
+		return query + " DO NOTHING"
+	}
+
+	var assignments []string
+	for _, col := range updateColumns {
+		assignments = append(assignments, fmt.Sprintf("%s = EXCLUDED.%s", dialect.Quote(col), dialect.Quote(col)))
+	}
+	return query + " DO UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+// limitOffsetSQL 生成 LIMIT ... OFFSET ... 分页子句，值为0时省略对应部分
+func limitOffsetSQL(limit, offset int) string {
+	var parts []string
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(parts, " ")
+}
+
+// rebind 将SQL中的?占位符替换为方言对应的占位符，忽略引号内的内容
+func rebind(dialect Dialect, query string) string {
+	if dialect.Placeholder(1) == "?" || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query) + 16)
+
+	index := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			index++
+			builder.WriteString(dialect.Placeholder(index))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
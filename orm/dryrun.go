@@ -0,0 +1,94 @@
+package orm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Statement 生成的SQL语句及参数
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// dryRunRecorder 记录DryRun模式下生成的写操作语句，在构建器副本间共享
+type dryRunRecorder struct {
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// record 记录一条语句
+func (r *dryRunRecorder) record(query string, args []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, Statement{SQL: query, Args: args})
+}
+
+// dryRunResult DryRun模式下的执行结果
+type dryRunResult struct{}
+
+// LastInsertId DryRun模式下没有插入ID
+func (dryRunResult) LastInsertId() (int64, error) {
+	return 0, ErrDryRun
+}
+
+// RowsAffected DryRun模式下没有受影响的行
+func (dryRunResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+// DryRun 开启DryRun模式：Insert/Update/Delete等写操作只生成SQL而不执行
+// 生成的语句通过DryRunStatements获取，读操作仍正常执行
+func (qb *queryBuilder) DryRun() QueryBuilder {
+	if qb.dryRun == nil {
+		qb.dryRun = &dryRunRecorder{}
+	}
+	return qb
+}
+
+// DryRunStatements 获取DryRun模式下生成的写操作语句
+func (qb *queryBuilder) DryRunStatements() []Statement {
+	if qb.dryRun == nil {
+		return nil
+	}
+	qb.dryRun.mu.Lock()
+	defer qb.dryRun.mu.Unlock()
+	return append([]Statement(nil), qb.dryRun.statements...)
+}
+
+// ToSQLInsert 构建INSERT语句，不修改data
+func (qb *queryBuilder) ToSQLInsert(data interface{}) (string, []interface{}) {
+	query, args := qb.buildInsertSQL(qb.stampTimestamps(structValue(data), false))
+	return rebind(qb.dialect(), query), args
+}
+
+// ToSQLUpdate 构建UPDATE语句，不修改data；带版本号字段时包含乐观锁条件
+func (qb *queryBuilder) ToSQLUpdate(data interface{}) (string, []interface{}) {
+	qb = qb.byPrimaryKey(data)
+	data = qb.stampTimestamps(structValue(data), true)
+
+	var query string
+	var args []interface{}
+	if column, field, ok := versionField(data); ok {
+		query, args, _ = qb.buildVersionedUpdateSQL(data, column, field)
+	} else {
+		query, args = qb.buildUpdateSQL(data)
+	}
+	return rebind(qb.dialect(), query), args
+}
+
+// ToSQLDelete 构建DELETE语句
+func (qb *queryBuilder) ToSQLDelete() (string, []interface{}) {
+	qb = qb.byPrimaryKey(qb.model)
+	query, args := qb.buildDeleteSQL()
+	return rebind(qb.dialect(), query), args
+}
+
+// structValue 将结构体指针解引用为结构体值，以免构建SQL时修改调用方的数据
+func structValue(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		return v.Elem().Interface()
+	}
+	return data
+}
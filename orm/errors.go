@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrStaleObject 乐观锁冲突，记录已被其他操作修改或不存在
+	ErrStaleObject = errors.New("记录已被修改或不存在")
+
+	// ErrDryRun DryRun模式下语句未实际执行
+	ErrDryRun = errors.New("DryRun模式下语句未执行")
+
+	// ErrRecordNotFound 查询单条记录时没有匹配的记录，同时满足 errors.Is(err, sql.ErrNoRows)
+	ErrRecordNotFound = errors.New("记录不存在")
+
+	// ErrDuplicateKey 违反主键或唯一约束
+	ErrDuplicateKey = errors.New("违反唯一约束")
+
+	// ErrForeignKeyViolation 违反外键约束
+	ErrForeignKeyViolation = errors.New("违反外键约束")
+)
+
+// translatedError 将驱动错误与对应的哨兵错误关联，errors.Is 对两者均成立，错误信息保留驱动的原始信息
+type translatedError struct {
+	sentinel error
+	err      error
+}
+
+func (e *translatedError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+func (e *translatedError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+// errRecordNotFound 记录不存在的错误
+var errRecordNotFound = &translatedError{sentinel: ErrRecordNotFound, err: sql.ErrNoRows}
+
+// duplicateKeyMessages 各数据库违反唯一约束的错误特征文本
+var duplicateKeyMessages = []string{
+	"duplicate entry",                     // MySQL 1062
+	"duplicate key value",                 // PostgreSQL 23505
+	"unique constraint failed",            // SQLite
+	"cannot insert duplicate key",         // SQL Server 2601
+	"violation of primary key constraint", // SQL Server 2627
+	"violation of unique key constraint",  // SQL Server 2627
+}
+
+// foreignKeyMessages 各数据库违反外键约束的错误特征文本
+var foreignKeyMessages = []string{
+	"a foreign key constraint fails",  // MySQL 1451、1452
+	"violates foreign key constraint", // PostgreSQL 23503
+	"foreign key constraint failed",   // SQLite
+	"conflicted with the foreign key", // SQL Server 547
+	"conflicted with the reference",   // SQL Server 547
+}
+
+// translateError 将驱动返回的约束错误转换为对应的哨兵错误，其余错误原样返回
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, ErrRecordNotFound) {
+			return err
+		}
+		return &translatedError{sentinel: ErrRecordNotFound, err: err}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, text := range duplicateKeyMessages {
+		if strings.Contains(message, text) {
+			return &translatedError{sentinel: ErrDuplicateKey, err: err}
+		}
+	}
+	for _, text := range foreignKeyMessages {
+		if strings.Contains(message, text) {
+			return &translatedError{sentinel: ErrForeignKeyViolation, err: err}
+		}
+	}
+	return err
+}
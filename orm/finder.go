@@ -0,0 +1,240 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FindByID 按主键查询单条记录，主键列取自模型的primary标签，默认为id
+// 复合主键可传入按主键字段顺序排列的[]interface{}；记录不存在时返回ErrRecordNotFound
+func (qb *queryBuilder) FindByID(id interface{}, dest interface{}) error {
+	if keys, ok := id.([]interface{}); ok {
+		return qb.FindByKey(dest, keys...)
+	}
+	return qb.FindByKey(dest, id)
+}
+
+// FindByKey 按主键查询单条记录，复合主键按主键字段的声明顺序传入各列的值
+func (qb *queryBuilder) FindByKey(dest interface{}, keys ...interface{}) error {
+	columns := primaryKeyColumns(qb.model)
+	if len(columns) == 0 {
+		columns = primaryKeyColumns(dest)
+	}
+	if len(columns) == 0 {
+		columns = []string{"id"}
+	}
+	if len(keys) != len(columns) {
+		return fmt.Errorf("主键包含 %d 列，传入了 %d 个值", len(columns), len(keys))
+	}
+
+	for i, column := range columns {
+		qb.whereEqual(column, keys[i])
+	}
+	return qb.findOne(dest)
+}
+
+// FirstOrCreate 按attrs和已有条件查询第一条记录，不存在时用attrs填充dest并插入
+// 查询始终在主库上执行，避免副本延迟导致重复创建
+func (qb *queryBuilder) FirstOrCreate(dest interface{}, attrs map[string]interface{}) error {
+	qb.usePrimary = true
+	qb.WhereMap(attrs)
+	err := qb.findOne(dest)
+	if err == nil || !errors.Is(err, ErrRecordNotFound) {
+		return err
+	}
+
+	if err := setColumnValues(dest, attrs); err != nil {
+		return err
+	}
+	return qb.Insert(dest)
+}
+
+// UpdateOrCreate 存在匹配match的记录时更新updates中的列，否则以match和updates创建新记录
+// 需要通过Model创建的查询构建器，以便按模型类型创建记录
+func (qb *queryBuilder) UpdateOrCreate(match, updates map[string]interface{}) error {
+	modelType := reflect.TypeOf(qb.model)
+	if modelType == nil {
+		return fmt.Errorf("UpdateOrCreate需要通过Model创建查询构建器")
+	}
+	for modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	qb.usePrimary = true
+	qb.WhereMap(match)
+	exists, err := qb.Exists()
+	if err != nil {
+		return err
+	}
+	if exists {
+		if len(updates) == 0 {
+			return nil
+		}
+		return qb.UpdateColumns(updates)
+	}
+
+	record := reflect.New(modelType).Interface()
+	if err := setColumnValues(record, match); err != nil {
+		return err
+	}
+	if err := setColumnValues(record, updates); err != nil {
+		return err
+	}
+	return qb.Insert(record)
+}
+
+// findOne 查询第一条记录到结构体，并加载预加载关联
+func (qb *queryBuilder) findOne(dest interface{}) error {
+	qb.limitNum = 1
+	query, args := qb.buildSelectSQL()
+
+	err := qb.cachedQuery("first", dest, query, args, func() error {
+		rows, err := qb.query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if err := scanStruct(rows, dest, qb.orm.location()); err != nil {
+			return err
+		}
+		return qb.preload(dest)
+	})
+	if err != nil {
+		return err
+	}
+	return qb.afterFind(dest)
+}
+
+// autoIncrementField 模型的自增主键字段，没有时返回nil
+func autoIncrementField(t reflect.Type) *modelField {
+	for _, field := range metadataOf(t).fields {
+		if field.tag.AutoIncrement && isIntegerKind(field.field.Type.Kind()) {
+			return field
+		}
+	}
+	return nil
+}
+
+// insertRecords 插入数据对应的可写结构体，用于回填自增主键；非结构体数据返回nil
+func insertRecords(data interface{}) []reflect.Value {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return []reflect.Value{v.Elem()}
+}
+
+// returningField 需要通过RETURNING获取自增主键时返回主键字段
+// PostgreSQL驱动不支持LastInsertId，upsert可能不返回行，DryRun不执行语句，均不使用RETURNING
+func (qb *queryBuilder) returningField(records []reflect.Value) *modelField {
+	if len(records) == 0 || qb.upsert || qb.dryRun != nil {
+		return nil
+	}
+	if _, ok := qb.dialect().(*PostgreSQLDialect); !ok {
+		return nil
+	}
+	field := autoIncrementField(records[0].Type())
+	if field == nil || !isZeroValue(records[0].FieldByIndex(field.index)) {
+		return nil
+	}
+	return field
+}
+
+// execInsert 执行插入语句，并将生成的自增主键回填到零值的主键字段，返回受影响的行数
+func (qb *queryBuilder) execInsert(statement sqlStatement) (int64, error) {
+	field := qb.returningField(statement.records)
+	if field == nil {
+		result, err := qb.exec(statement.query, statement.args...)
+		if err != nil {
+			return 0, err
+		}
+		qb.fillInsertedIDs(statement.records, result)
+		return result.RowsAffected()
+	}
+
+	query := statement.query + " RETURNING " + qb.dialect().Quote(field.column)
+	rows, err := qb.queryPrimary(query, statement.args...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	defer rows.Close()
+
+	var affected int64
+	for _, record := range statement.records {
+		if !rows.Next() {
+			break
+		}
+		if err := rows.Scan(record.FieldByIndex(field.index).Addr().Interface()); err != nil {
+			return affected, err
+		}
+		affected++
+	}
+	return affected, translateError(rows.Err())
+}
+
+// fillInsertedIDs 按LastInsertId回填零值的自增主键，驱动不支持时忽略
+// 多行插入时MySQL返回第一行的ID，SQLite返回最后一行的ID，其余数据库只回填单行插入
+func (qb *queryBuilder) fillInsertedIDs(records []reflect.Value, result sql.Result) {
+	if len(records) == 0 || qb.upsert {
+		return
+	}
+	field := autoIncrementField(records[0].Type())
+	if field == nil || !isZeroValue(records[0].FieldByIndex(field.index)) {
+		return
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil || id == 0 {
+		return
+	}
+	first := id
+	switch qb.dialect().(type) {
+	case *MySQLDialect:
+	case *SQLiteDialect:
+		first = id - int64(len(records)) + 1
+	default:
+		if len(records) > 1 {
+			return
+		}
+	}
+
+	for i, record := range records {
+		value := record.FieldByIndex(field.index)
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value.SetInt(first + int64(i))
+		default:
+			value.SetUint(uint64(first + int64(i)))
+		}
+	}
+}
+
+// setColumnValues 按列名将值写入结构体字段
+func setColumnValues(dest interface{}, values map[string]interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest必须是结构体指针")
+	}
+	v = v.Elem()
+
+	for column, value := range values {
+		field := findFieldByColumn(v, column)
+		if !field.IsValid() || !field.CanSet() {
+			return fmt.Errorf("模型 %s 不存在列: %s", v.Type().Name(), column)
+		}
+		if value == nil {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+
+		converted := reflect.ValueOf(convertValue(value, field.Type()))
+		if !converted.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("列 %s 的值类型 %s 无法赋给字段类型 %s", column, converted.Type(), field.Type())
+		}
+		field.Set(converted)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// defaultRetryBackoff 默认的重试初始等待时间
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// retryBackoff 获取重试初始等待时间
+func (c *Config) retryBackoff() time.Duration {
+	if c.RetryBackoff > 0 {
+		return c.RetryBackoff
+	}
+	return defaultRetryBackoff
+}
+
+// openWithRetry 打开数据库连接，失败时按配置的次数指数退避重试
+func openWithRetry(config *Config) (*sql.DB, error) {
+	backoff := config.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		db, err := openDB(config)
+		if err == nil || attempt >= config.ConnectRetries {
+			return db, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryRead 读操作遇到连接错误、序列化失败或死锁时按配置的次数指数退避重试
+func (o *ORM) retryRead(ctx context.Context, fn func() error) {
+	o.retry(ctx, isTransientError, fn)
+}
+
+// retryWrite 写操作遇到序列化失败或死锁时按配置的次数指数退避重试
+// 此时语句已被数据库回滚，可以安全地重新执行；连接错误时语句可能已执行，不重试
+func (o *ORM) retryWrite(ctx context.Context, fn func() error) {
+	o.retry(ctx, isSerializationFailure, fn)
+}
+
+// retry 执行fn，返回的错误满足retryable时按QueryRetries的次数指数退避重试
+func (o *ORM) retry(ctx context.Context, retryable func(error) bool, fn func() error) {
+	backoff := o.config.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if attempt >= o.config.QueryRetries || !retryable(err) {
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// Healthy 最近一次健康检查是否成功，未开启健康检查时始终为true
+func (o *ORM) Healthy() bool {
+	o.healthMu.RLock()
+	defer o.healthMu.RUnlock()
+	return o.healthErr == nil
+}
+
+// HealthError 最近一次健康检查的错误
+func (o *ORM) HealthError() error {
+	o.healthMu.RLock()
+	defer o.healthMu.RUnlock()
+	return o.healthErr
+}
+
+// startHealthCheck 按配置的间隔启动后台健康检查，调用方需持有写锁
+func (o *ORM) startHealthCheck() {
+	o.stopHealthCheck()
+	if o.config.HealthCheckInterval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	o.healthStop = stop
+	go func() {
+		ticker := time.NewTicker(o.config.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				o.checkHealth()
+			}
+		}
+	}()
+}
+
+// stopHealthCheck 停止后台健康检查，调用方需持有写锁
+func (o *ORM) stopHealthCheck() {
+	if o.healthStop != nil {
+		close(o.healthStop)
+		o.healthStop = nil
+	}
+}
+
+// Stats 获取主库连接池的统计信息，包括打开、使用中、空闲的连接数以及等待次数和耗时
+// 未连接时返回零值
+func (o *ORM) Stats() sql.DBStats {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.db == nil {
+		return sql.DBStats{}
+	}
+	return o.db.Stats()
+}
+
+// HealthCheck 立即检测主库和只读副本的连接，返回主库的错误并更新Healthy的结果
+// 连接失败的只读副本被标记为不可用，读操作暂时回退到其他副本或主库
+func (o *ORM) HealthCheck(ctx context.Context) error {
+	o.mu.RLock()
+	db, replicas := o.db, o.replicas
+	o.mu.RUnlock()
+	if db == nil {
+		return fmt.Errorf("数据库未连接")
+	}
+
+	// Ping会淘汰失效的连接并建立新连接，数据库恢复后连接池随之恢复
+	err := db.PingContext(ctx)
+	o.healthMu.Lock()
+	o.healthErr = err
+	o.healthMu.Unlock()
+
+	if replicas != nil {
+		for _, r := range replicas.replicas {
+			r.mu.Lock()
+			replicaDB, down := r.db, !r.downUntil.IsZero()
+			r.mu.Unlock()
+			if replicaDB != nil && !down && replicaDB.PingContext(ctx) != nil {
+				r.markDown()
+			}
+		}
+	}
+	return err
+}
+
+// checkHealth 后台定期执行的健康检查，单次检查的超时时间为检查间隔
+func (o *ORM) checkHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.HealthCheckInterval)
+	defer cancel()
+	o.HealthCheck(ctx)
+}
@@ -0,0 +1,143 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryEvent SQL执行事件
+type QueryEvent struct {
+	Operation     string        // 操作类型：SELECT、INSERT、UPDATE、DELETE等
+	SQL           string        // 实际执行的SQL
+	Args          []interface{} // 绑定参数
+	RowsAffected  int64         // 受影响的行数，查询语句为-1
+	Duration      time.Duration // 执行耗时
+	Err           error         // 执行错误
+	InTransaction bool          // 是否在事务中执行
+}
+
+// QueryHook SQL执行钩子，在每条SQL执行完成后调用
+type QueryHook func(ctx context.Context, event *QueryEvent)
+
+// AddQueryHook 添加SQL执行钩子
+func (o *ORM) AddQueryHook(hook QueryHook) {
+	if hook == nil {
+		return
+	}
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.hooks = append(o.hooks, hook)
+}
+
+// SetLogger 设置SQL日志输出，替换LogQueries或之前设置的日志，writer为nil时关闭SQL日志
+// 慢查询阈值和参数脱敏沿用配置中的SlowThreshold和MaskQueryArgs，需要更多控制时使用 AddQueryHook(NewQueryLogger(...))
+func (o *ORM) SetLogger(writer QueryLogWriter) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+
+	if writer == nil {
+		o.logHook = nil
+		return
+	}
+	o.logHook = NewQueryLogger(QueryLoggerConfig{
+		Writer:        writer,
+		SlowThreshold: o.config.SlowThreshold,
+		MaskArgs:      o.config.MaskQueryArgs,
+	})
+}
+
+// queryHooks 获取已注册的钩子，SQL日志在其他钩子之前调用
+func (o *ORM) queryHooks() []QueryHook {
+	if o == nil {
+		return nil
+	}
+	o.hooksMu.RLock()
+	defer o.hooksMu.RUnlock()
+	if o.logHook == nil {
+		return o.hooks
+	}
+	return append([]QueryHook{o.logHook}, o.hooks...)
+}
+
+// observe 执行fn并将执行结果通知给钩子
+func (o *ORM) observe(ctx context.Context, inTx bool, query string, args []interface{}, fn func() (int64, error)) {
+	hooks := o.queryHooks()
+	if len(hooks) == 0 {
+		fn()
+		return
+	}
+
+	start := time.Now()
+	rows, err := fn()
+	event := &QueryEvent{
+		Operation:     sqlOperation(query),
+		SQL:           query,
+		Args:          args,
+		RowsAffected:  rows,
+		Duration:      time.Since(start),
+		Err:           err,
+		InTransaction: inTx,
+	}
+	for _, hook := range hooks {
+		hook(ctx, event)
+	}
+}
+
+// observeQuery 执行查询并通知钩子
+func (o *ORM) observeQuery(ctx context.Context, inTx bool, query string, args []interface{}, fn func() (*sql.Rows, error)) (rows *sql.Rows, err error) {
+	o.observe(ctx, inTx, query, args, func() (int64, error) {
+		rows, err = fn()
+		return -1, err
+	})
+	return rows, err
+}
+
+// observeQueryRow 执行单行查询并通知钩子
+func (o *ORM) observeQueryRow(ctx context.Context, inTx bool, query string, args []interface{}, fn func() *sql.Row) (row *sql.Row) {
+	o.observe(ctx, inTx, query, args, func() (int64, error) {
+		row = fn()
+		return -1, row.Err()
+	})
+	return row
+}
+
+// observeExec 执行SQL语句并通知钩子
+func (o *ORM) observeExec(ctx context.Context, inTx bool, query string, args []interface{}, fn func() (sql.Result, error)) (result sql.Result, err error) {
+	o.observe(ctx, inTx, query, args, func() (int64, error) {
+		result, err = fn()
+		if err != nil {
+			return 0, err
+		}
+		affected, affectedErr := result.RowsAffected()
+		if affectedErr != nil {
+			return -1, nil
+		}
+		return affected, nil
+	})
+	return result, err
+}
+
+// sqlOperation 获取SQL语句的操作类型，WITH开头的语句取公用表表达式之后的主语句
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	operation := strings.ToUpper(fields[0])
+	if operation == "WITH" {
+		operation = "SELECT"
+		depth := 0
+		for _, field := range fields[1:] {
+			keyword := strings.ToUpper(field)
+			if depth == 0 && (keyword == "SELECT" || keyword == "INSERT" || keyword == "UPDATE" || keyword == "DELETE") {
+				operation = keyword
+				break
+			}
+			depth += strings.Count(field, "(") - strings.Count(field, ")")
+		}
+	}
+	return operation
+}
@@ -0,0 +1,193 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// TableColumn 数据库中已有列的结构信息
+type TableColumn struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"` // 数据库报告的类型，如 varchar(50)、INTEGER
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default"` // 默认值表达式，没有默认值时为nil
+	Primary  bool    `json:"primary"`
+}
+
+// TableIndex 数据库中已有索引的结构信息
+type TableIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"` // 按索引中的顺序排列，ClickHouse为索引表达式
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+// ListTables 获取当前数据库（模式）中的表名，按名称排序，不含视图和系统表
+func (s *schema) ListTables() ([]string, error) {
+	var query string
+	switch s.orm.config.Type {
+	case MySQL:
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case PostgreSQL:
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case SQLite:
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	case SQLServer:
+		query = "SELECT table_name FROM information_schema.tables WHERE table_type = 'BASE TABLE' ORDER BY table_name"
+	case ClickHouse:
+		query = "SELECT name FROM system.tables WHERE database = currentDatabase() ORDER BY name"
+	case Oracle:
+		query = "SELECT table_name FROM user_tables ORDER BY table_name"
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型")
+	}
+
+	rows, err := s.orm.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// ListColumns 获取表的列信息，按列在表中的顺序排列
+func (s *schema) ListColumns(tableName string) ([]TableColumn, error) {
+	// 各方言的查询统一返回：列名、类型、是否可空（YES/NO）、默认值、是否主键（1/0）
+	var query string
+	switch s.orm.config.Type {
+	case MySQL:
+		query = `SELECT column_name, column_type, is_nullable, column_default, CASE WHEN column_key = 'PRI' THEN 1 ELSE 0 END
+			FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`
+	case PostgreSQL:
+		query = `SELECT c.column_name, c.data_type, c.is_nullable, c.column_default, CASE WHEN k.column_name IS NULL THEN 0 ELSE 1 END
+			FROM information_schema.columns c
+			LEFT JOIN (
+				SELECT ku.table_schema, ku.table_name, ku.column_name FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage ku ON ku.constraint_name = tc.constraint_name AND ku.table_schema = tc.table_schema
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+			) k ON k.table_schema = c.table_schema AND k.table_name = c.table_name AND k.column_name = c.column_name
+			WHERE c.table_schema = current_schema() AND c.table_name = ? ORDER BY c.ordinal_position`
+	case SQLite:
+		query = `SELECT name, type, CASE WHEN "notnull" = 1 OR pk > 0 THEN 'NO' ELSE 'YES' END, dflt_value, CASE WHEN pk > 0 THEN 1 ELSE 0 END
+			FROM pragma_table_info(?) ORDER BY cid`
+	case SQLServer:
+		query = `SELECT c.column_name, c.data_type, c.is_nullable, c.column_default, CASE WHEN k.column_name IS NULL THEN 0 ELSE 1 END
+			FROM information_schema.columns c
+			LEFT JOIN (
+				SELECT ku.table_name, ku.column_name FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage ku ON ku.constraint_name = tc.constraint_name
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+			) k ON k.table_name = c.table_name AND k.column_name = c.column_name
+			WHERE c.table_name = ? ORDER BY c.ordinal_position`
+	case ClickHouse:
+		query = `SELECT name, type, CASE WHEN type LIKE 'Nullable(%' THEN 'YES' ELSE 'NO' END, nullIf(default_expression, ''), is_in_primary_key
+			FROM system.columns WHERE database = currentDatabase() AND table = ? ORDER BY position`
+	case Oracle:
+		query = `SELECT c.column_name, c.data_type, CASE c.nullable WHEN 'Y' THEN 'YES' ELSE 'NO' END, c.data_default,
+			CASE WHEN EXISTS (
+				SELECT 1 FROM user_constraints uc JOIN user_cons_columns ucc ON ucc.constraint_name = uc.constraint_name
+				WHERE uc.constraint_type = 'P' AND uc.table_name = c.table_name AND ucc.column_name = c.column_name
+			) THEN 1 ELSE 0 END
+			FROM user_tab_columns c WHERE c.table_name = ? ORDER BY c.column_id`
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型")
+	}
+
+	rows, err := s.orm.Query(rebind(NewDatabaseManager(s.orm).GetDialect(), query), tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []TableColumn
+	for rows.Next() {
+		var column TableColumn
+		var nullable string
+		var defaultValue sql.NullString
+		var primary int64
+		if err := rows.Scan(&column.Name, &column.Type, &nullable, &defaultValue, &primary); err != nil {
+			return nil, err
+		}
+		column.Nullable = nullable == "YES"
+		column.Primary = primary != 0
+		if defaultValue.Valid {
+			column.Default = &defaultValue.String
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// ListIndexes 获取表的索引信息，按索引名排序，包含主键和唯一约束生成的索引
+func (s *schema) ListIndexes(tableName string) ([]TableIndex, error) {
+	// 各方言的查询统一返回：索引名、列名、是否唯一（1/0）、是否主键（1/0），按索引名和列顺序排列
+	var query string
+	switch s.orm.config.Type {
+	case MySQL:
+		query = `SELECT index_name, column_name, CASE WHEN non_unique = 0 THEN 1 ELSE 0 END, CASE WHEN index_name = 'PRIMARY' THEN 1 ELSE 0 END
+			FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? ORDER BY index_name, seq_in_index`
+	case PostgreSQL:
+		query = `SELECT i.relname, a.attname, CASE WHEN ix.indisunique THEN 1 ELSE 0 END, CASE WHEN ix.indisprimary THEN 1 ELSE 0 END
+			FROM pg_index ix
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+			WHERE n.nspname = current_schema() AND t.relname = ? ORDER BY i.relname, k.ord`
+	case SQLite:
+		query = `SELECT il.name, ii.name, il."unique", CASE WHEN il.origin = 'pk' THEN 1 ELSE 0 END
+			FROM pragma_index_list(?) il JOIN pragma_index_info(il.name) ii ORDER BY il.name, ii.seqno`
+	case SQLServer:
+		query = `SELECT i.name, c.name, CAST(i.is_unique AS INT), CAST(i.is_primary_key AS INT)
+			FROM sys.indexes i
+			JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+			JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+			WHERE i.object_id = OBJECT_ID(?) AND i.name IS NOT NULL ORDER BY i.name, ic.key_ordinal`
+	case ClickHouse:
+		query = `SELECT name, expr, 0, 0 FROM system.data_skipping_indices WHERE database = currentDatabase() AND table = ? ORDER BY name`
+	case Oracle:
+		query = `SELECT i.index_name, ic.column_name, CASE WHEN i.uniqueness = 'UNIQUE' THEN 1 ELSE 0 END,
+			CASE WHEN EXISTS (SELECT 1 FROM user_constraints uc WHERE uc.constraint_type = 'P' AND uc.index_name = i.index_name) THEN 1 ELSE 0 END
+			FROM user_indexes i JOIN user_ind_columns ic ON ic.index_name = i.index_name
+			WHERE i.table_name = ? ORDER BY i.index_name, ic.column_position`
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型")
+	}
+
+	rows, err := s.orm.Query(rebind(NewDatabaseManager(s.orm).GetDialect(), query), tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []TableIndex
+	for rows.Next() {
+		var name, column string
+		var unique, primary int64
+		if err := rows.Scan(&name, &column, &unique, &primary); err != nil {
+			return nil, err
+		}
+		// 同一索引的列相邻，合并为一条索引信息
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+			continue
+		}
+		indexes = append(indexes, TableIndex{
+			Name:    name,
+			Columns: []string{column},
+			Unique:  unique != 0,
+			Primary: primary != 0,
+		})
+	}
+	return indexes, rows.Err()
+}
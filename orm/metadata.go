@@ -0,0 +1,184 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// modelField 映射到列的结构体字段及其解析后的标签
+type modelField struct {
+	index  []int
+	field  reflect.StructField
+	column string
+	tag    FieldTag
+}
+
+// modelMetadata 结构体类型的字段元数据，按类型解析一次后缓存
+type modelMetadata struct {
+	// fields 当前结构体中映射到列的导出字段，按声明顺序排列
+	fields []*modelField
+	// primary 主键列名
+	primary []string
+	// version 乐观锁字段，没有时为nil
+	version *modelField
+	// softDelete 软删除字段，没有时为nil
+	softDelete *modelField
+	// lookup 扫描结果时按小写列名查找字段，包含嵌入结构体中的字段
+	lookup map[string]*modelField
+	// err 解析标签的第一个错误，包含嵌入结构体中的字段
+	err error
+}
+
+// tableInfoKey 表列信息的缓存键，列类型与方言相关，索引名与表名相关
+type tableInfoKey struct {
+	modelType reflect.Type
+	dbType    DatabaseType
+	tableName string
+}
+
+var (
+	// modelMetadataCache 结构体类型到字段元数据的缓存
+	modelMetadataCache sync.Map
+	// tableInfoCache 结构体类型、方言和表名到表列信息的缓存
+	tableInfoCache sync.Map
+)
+
+// RegisterModel 预先解析并缓存模型的字段元数据，通常在程序启动时调用，返回第一个无效的标签
+// 未注册的模型在首次使用时自动解析，注册只是将这部分开销提前
+func RegisterModel(models ...interface{}) error {
+	for _, model := range models {
+		if err := modelTagError(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// modelTagError 获取模型标签的解析错误，建表和迁移前检查以免生成与预期不符的列类型
+func modelTagError(model interface{}) error {
+	if t := structType(model); t != nil {
+		return metadataOf(t).err
+	}
+	return nil
+}
+
+// structType 获取模型的结构体类型，支持指针和切片，非结构体返回nil
+func structType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// metadataOf 获取结构体类型的字段元数据，未缓存时解析并缓存
+func metadataOf(t reflect.Type) *modelMetadata {
+	if cached, ok := modelMetadataCache.Load(t); ok {
+		return cached.(*modelMetadata)
+	}
+	meta, _ := modelMetadataCache.LoadOrStore(t, parseModelMetadata(t))
+	return meta.(*modelMetadata)
+}
+
+// parseModelMetadata 解析结构体类型的字段元数据
+func parseModelMetadata(t reflect.Type) *modelMetadata {
+	meta := &modelMetadata{lookup: make(map[string]*modelField)}
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag := structField.Tag.Get("orm")
+		if tag == "-" || isRelationField(structField) {
+			continue
+		}
+
+		fieldTag, err := parseFieldTag(tag)
+		if err != nil && meta.err == nil {
+			meta.err = fmt.Errorf("字段 %s.%s 的标签无效: %w", t.Name(), structField.Name, err)
+		}
+		field := &modelField{index: []int{i}, field: structField, tag: fieldTag}
+		field.column, _ = fieldColumnName(structField)
+
+		// 与按列名查找字段的规则一致：orm标签、下划线命名、字段名，先声明的字段优先
+		meta.addLookup(field.tag.Column, field)
+		meta.addLookup(camelToSnake(structField.Name), field)
+		meta.addLookup(structField.Name, field)
+
+		if !structField.IsExported() {
+			continue
+		}
+		meta.fields = append(meta.fields, field)
+		if field.tag.Primary {
+			meta.primary = append(meta.primary, field.column)
+		}
+		if field.tag.Version && meta.version == nil && isIntegerKind(structField.Type.Kind()) {
+			meta.version = field
+		}
+		if meta.softDelete == nil && isSoftDeleteField(field) {
+			meta.softDelete = field
+		}
+	}
+
+	// 嵌入结构体的字段在当前结构体的字段之后匹配
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.Anonymous || structField.Type.Kind() != reflect.Struct || structField.Type == timeType {
+			continue
+		}
+		embeddedMeta := metadataOf(structField.Type)
+		if meta.err == nil {
+			meta.err = embeddedMeta.err
+		}
+		for name, embedded := range embeddedMeta.lookup {
+			if _, exists := meta.lookup[name]; !exists {
+				meta.lookup[name] = &modelField{
+					index:  append([]int{i}, embedded.index...),
+					field:  embedded.field,
+					column: embedded.column,
+					tag:    embedded.tag,
+				}
+			}
+		}
+	}
+
+	return meta
+}
+
+// addLookup 添加列名查找项，已存在的名称不覆盖
+func (m *modelMetadata) addLookup(name string, field *modelField) {
+	if name == "" {
+		return
+	}
+	name = strings.ToLower(name)
+	if _, exists := m.lookup[name]; !exists {
+		m.lookup[name] = field
+	}
+}
+
+// fieldByColumn 按列名查找字段，带表名前缀的列（如 users.name）按最后一段查找
+func (m *modelMetadata) fieldByColumn(column string) *modelField {
+	if field, ok := m.lookup[strings.ToLower(column)]; ok {
+		return field
+	}
+	if idx := strings.LastIndex(column, "."); idx >= 0 {
+		return m.fieldByColumn(column[idx+1:])
+	}
+	return nil
+}
+
+// isIntegerKind 是否为整数类型
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package orm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultLatencyBuckets 默认的查询耗时直方图分桶（秒）
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Metrics 查询指标收集器，按Prometheus文本格式导出
+// 包含按操作类型统计的查询数、错误数、耗时直方图以及连接池状态
+type Metrics struct {
+	orm       *ORM
+	namespace string
+	buckets   []float64
+
+	mu        sync.Mutex
+	queries   map[string]uint64
+	errors    map[string]uint64
+	latencies map[string]*latencyHistogram
+}
+
+// latencyHistogram 耗时直方图
+type latencyHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMetrics 创建查询指标收集器并注册到ORM，namespace为指标名前缀，默认为orm
+func NewMetrics(orm *ORM, namespace string, buckets ...float64) *Metrics {
+	if namespace == "" {
+		namespace = "orm"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	m := &Metrics{
+		orm:       orm,
+		namespace: namespace,
+		buckets:   buckets,
+		queries:   make(map[string]uint64),
+		errors:    make(map[string]uint64),
+		latencies: make(map[string]*latencyHistogram),
+	}
+	orm.AddQueryHook(m.observe)
+	return m
+}
+
+// observe 记录一次SQL执行
+func (m *Metrics) observe(ctx context.Context, event *QueryEvent) {
+	operation := event.Operation
+	if operation == "" {
+		operation = "OTHER"
+	}
+	seconds := event.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queries[operation]++
+	if event.Err != nil {
+		m.errors[operation]++
+	}
+
+	histogram, ok := m.latencies[operation]
+	if !ok {
+		histogram = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[operation] = histogram
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			histogram.counts[i]++
+		}
+	}
+	histogram.count++
+	histogram.sum += seconds
+}
+
+// WriteTo 以Prometheus文本格式输出全部指标
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	buffered := bufio.NewWriter(w)
+	cw := &countingWriter{w: buffered}
+	m.writeQueryMetrics(cw)
+	m.writePoolMetrics(cw)
+	if cw.err == nil {
+		cw.err = buffered.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP 实现http.Handler，可直接挂载为 /metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// writeQueryMetrics 输出查询计数与耗时
+func (m *Metrics) writeQueryMetrics(w *countingWriter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	operations := make([]string, 0, len(m.queries))
+	for operation := range m.queries {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	name := m.namespace + "_queries_total"
+	w.printf("# HELP %s 按操作类型统计的SQL执行次数\n# TYPE %s counter\n", name, name)
+	for _, operation := range operations {
+		w.printf("%s{operation=%q} %d\n", name, operation, m.queries[operation])
+	}
+
+	name = m.namespace + "_query_errors_total"
+	w.printf("# HELP %s 按操作类型统计的SQL执行错误次数\n# TYPE %s counter\n", name, name)
+	for _, operation := range operations {
+		w.printf("%s{operation=%q} %d\n", name, operation, m.errors[operation])
+	}
+
+	name = m.namespace + "_query_duration_seconds"
+	w.printf("# HELP %s SQL执行耗时\n# TYPE %s histogram\n", name, name)
+	for _, operation := range operations {
+		histogram := m.latencies[operation]
+		for i, bound := range m.buckets {
+			w.printf("%s_bucket{operation=%q,le=%q} %d\n", name, operation, formatFloat(bound), histogram.counts[i])
+		}
+		w.printf("%s_bucket{operation=%q,le=\"+Inf\"} %d\n", name, operation, histogram.count)
+		w.printf("%s_sum{operation=%q} %s\n", name, operation, formatFloat(histogram.sum))
+		w.printf("%s_count{operation=%q} %d\n", name, operation, histogram.count)
+	}
+}
+
+// writePoolMetrics 输出连接池状态
+func (m *Metrics) writePoolMetrics(w *countingWriter) {
+	db := m.orm.Raw()
+	if db == nil {
+		return
+	}
+	stats := db.Stats()
+
+	gauges := []struct {
+		name  string
+		help  string
+		value int
+	}{
+		{"connections_max_open", "最大打开连接数", stats.MaxOpenConnections},
+		{"connections_open", "当前打开的连接数", stats.OpenConnections},
+		{"connections_in_use", "正在使用的连接数", stats.InUse},
+		{"connections_idle", "空闲连接数", stats.Idle},
+	}
+	for _, gauge := range gauges {
+		name := m.namespace + "_" + gauge.name
+		w.printf("# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, gauge.help, name, name, gauge.value)
+	}
+
+	counters := []struct {
+		name  string
+		help  string
+		value string
+	}{
+		{"connections_wait_total", "等待连接的总次数", strconv.FormatInt(stats.WaitCount, 10)},
+		{"connections_wait_seconds_total", "等待连接的总耗时", formatFloat(stats.WaitDuration.Seconds())},
+		{"connections_max_idle_closed_total", "因超过最大空闲数而关闭的连接数", strconv.FormatInt(stats.MaxIdleClosed, 10)},
+		{"connections_max_lifetime_closed_total", "因超过最大生存时间而关闭的连接数", strconv.FormatInt(stats.MaxLifetimeClosed, 10)},
+	}
+	for _, counter := range counters {
+		name := m.namespace + "_" + counter.name
+		w.printf("# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, counter.help, name, name, counter.value)
+	}
+}
+
+// countingWriter 记录写入字节数和首个错误的写入器
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// printf 格式化写入，出错后不再写入
+func (cw *countingWriter) printf(format string, args ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+// formatFloat 格式化浮点数
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
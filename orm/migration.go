@@ -10,6 +10,15 @@ import (
 type MigrationManager struct {
 	orm        *ORM
 	migrations []Migration
+	dryRun     bool
+}
+
+// MigrationPlan 待执行迁移的计划
+type MigrationPlan struct {
+	// Version 迁移版本
+	Version string
+	// Statements 迁移将执行的SQL语句，代码迁移无法预知时为nil
+	Statements []string
 }
 
 // NewMigrationManager 创建迁移管理器
@@ -22,11 +31,77 @@ func NewMigrationManager(orm *ORM) *MigrationManager {
 
 // AddMigration 添加迁移
 func (mm *MigrationManager) AddMigration(migration Migration) {
+	if sqlMigration, ok := migration.(*SQLMigration); ok && sqlMigration.orm == nil {
+		sqlMigration.orm = mm.orm
+	}
 	mm.migrations = append(mm.migrations, migration)
 }
 
-// Run 运行迁移
+// DryRun 开启DryRun模式：Run只打印待执行迁移的SQL，不修改数据库
+func (mm *MigrationManager) DryRun() *MigrationManager {
+	mm.dryRun = true
+	return mm
+}
+
+// Plan 返回待执行迁移的计划，按版本排序，不修改数据库
+// 迁移表尚不存在时所有迁移均为待执行
+func (mm *MigrationManager) Plan() ([]MigrationPlan, error) {
+	executed := make(map[string]time.Time)
+	exists, err := NewModelManager(mm.orm).hasTable("migrations")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if executed, err = mm.getExecutedMigrations(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(mm.migrations, func(i, j int) bool {
+		return mm.migrations[i].Version() < mm.migrations[j].Version()
+	})
+
+	var plans []MigrationPlan
+	for _, migration := range mm.migrations {
+		if _, done := executed[migration.Version()]; done {
+			continue
+		}
+		plan := MigrationPlan{Version: migration.Version()}
+		if statements, ok := migration.(MigrationStatements); ok {
+			plan.Statements = statements.UpStatements()
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// printPlan 打印待执行迁移的SQL
+func (mm *MigrationManager) printPlan() error {
+	plans, err := mm.Plan()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("迁移计划（DryRun，未执行）:")
+	for _, plan := range plans {
+		fmt.Printf("-- %s\n", plan.Version)
+		if plan.Statements == nil {
+			fmt.Println("-- 代码迁移，无法预览SQL")
+			continue
+		}
+		for _, statement := range plan.Statements {
+			fmt.Println(statement + ";")
+		}
+	}
+	return nil
+}
+
+// Run 运行迁移，DryRun模式下只打印待执行迁移的SQL
 func (mm *MigrationManager) Run() error {
+	if mm.dryRun {
+		return mm.printPlan()
+	}
+
 	// 确保迁移表存在
 	if err := mm.ensureMigrationTable(); err != nil {
 		return err
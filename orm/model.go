@@ -3,11 +3,21 @@ package orm
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 )
 
 // 移除BaseModel，让用户自己定义模型结构
 
+const (
+	// defaultCreatedAtColumn 默认创建时间列
+	defaultCreatedAtColumn = "created_at"
+	// defaultUpdatedAtColumn 默认更新时间列
+	defaultUpdatedAtColumn = "updated_at"
+	// defaultIndexName 标签中仅写 index / unique_index 时的占位名，生成表信息时替换为 idx_表名_列名
+	defaultIndexName = "*"
+)
+
 // ModelManager 模型管理器
 type ModelManager struct {
 	orm *ORM
@@ -19,6 +29,7 @@ func NewModelManager(orm *ORM) *ModelManager {
 }
 
 // GetTableInfo 获取表信息
+// 解析结果按模型类型、数据库类型和表名缓存，返回的TableInfo为副本，可以安全修改
 func (mm *ModelManager) GetTableInfo(model interface{}) *TableInfo {
 	t := reflect.TypeOf(model)
 	if t.Kind() == reflect.Ptr {
@@ -30,54 +41,48 @@ func (mm *ModelManager) GetTableInfo(model interface{}) *TableInfo {
 	}
 
 	tableName := mm.getTableName(model)
-	columns := mm.getColumns(t)
+	key := tableInfoKey{modelType: t, tableName: tableName}
+	if mm.orm != nil {
+		key.dbType = mm.orm.config.Type
+	}
 
-	return &TableInfo{
+	cached, ok := tableInfoCache.Load(key)
+	if !ok {
+		cached, _ = tableInfoCache.LoadOrStore(key, mm.getColumns(t, tableName))
+	}
+
+	info := &TableInfo{
 		Name:    tableName,
-		Columns: columns,
+		Columns: append([]ColumnInfo(nil), cached.([]ColumnInfo)...),
 		Model:   model,
 	}
+	if m, ok := model.(TableOptionsInterface); ok {
+		info.Options = m.TableOptions()
+	}
+	return info
 }
 
 // getTableName 获取表名
 func (mm *ModelManager) getTableName(model interface{}) string {
-	if m, ok := model.(ModelInterface); ok {
-		tableName := m.TableName()
-		if tableName != "" {
-			return tableName
-		}
-	}
-
-	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-
-	return camelToSnake(t.Name())
+	return mm.orm.getTableName(model)
 }
 
 // getColumns 获取列信息
-func (mm *ModelManager) getColumns(t reflect.Type) []ColumnInfo {
+func (mm *ModelManager) getColumns(t reflect.Type, tableName string) []ColumnInfo {
 	var columns []ColumnInfo
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// 跳过未导出的字段
-		if !field.IsExported() {
-			continue
-		}
-
-		// 解析标签
-		tag := field.Tag.Get("orm")
-		if tag == "-" {
-			continue
-		}
-
-		fieldTag := parseFieldTag(tag)
+	// 使用缓存的字段元数据，其中已跳过未导出字段、忽略的字段和关联字段
+	for _, f := range metadataOf(t).fields {
+		field, fieldTag := f.field, f.tag
 		if fieldTag.Column == "" {
 			fieldTag.Column = camelToSnake(field.Name)
 		}
+		if fieldTag.Index == defaultIndexName {
+			fieldTag.Index = "idx_" + tableName + "_" + fieldTag.Column
+		}
+		if fieldTag.UniqueIndex == defaultIndexName {
+			fieldTag.UniqueIndex = "uidx_" + tableName + "_" + fieldTag.Column
+		}
 
 		column := ColumnInfo{
 			Name:          fieldTag.Column,
@@ -88,10 +93,18 @@ func (mm *ModelManager) getColumns(t reflect.Type) []ColumnInfo {
 			AutoIncrement: fieldTag.AutoIncrement,
 			NotNull:       fieldTag.NotNull,
 			Unique:        fieldTag.Unique,
-			Default:       fieldTag.Default,
 			Comment:       fieldTag.Comment,
 			Index:         fieldTag.Index,
+			UniqueIndex:   fieldTag.UniqueIndex,
 			Size:          fieldTag.Size,
+			Precision:     fieldTag.Precision,
+			Scale:         fieldTag.Scale,
+			References:    fieldTag.References,
+			OnDelete:      fieldTag.OnDelete,
+			OnUpdate:      fieldTag.OnUpdate,
+		}
+		if fieldTag.Default != "" {
+			column.Default = fieldTag.Default
 		}
 
 		columns = append(columns, column)
@@ -102,11 +115,49 @@ func (mm *ModelManager) getColumns(t reflect.Type) []ColumnInfo {
 
 // getColumnType 获取列类型
 func (mm *ModelManager) getColumnType(goType reflect.Type, tag FieldTag) string {
+	// JSON列按数据库选择合适的类型
+	if isJSONColumn(tag) && mm.orm != nil {
+		switch mm.orm.config.Type {
+		case SQLite:
+			return "TEXT"
+		case SQLServer:
+			return "NVARCHAR(MAX)"
+		case ClickHouse:
+			return "String"
+		case Oracle:
+			return "CLOB"
+		}
+	}
+
 	// 如果标签中指定了类型，使用标签中的类型
 	if tag.Type != "" {
 		return tag.Type
 	}
 
+	// UUID列在PostgreSQL和ClickHouse中使用原生类型
+	if tag.UUID != "" {
+		if mm.orm != nil && (mm.orm.config.Type == PostgreSQL || mm.orm.config.Type == ClickHouse) {
+			return "UUID"
+		}
+		return "CHAR(36)"
+	}
+
+	// 指定精度的浮点数使用定点小数
+	if tag.Precision > 0 {
+		switch goType.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return fmt.Sprintf("DECIMAL(%d,%d)", tag.Precision, tag.Scale)
+		}
+	}
+
+	// 指针按指向的类型处理，sql.NullTime按时间处理（如软删除字段）
+	for goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+	if goType == nullTimeType {
+		goType = timeType
+	}
+
 	// 获取数据库方言
 	dialect := NewDatabaseManager(mm.orm).GetDialect()
 
@@ -119,31 +170,31 @@ func (mm *ModelManager) CreateTable(model interface{}) error {
 	if tableInfo == nil {
 		return fmt.Errorf("无法获取表信息")
 	}
+	if err := modelTagError(model); err != nil {
+		return err
+	}
 
-	// 构建列定义
+	for _, statement := range mm.createTableStatements(tableInfo) {
+		if _, err := mm.orm.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTableStatements 生成建表及建索引语句
+func (mm *ModelManager) createTableStatements(tableInfo *TableInfo) []string {
 	var columnDefs []ColumnDefinition
 	for _, col := range tableInfo.Columns {
-		colDef := ColumnDefinition{
-			Name:          col.Name,
-			Type:          col.Type,
-			Size:          col.Size,
-			NotNull:       col.NotNull,
-			Primary:       col.Primary,
-			AutoIncrement: col.AutoIncrement,
-			Unique:        col.Unique,
-			Default:       col.Default,
-			Comment:       col.Comment,
-		}
-		columnDefs = append(columnDefs, colDef)
+		columnDefs = append(columnDefs, col.definition())
 	}
 
-	// 获取方言并生成SQL
 	dialect := NewDatabaseManager(mm.orm).GetDialect()
-	sql := dialect.CreateTableSQL(tableInfo.Name, columnDefs)
-
-	// 执行SQL
-	_, err := mm.orm.Exec(sql)
-	return err
+	statements := createTableStatements(dialect, tableInfo.Name, columnDefs, tableInfo.Options)
+	for _, index := range tableInfo.Indexes() {
+		statements = append(statements, dialect.CreateIndexSQL(tableInfo.Name, index.Name, index.Columns, index.Unique))
+	}
+	return statements
 }
 
 // DropTable 删除表
@@ -156,10 +207,37 @@ func (mm *ModelManager) DropTable(model interface{}) error {
 	return err
 }
 
+// Truncate 清空表数据并重置自增序列
+// SQLite没有TRUNCATE语句，删除全部数据后清除sqlite_sequence中的序列记录
+func (mm *ModelManager) Truncate(model interface{}) error {
+	tableName := mm.getTableName(model)
+	dialect := NewDatabaseManager(mm.orm).GetDialect()
+
+	if _, err := mm.orm.Exec(dialect.TruncateTableSQL(tableName)); err != nil {
+		return err
+	}
+	if mm.orm.config.Type != SQLite {
+		return nil
+	}
+
+	var sequences int
+	if err := mm.orm.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_sequence'").Scan(&sequences); err != nil {
+		return err
+	}
+	if sequences > 0 {
+		_, err := mm.orm.Exec("DELETE FROM sqlite_sequence WHERE name = ?", tableName)
+		return err
+	}
+	return nil
+}
+
 // HasTable 检查表是否存在
 func (mm *ModelManager) HasTable(model interface{}) (bool, error) {
-	tableName := mm.getTableName(model)
+	return mm.hasTable(mm.getTableName(model))
+}
 
+// hasTable 按表名检查表是否存在
+func (mm *ModelManager) hasTable(tableName string) (bool, error) {
 	var sql string
 	switch mm.orm.config.Type {
 	case MySQL:
@@ -170,39 +248,281 @@ func (mm *ModelManager) HasTable(model interface{}) (bool, error) {
 		sql = "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?"
 	case SQLServer:
 		sql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ?"
+	case ClickHouse:
+		sql = "SELECT COUNT(*) FROM system.tables WHERE database = currentDatabase() AND name = ?"
+	case Oracle:
+		sql = "SELECT COUNT(*) FROM user_tables WHERE table_name = ?"
 	default:
 		return false, fmt.Errorf("不支持的数据库类型")
 	}
 
 	var count int
-	err := mm.orm.QueryRow(sql, tableName).Scan(&count)
+	err := mm.orm.QueryRow(rebind(NewDatabaseManager(mm.orm).GetDialect(), sql), tableName).Scan(&count)
 	return count > 0, err
 }
 
-// AutoMigrate 自动迁移
+// AutoMigrate 自动迁移：创建缺失的表和多对多中间表，为已存在的表添加缺失的列和索引，修改类型变化的列
+// Config.MigrateSafeMode 为 true 时只执行新增表、列和索引的语句，修改列的语句只打印不执行
 func (mm *ModelManager) AutoMigrate(models ...interface{}) error {
+	statements, err := mm.autoMigrateStatements(models...)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		if statement.modify && mm.orm.config.MigrateSafeMode {
+			fmt.Printf("安全模式，跳过修改列: %s;\n", statement.sql)
+			continue
+		}
+		if _, err := mm.orm.Exec(statement.sql); err != nil {
+			return fmt.Errorf("执行迁移语句失败: %s: %w", statement.sql, err)
+		}
+	}
+	return nil
+}
+
+// AutoMigratePlan 对比模型与数据库结构，返回需要执行的迁移语句
+// 新增缺失的表、列和索引，列类型与模型不一致时修改列（SQLite不支持修改列，跳过）；
+// 不会删除已有结构，主键和自增列无法通过新增或修改列调整
+func (mm *ModelManager) AutoMigratePlan(models ...interface{}) ([]string, error) {
+	statements, err := mm.autoMigrateStatements(models...)
+	if err != nil {
+		return nil, err
+	}
+	plan := make([]string, len(statements))
+	for i, statement := range statements {
+		plan[i] = statement.sql
+	}
+	return plan, nil
+}
+
+// migrateStatement 自动迁移的一条语句，modify表示修改已有列，安全模式下不执行
+type migrateStatement struct {
+	sql    string
+	modify bool
+}
+
+// autoMigrateStatements 对比模型与数据库结构，生成自动迁移的语句
+func (mm *ModelManager) autoMigrateStatements(models ...interface{}) ([]migrateStatement, error) {
+	dialect := NewDatabaseManager(mm.orm).GetDialect()
+
+	var statements []migrateStatement
+	add := func(sqls ...string) {
+		for _, sql := range sqls {
+			statements = append(statements, migrateStatement{sql: sql})
+		}
+	}
+	var joinTables []joinTable
 	for _, model := range models {
-		exists, err := mm.HasTable(model)
+		tableInfo := mm.GetTableInfo(model)
+		if tableInfo == nil {
+			return nil, fmt.Errorf("无法获取表信息: %T", model)
+		}
+		if err := modelTagError(model); err != nil {
+			return nil, err
+		}
+
+		tables, err := mm.joinTables(model)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		joinTables = append(joinTables, tables...)
 
+		exists, err := mm.HasTable(model)
+		if err != nil {
+			return nil, err
+		}
 		if !exists {
-			if err := mm.CreateTable(model); err != nil {
-				return err
+			add(mm.createTableStatements(tableInfo)...)
+			continue
+		}
+
+		columns, err := mm.existingColumns(tableInfo.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range tableInfo.Columns {
+			if col.Primary || col.AutoIncrement {
+				continue
+			}
+			existing, ok := columns[strings.ToLower(col.Name)]
+			if !ok {
+				add(dialect.AddColumnSQL(tableInfo.Name, col.Name, col.definition()))
+				continue
+			}
+			if columnTypesMatch(existing.Type, col.Type) {
+				continue
+			}
+			if sql := dialect.ModifyColumnSQL(tableInfo.Name, col.Name, col.definition()); sql != "" {
+				statements = append(statements, migrateStatement{sql: sql, modify: true})
+			}
+		}
+
+		indexes, err := mm.existingIndexes(tableInfo.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range tableInfo.Indexes() {
+			if indexes[strings.ToLower(index.Name)] {
+				continue
 			}
-		} else {
-			// TODO: 实现表结构更新逻辑
+			add(dialect.CreateIndexSQL(tableInfo.Name, index.Name, index.Columns, index.Unique))
 		}
 	}
 
-	return nil
+	planned := make(map[string]bool)
+	for _, table := range joinTables {
+		if planned[table.name] {
+			continue
+		}
+		planned[table.name] = true
+
+		exists, err := mm.hasTable(table.name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			add(dialect.CreateTableSQL(table.name, table.columns))
+		}
+	}
+
+	return statements, nil
+}
+
+// joinTable 多对多关联的中间表定义
+type joinTable struct {
+	name    string
+	columns []ColumnDefinition
+}
+
+// joinTables 获取模型中多对多关联的中间表定义
+// 中间表由两个键列组成复合主键，列类型取自两侧模型的键字段
+func (mm *ModelManager) joinTables(model interface{}) ([]joinTable, error) {
+	t := structType(model)
+	if t == nil {
+		return nil, nil
+	}
+	parent := reflect.New(t).Elem()
+
+	var declared map[string]Relation
+	if r, ok := parent.Addr().Interface().(RelationInterface); ok {
+		declared = r.Relations()
+	}
+
+	var tables []joinTable
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := declared[field.Name]; !ok && !isRelationField(field) {
+			continue
+		}
+
+		_, relatedType, relation, err := mm.orm.lookupRelation(parent, field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if relation.Type != Many2Many {
+			continue
+		}
+
+		ownerKey := metadataOf(t).fieldByColumn(relation.References)
+		relatedKey := metadataOf(relatedType).fieldByColumn(relatedKeyColumn(relatedType))
+		if ownerKey == nil || relatedKey == nil {
+			return nil, fmt.Errorf("无法确定多对多关联 %s 的键列", field.Name)
+		}
+
+		tables = append(tables, joinTable{
+			name: relation.JoinTable,
+			columns: []ColumnDefinition{
+				{Name: relation.JoinForeignKey, Type: mm.getColumnType(ownerKey.field.Type, ownerKey.tag), NotNull: true, Primary: true},
+				{Name: relation.JoinReferences, Type: mm.getColumnType(relatedKey.field.Type, relatedKey.tag), NotNull: true, Primary: true},
+			},
+		})
+	}
+	return tables, nil
+}
+
+// existingColumns 查询表中已有的列，键为小写列名
+func (mm *ModelManager) existingColumns(tableName string) (map[string]TableColumn, error) {
+	columns, err := NewSchema(mm.orm).ListColumns(tableName)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]TableColumn, len(columns))
+	for _, column := range columns {
+		existing[strings.ToLower(column.Name)] = column
+	}
+	return existing, nil
+}
+
+// columnTypeAliases 各数据库报告的类型名与建表时使用的类型名的对应关系
+var columnTypeAliases = map[string]string{
+	"tinyint(1)":                  "boolean",
+	"character varying":           "varchar",
+	"character":                   "char",
+	"integer":                     "int",
+	"int4":                        "int",
+	"int8":                        "bigint",
+	"int2":                        "smallint",
+	"bool":                        "boolean",
+	"double precision":            "double",
+	"float8":                      "double",
+	"float4":                      "real",
+	"numeric":                     "decimal",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+}
+
+// columnTypesMatch 数据库中已有列的类型与模型的列类型是否一致，忽略大小写、空白和类型别名
+// 数据库只报告类型名而不带长度、精度时（如PostgreSQL、SQL Server、Oracle）只比较类型名
+func columnTypesMatch(existing, wanted string) bool {
+	existingName, existingParams := splitColumnType(existing)
+	wantedName, wantedParams := splitColumnType(wanted)
+	if existingName != wantedName {
+		return false
+	}
+	return existingParams == "" || wantedParams == "" || existingParams == wantedParams
+}
+
+// splitColumnType 将列类型规范化并拆分为类型名和参数，如 VARCHAR(50) 拆分为 varchar 和 (50)，
+// INT(10) UNSIGNED 拆分为 int unsigned 和 (10)；ClickHouse的 Nullable(T) 按T比较
+func splitColumnType(columnType string) (string, string) {
+	columnType = strings.ToLower(strings.Join(strings.Fields(columnType), " "))
+	if strings.HasPrefix(columnType, "nullable(") && strings.HasSuffix(columnType, ")") {
+		columnType = columnType[len("nullable(") : len(columnType)-1]
+	}
+	if alias, ok := columnTypeAliases[columnType]; ok {
+		columnType = alias
+	}
+
+	name, params := columnType, ""
+	if i, j := strings.Index(columnType, "("), strings.LastIndex(columnType, ")"); i >= 0 && j > i {
+		name = strings.TrimSpace(strings.TrimSpace(columnType[:i]) + " " + strings.TrimSpace(columnType[j+1:]))
+		params = strings.ReplaceAll(columnType[i:j+1], " ", "")
+	}
+	if alias, ok := columnTypeAliases[name]; ok {
+		name = alias
+	}
+	return name, params
+}
+
+// existingIndexes 查询表中已有的索引名（小写）
+func (mm *ModelManager) existingIndexes(tableName string) (map[string]bool, error) {
+	indexes, err := NewSchema(mm.orm).ListIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		names[strings.ToLower(index.Name)] = true
+	}
+	return names, nil
 }
 
 // TableInfo 表信息
 type TableInfo struct {
 	Name    string       `json:"name"`
 	Columns []ColumnInfo `json:"columns"`
+	Options TableOptions `json:"options"`
 	Model   interface{}  `json:"-"`
 }
 
@@ -219,10 +539,36 @@ type ColumnInfo struct {
 	Default       interface{}  `json:"default"`
 	Comment       string       `json:"comment"`
 	Index         string       `json:"index"`
+	UniqueIndex   string       `json:"unique_index"`
 	Size          int          `json:"size"`
+	Precision     int          `json:"precision"`
+	Scale         int          `json:"scale"`
+	References    string       `json:"references"` // 外键引用，如 users.id
+	OnDelete      string       `json:"on_delete"`  // 外键的删除动作，如 CASCADE
+	OnUpdate      string       `json:"on_update"`  // 外键的更新动作
 }
 
-// GetPrimaryKey 获取主键列
+// definition 转换为列定义
+func (ci ColumnInfo) definition() ColumnDefinition {
+	return ColumnDefinition{
+		Name:          ci.Name,
+		Type:          ci.Type,
+		Size:          ci.Size,
+		NotNull:       ci.NotNull,
+		Primary:       ci.Primary,
+		AutoIncrement: ci.AutoIncrement,
+		Unique:        ci.Unique,
+		Default:       ci.Default,
+		Comment:       ci.Comment,
+		Precision:     ci.Precision,
+		Scale:         ci.Scale,
+		References:    ci.References,
+		OnDelete:      ci.OnDelete,
+		OnUpdate:      ci.OnUpdate,
+	}
+}
+
+// GetPrimaryKey 获取主键列，复合主键时返回第一列
 func (ti *TableInfo) GetPrimaryKey() *ColumnInfo {
 	for _, col := range ti.Columns {
 		if col.Primary {
@@ -232,6 +578,17 @@ func (ti *TableInfo) GetPrimaryKey() *ColumnInfo {
 	return nil
 }
 
+// GetPrimaryKeys 获取全部主键列，按字段声明顺序排列
+func (ti *TableInfo) GetPrimaryKeys() []ColumnInfo {
+	var keys []ColumnInfo
+	for _, col := range ti.Columns {
+		if col.Primary {
+			keys = append(keys, col)
+		}
+	}
+	return keys
+}
+
 // GetColumnByName 根据名称获取列
 func (ti *TableInfo) GetColumnByName(name string) *ColumnInfo {
 	for _, col := range ti.Columns {
@@ -242,6 +599,30 @@ func (ti *TableInfo) GetColumnByName(name string) *ColumnInfo {
 	return nil
 }
 
+// Indexes 汇总列标签中声明的索引，同名索引按字段顺序组成复合索引
+func (ti *TableInfo) Indexes() []IndexDefinition {
+	var indexes []IndexDefinition
+	positions := make(map[string]int)
+
+	add := func(name, column string, unique bool) {
+		if name == "" {
+			return
+		}
+		if i, ok := positions[name]; ok {
+			indexes[i].Columns = append(indexes[i].Columns, column)
+			return
+		}
+		positions[name] = len(indexes)
+		indexes = append(indexes, IndexDefinition{Name: name, Columns: []string{column}, Unique: unique})
+	}
+
+	for _, col := range ti.Columns {
+		add(col.Index, col.Name, false)
+		add(col.UniqueIndex, col.Name, true)
+	}
+	return indexes
+}
+
 // GetColumnNames 获取所有列名
 func (ti *TableInfo) GetColumnNames() []string {
 	names := make([]string, len(ti.Columns))
@@ -281,33 +662,57 @@ func (mm *ModelManager) ValidateModel(model interface{}) error {
 }
 
 // SetTimestamps 设置时间戳
+// 新建记录时仅填充为零值的创建/更新时间列，更新记录时总是刷新更新时间列
 func (mm *ModelManager) SetTimestamps(model interface{}, isUpdate bool) {
 	v := reflect.ValueOf(model)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 
-	if v.Kind() != reflect.Struct {
+	if v.Kind() != reflect.Struct || !v.CanSet() {
 		return
 	}
 
 	now := time.Now()
+	createdAtColumn, updatedAtColumn := mm.timestampColumns()
 
 	// 设置UpdatedAt
-	if field := v.FieldByName("UpdatedAt"); field.IsValid() && field.CanSet() {
-		if field.Type() == reflect.TypeOf(time.Time{}) {
-			field.Set(reflect.ValueOf(now))
+	if field := findFieldByColumn(v, updatedAtColumn); field.IsValid() && field.CanSet() {
+		if isUpdate || isZeroValue(field) {
+			setTimeField(field, now)
 		}
 	}
 
 	// 如果是新建记录，设置CreatedAt
 	if !isUpdate {
-		if field := v.FieldByName("CreatedAt"); field.IsValid() && field.CanSet() {
-			if field.Type() == reflect.TypeOf(time.Time{}) && field.Interface().(time.Time).IsZero() {
-				field.Set(reflect.ValueOf(now))
-			}
+		if field := findFieldByColumn(v, createdAtColumn); field.IsValid() && field.CanSet() && isZeroValue(field) {
+			setTimeField(field, now)
+		}
+	}
+}
+
+// timestampColumns 获取创建时间和更新时间的列名
+func (mm *ModelManager) timestampColumns() (string, string) {
+	createdAt, updatedAt := defaultCreatedAtColumn, defaultUpdatedAtColumn
+	if mm.orm != nil && mm.orm.config != nil {
+		if mm.orm.config.CreatedAtColumn != "" {
+			createdAt = mm.orm.config.CreatedAtColumn
+		}
+		if mm.orm.config.UpdatedAtColumn != "" {
+			updatedAt = mm.orm.config.UpdatedAtColumn
 		}
 	}
+	return createdAt, updatedAt
+}
+
+// setTimeField 设置time.Time或*time.Time类型的字段
+func setTimeField(field reflect.Value, t time.Time) {
+	switch field.Type() {
+	case reflect.TypeOf(time.Time{}):
+		field.Set(reflect.ValueOf(t))
+	case reflect.TypeOf(&time.Time{}):
+		field.Set(reflect.ValueOf(&t))
+	}
 }
 
 // 全局便捷方法
@@ -318,6 +723,11 @@ func AutoMigrate(models ...interface{}) error {
 	return mm.AutoMigrate(models...)
 }
 
+// AutoMigratePlan 返回自动迁移需要执行的语句
+func AutoMigratePlan(models ...interface{}) ([]string, error) {
+	return NewModelManager(GetGlobalORM()).AutoMigratePlan(models...)
+}
+
 // CreateTable 创建表
 func CreateTable(model interface{}) error {
 	mm := NewModelManager(GetGlobalORM())
@@ -0,0 +1,95 @@
+package orm
+
+import "reflect"
+
+// 模型生命周期钩子：模型实现以下接口时，查询构建器在对应操作前后调用
+// tx 为当前事务，不在事务中执行时为nil；Before钩子返回错误时中止操作，After钩子的错误作为操作结果返回
+
+// BeforeInsertInterface 插入前调用，可用于设置默认值、加密密码等
+type BeforeInsertInterface interface {
+	BeforeInsert(tx Tx) error
+}
+
+// AfterInsertInterface 插入后调用，自增主键已回填
+type AfterInsertInterface interface {
+	AfterInsert(tx Tx) error
+}
+
+// BeforeUpdateInterface 按结构体更新前调用
+type BeforeUpdateInterface interface {
+	BeforeUpdate(tx Tx) error
+}
+
+// AfterUpdateInterface 按结构体更新后调用
+type AfterUpdateInterface interface {
+	AfterUpdate(tx Tx) error
+}
+
+// BeforeDeleteInterface 通过Model(&record)删除前调用
+type BeforeDeleteInterface interface {
+	BeforeDelete(tx Tx) error
+}
+
+// AfterDeleteInterface 通过Model(&record)删除后调用
+type AfterDeleteInterface interface {
+	AfterDelete(tx Tx) error
+}
+
+// AfterFindInterface 查询结果扫描完成后对每条记录调用
+type AfterFindInterface interface {
+	AfterFind(tx Tx) error
+}
+
+// callModelHooks 对data中实现钩子接口H的每个结构体调用call，支持结构体、结构体指针及其切片
+func callModelHooks[H any](data interface{}, call func(hook H) error) error {
+	if data == nil {
+		return nil
+	}
+	for _, v := range collectStructs(reflect.ValueOf(data)) {
+		model := v.Interface()
+		if v.CanAddr() {
+			model = v.Addr().Interface()
+		}
+		if hook, ok := model.(H); ok {
+			if err := call(hook); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// beforeInsert 调用插入前钩子
+func (qb *queryBuilder) beforeInsert(data interface{}) error {
+	return callModelHooks(data, func(hook BeforeInsertInterface) error { return hook.BeforeInsert(qb.tx) })
+}
+
+// afterInsert 调用插入后钩子
+func (qb *queryBuilder) afterInsert(data interface{}) error {
+	return callModelHooks(data, func(hook AfterInsertInterface) error { return hook.AfterInsert(qb.tx) })
+}
+
+// beforeUpdate 调用更新前钩子
+func (qb *queryBuilder) beforeUpdate(data interface{}) error {
+	return callModelHooks(data, func(hook BeforeUpdateInterface) error { return hook.BeforeUpdate(qb.tx) })
+}
+
+// afterUpdate 调用更新后钩子
+func (qb *queryBuilder) afterUpdate(data interface{}) error {
+	return callModelHooks(data, func(hook AfterUpdateInterface) error { return hook.AfterUpdate(qb.tx) })
+}
+
+// beforeDelete 调用删除前钩子
+func (qb *queryBuilder) beforeDelete(data interface{}) error {
+	return callModelHooks(data, func(hook BeforeDeleteInterface) error { return hook.BeforeDelete(qb.tx) })
+}
+
+// afterDelete 调用删除后钩子
+func (qb *queryBuilder) afterDelete(data interface{}) error {
+	return callModelHooks(data, func(hook AfterDeleteInterface) error { return hook.AfterDelete(qb.tx) })
+}
+
+// afterFind 调用查询后钩子
+func (qb *queryBuilder) afterFind(dest interface{}) error {
+	return callModelHooks(dest, func(hook AfterFindInterface) error { return hook.AfterFind(qb.tx) })
+}
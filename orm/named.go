@@ -0,0 +1,105 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// bindNamed 将SQL中的 :name 命名参数替换为 ? 并按出现顺序排列参数，如
+// Where("name = :name AND age > :age", map[string]interface{}{"name": "张三", "age": 18})
+// 只在参数为单个map时生效；引号内的内容和PostgreSQL的 :: 类型转换不被替换；
+// 切片参数展开为多个占位符，用于 IN (:ids)；缺少的参数在执行时返回错误
+func bindNamed(query string, args []interface{}) (string, []interface{}, bool) {
+	if len(args) != 1 || !strings.Contains(query, ":") {
+		return query, args, false
+	}
+	params, ok := args[0].(map[string]interface{})
+	if !ok {
+		return query, args, false
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(query))
+	var bound []interface{}
+	found := false
+
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			builder.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNamePart(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			placeholders, values := namedValue(name, params)
+			builder.WriteString(placeholders)
+			bound = append(bound, values...)
+			found = true
+			i = end - 1
+			continue
+		}
+		builder.WriteByte(c)
+	}
+
+	if !found {
+		return query, args, false
+	}
+	return builder.String(), bound, true
+}
+
+// namedValue 获取命名参数的占位符和值，切片（[]byte除外）展开为逗号分隔的多个占位符
+func namedValue(name string, params map[string]interface{}) (string, []interface{}) {
+	value, ok := params[name]
+	if !ok {
+		return "?", []interface{}{errorValuer{fmt.Errorf("缺少命名参数: %s", name)}}
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return "?", []interface{}{value}
+	}
+	if v.Len() == 0 {
+		// 空切片生成 NULL，使 IN (NULL) 不匹配任何记录
+		return "NULL", nil
+	}
+
+	placeholders := make([]string, v.Len())
+	values := make([]interface{}, v.Len())
+	for i := range placeholders {
+		placeholders[i] = "?"
+		values[i] = v.Index(i).Interface()
+	}
+	return strings.Join(placeholders, ", "), values
+}
+
+// isNameStart 是否可作为命名参数的首字符
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isNamePart 是否可作为命名参数的后续字符
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// bindNamedSQL 替换原始SQL中的命名参数为当前方言的占位符
+func (o *ORM) bindNamedSQL(query string, args []interface{}) (string, []interface{}) {
+	query, args, ok := bindNamed(query, args)
+	if ok {
+		query = rebind(NewDatabaseManager(o).GetDialect(), query)
+	}
+	return query, args
+}
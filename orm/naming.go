@@ -0,0 +1,95 @@
+package orm
+
+import "strings"
+
+// NamingStrategy 命名策略，由结构体名生成未实现TableName()的模型对应的表名
+type NamingStrategy interface {
+	TableName(structName string) string
+}
+
+// SnakeCaseNaming 下划线命名策略，如 UserProfile 对应 user_profile，为默认策略
+type SnakeCaseNaming struct{}
+
+// TableName 将结构体名转换为下划线命名
+func (SnakeCaseNaming) TableName(structName string) string {
+	return camelToSnake(structName)
+}
+
+// PluralNaming 下划线复数命名策略，如 UserProfile 对应 user_profiles，Category 对应 categories
+type PluralNaming struct{}
+
+// TableName 将结构体名转换为下划线命名并将最后一个单词变为复数
+func (PluralNaming) TableName(structName string) string {
+	return pluralize(camelToSnake(structName))
+}
+
+// ForeignKeyNaming 可选的外键命名策略，命名策略同时实现该接口时用于生成关联的默认外键列和多对多中间表的键列
+// 未实现时使用下划线命名加 _id 后缀，如 UserProfile 对应 user_profile_id
+type ForeignKeyNaming interface {
+	ForeignKey(name string) string
+}
+
+// NamingFunc 以函数实现的自定义命名策略
+type NamingFunc func(structName string) string
+
+// TableName 调用函数生成表名
+func (f NamingFunc) TableName(structName string) string {
+	return f(structName)
+}
+
+// pluralize 按英语的常见规则将单词变为复数
+func pluralize(word string) string {
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// getTableName 获取模型对应的表名
+// 实现了TableName()且返回非空时原样使用，否则按配置的命名策略由结构体名生成并加上表前缀
+func (o *ORM) getTableName(model interface{}) string {
+	if m, ok := model.(ModelInterface); ok {
+		if tableName := m.TableName(); tableName != "" {
+			return tableName
+		}
+	}
+
+	return o.tableNameOf(getStructName(model))
+}
+
+// tableNameOf 按配置的命名策略由结构体名生成表名并加上表前缀
+func (o *ORM) tableNameOf(structName string) string {
+	if o == nil {
+		return camelToSnake(structName)
+	}
+	return o.config.TablePrefix + o.namingStrategy().TableName(structName)
+}
+
+// namingStrategy 获取配置的命名策略，未配置时为下划线命名
+func (o *ORM) namingStrategy() NamingStrategy {
+	if o == nil || o.config.NamingStrategy == nil {
+		return SnakeCaseNaming{}
+	}
+	return o.config.NamingStrategy
+}
+
+// joinTableName 多对多关联的默认中间表名，由两个结构体名拼接后按命名策略生成并加上表前缀
+// 如 User 与 Role 默认对应 user_role，使用 PluralNaming 时对应 user_roles
+func (o *ORM) joinTableName(owner, related string) string {
+	return o.tableNameOf(owner + related)
+}
+
+// foreignKeyName 由结构体名或字段名生成默认外键列，命名策略实现了ForeignKeyNaming时使用其规则
+func (o *ORM) foreignKeyName(name string) string {
+	if naming, ok := o.namingStrategy().(ForeignKeyNaming); ok {
+		return naming.ForeignKey(name)
+	}
+	return camelToSnake(name) + "_id"
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"reflect"
+	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	_ "github.com/denisenkom/go-mssqldb"
 	_ "github.com/go-sql-driver/mysql"
@@ -24,7 +25,22 @@ var (
 type ORM struct {
 	config *Config
 	db     *sql.DB
+	loc    *time.Location
 	mu     sync.RWMutex
+
+	replicas *replicaPool
+	stmts    *stmtCache
+
+	healthStop chan struct{}
+	healthErr  error
+	healthMu   sync.RWMutex
+
+	hooks   []QueryHook
+	logHook QueryHook
+	hooksMu sync.RWMutex
+
+	cache   QueryCache
+	cacheMu sync.Mutex
 }
 
 // New 创建新的ORM实例
@@ -33,9 +49,16 @@ func New(config *Config) *ORM {
 		config = DefaultConfig()
 	}
 
-	return &ORM{
+	o := &ORM{
 		config: config,
 	}
+	if config.LogQueries {
+		o.logHook = NewQueryLogger(QueryLoggerConfig{
+			SlowThreshold: config.SlowThreshold,
+			MaskArgs:      config.MaskQueryArgs,
+		})
+	}
+	return o
 }
 
 // Init 初始化全局ORM实例
@@ -56,34 +79,53 @@ func GetGlobalORM() *ORM {
 	return globalORM
 }
 
-// Connect 连接数据库
+// Connect 连接数据库，配置了只读副本时一并连接副本
+// 连接失败时按ConnectRetries重试，设置了HealthCheckInterval时启动后台健康检查
 func (o *ORM) Connect() error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	dsn, err := o.buildDSN()
+	loc, err := loadLocation(o.config.Timezone)
 	if err != nil {
-		return fmt.Errorf("构建DSN失败: %w", err)
+		return err
 	}
+	o.loc = loc
 
-	db, err := sql.Open(string(o.config.Type), dsn)
+	db, err := openWithRetry(o.config)
 	if err != nil {
-		return fmt.Errorf("打开数据库连接失败: %w", err)
+		return err
+	}
+
+	o.db = db
+	o.replicas = connectReplicas(o.config)
+	o.resetStmtCache()
+	o.startHealthCheck()
+	return nil
+}
+
+// openDB 按配置打开数据库连接并设置连接池
+func openDB(config *Config) (*sql.DB, error) {
+	dsn, err := (&ORM{config: config}).buildDSN()
+	if err != nil {
+		return nil, fmt.Errorf("构建DSN失败: %w", err)
+	}
+
+	db, err := sql.Open(string(config.Type), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库连接失败: %w", err)
 	}
 
 	// 设置连接池参数
-	db.SetMaxOpenConns(o.config.MaxOpenConns)
-	db.SetMaxIdleConns(o.config.MaxIdleConns)
-	db.SetConnMaxLifetime(o.config.MaxLifetime)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.MaxLifetime)
 
 	// 测试连接
 	if err := db.Ping(); err != nil {
 		db.Close()
-		return fmt.Errorf("数据库连接测试失败: %w", err)
+		return nil, fmt.Errorf("数据库连接测试失败: %w", err)
 	}
-
-	o.db = db
-	return nil
+	return db, nil
 }
 
 // Close 关闭数据库连接
@@ -91,6 +133,12 @@ func (o *ORM) Close() error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
+	o.stopHealthCheck()
+	o.replicas.close()
+	if o.stmts != nil {
+		o.stmts.clear()
+		o.stmts = nil
+	}
 	if o.db != nil {
 		return o.db.Close()
 	}
@@ -110,35 +158,73 @@ func (o *ORM) Ping() error {
 
 // Query 执行查询
 func (o *ORM) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
+	return o.QueryContext(context.Background(), query, args...)
+}
 
-	if o.db == nil {
+// QueryContext 执行带上下文的查询
+func (o *ORM) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db, stmts := o.primary()
+	if db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
-	return o.db.Query(query, args...)
+	query, args = o.bindNamedSQL(query, args)
+	return o.observeQuery(ctx, false, query, args, func() (rows *sql.Rows, err error) {
+		if sqlOperation(query) != "SELECT" {
+			// 带RETURNING的写语句只在死锁等语句被回滚的情况下重试
+			o.retryWrite(ctx, func() error {
+				rows, err = dbQuery(ctx, db, stmts, query, args)
+				return err
+			})
+			return rows, err
+		}
+		o.retryRead(ctx, func() error {
+			rows, err = dbQuery(ctx, db, stmts, query, args)
+			return err
+		})
+		return rows, err
+	})
 }
 
 // QueryRow 执行单行查询
 func (o *ORM) QueryRow(query string, args ...interface{}) *sql.Row {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
+	return o.QueryRowContext(context.Background(), query, args...)
+}
 
-	if o.db == nil {
+// QueryRowContext 执行带上下文的单行查询
+func (o *ORM) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	db, stmts := o.primary()
+	if db == nil {
 		panic("数据库未连接")
 	}
-	return o.db.QueryRow(query, args...)
+	query, args = o.bindNamedSQL(query, args)
+	return o.observeQueryRow(ctx, false, query, args, func() (row *sql.Row) {
+		o.retryRead(ctx, func() error {
+			row = dbQueryRow(ctx, db, stmts, query, args)
+			return row.Err()
+		})
+		return row
+	})
 }
 
 // Exec 执行SQL语句
 func (o *ORM) Exec(query string, args ...interface{}) (sql.Result, error) {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
+	return o.ExecContext(context.Background(), query, args...)
+}
 
-	if o.db == nil {
+// ExecContext 执行带上下文的SQL语句
+func (o *ORM) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db, stmts := o.primary()
+	if db == nil {
 		return nil, fmt.Errorf("数据库未连接")
 	}
-	return o.db.Exec(query, args...)
+	query, args = o.bindNamedSQL(query, args)
+	return o.observeExec(ctx, false, query, args, func() (result sql.Result, err error) {
+		o.retryWrite(ctx, func() error {
+			result, err = dbExec(ctx, db, stmts, query, args)
+			return err
+		})
+		return result, err
+	})
 }
 
 // Begin 开始事务
@@ -155,7 +241,7 @@ func (o *ORM) Begin() (Tx, error) {
 		return nil, err
 	}
 
-	return &transaction{tx: tx}, nil
+	return &transaction{tx: tx, orm: o, written: &writtenTables{}}, nil
 }
 
 // BeginTx 开始带选项的事务
@@ -172,7 +258,7 @@ func (o *ORM) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
 		return nil, err
 	}
 
-	return &transaction{tx: tx}, nil
+	return &transaction{tx: tx, orm: o, ctx: ctx, written: &writtenTables{}}, nil
 }
 
 // Raw 获取原始数据库连接
@@ -190,7 +276,21 @@ func (o *ORM) Table(tableName string) QueryBuilder {
 // Model 基于模型创建查询构建器
 func (o *ORM) Model(model interface{}) QueryBuilder {
 	tableName := o.getTableName(model)
-	return NewQueryBuilder(o, tableName)
+	return &queryBuilder{
+		orm:       o,
+		tableName: tableName,
+		model:     model,
+	}
+}
+
+// Truncate 清空模型对应的表并重置自增序列，常用于清理任务和测试
+func (o *ORM) Truncate(model interface{}) error {
+	return NewModelManager(o).Truncate(model)
+}
+
+// WithContext 创建携带上下文的会话，会话中的查询均使用该上下文执行
+func (o *ORM) WithContext(ctx context.Context) *Session {
+	return &Session{orm: o, ctx: ctx}
 }
 
 // buildDSN 构建数据源名称
@@ -204,6 +304,10 @@ func (o *ORM) buildDSN() (string, error) {
 		return o.buildSQLiteDSN(), nil
 	case SQLServer:
 		return o.buildSQLServerDSN(), nil
+	case ClickHouse:
+		return o.buildClickHouseDSN(), nil
+	case Oracle:
+		return o.buildOracleDSN(), nil
 	default:
 		return "", fmt.Errorf("不支持的数据库类型: %s", o.config.Type)
 	}
@@ -224,7 +328,7 @@ func (o *ORM) buildMySQLDSN() string {
 		params = append(params, "charset="+o.config.Charset)
 	}
 	if o.config.Timezone != "" {
-		params = append(params, "loc="+o.config.Timezone)
+		params = append(params, "loc="+url.QueryEscape(o.config.Timezone))
 	}
 
 	if len(params) > 0 {
@@ -270,21 +374,29 @@ func (o *ORM) buildSQLServerDSN() string {
 	)
 }
 
-// getTableName 获取表名
-func (o *ORM) getTableName(model interface{}) string {
-	if m, ok := model.(ModelInterface); ok {
-		return m.TableName()
+// buildClickHouseDSN 构建ClickHouse DSN（clickhouse-go格式）
+func (o *ORM) buildClickHouseDSN() string {
+	dsn := url.URL{
+		Scheme: "clickhouse",
+		User:   url.UserPassword(o.config.Username, o.config.Password),
+		Host:   fmt.Sprintf("%s:%d", o.config.Host, o.config.Port),
+		Path:   "/" + o.config.Database,
 	}
-
-	// 使用反射获取结构体名称
-	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	if o.config.SSLMode != "" && o.config.SSLMode != "disable" {
+		dsn.RawQuery = "secure=true"
 	}
+	return dsn.String()
+}
 
-	// 将驼峰命名转换为下划线命名
-	name := t.Name()
-	return camelToSnake(name)
+// buildOracleDSN 构建Oracle DSN（go-ora格式），Database为服务名
+func (o *ORM) buildOracleDSN() string {
+	dsn := url.URL{
+		Scheme: "oracle",
+		User:   url.UserPassword(o.config.Username, o.config.Password),
+		Host:   fmt.Sprintf("%s:%d", o.config.Host, o.config.Port),
+		Path:   "/" + o.config.Database,
+	}
+	return dsn.String()
 }
 
 // camelToSnake 驼峰命名转下划线命名
@@ -330,3 +442,33 @@ func Begin() (Tx, error) {
 func Raw() *sql.DB {
 	return GetGlobalORM().Raw()
 }
+
+// WithContext 创建携带上下文的会话
+func WithContext(ctx context.Context) *Session {
+	return GetGlobalORM().WithContext(ctx)
+}
+
+// Stats 获取全局ORM主库连接池的统计信息
+func Stats() sql.DBStats {
+	return GetGlobalORM().Stats()
+}
+
+// HealthCheck 立即检测全局ORM的数据库连接
+func HealthCheck(ctx context.Context) error {
+	return GetGlobalORM().HealthCheck(ctx)
+}
+
+// SetLogger 设置全局ORM的SQL日志输出
+func SetLogger(writer QueryLogWriter) {
+	GetGlobalORM().SetLogger(writer)
+}
+
+// QueryContext 执行带上下文的查询
+func QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return GetGlobalORM().QueryContext(ctx, query, args...)
+}
+
+// ExecContext 执行带上下文的SQL语句
+func ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return GetGlobalORM().ExecContext(ctx, query, args...)
+}
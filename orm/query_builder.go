@@ -1,25 +1,79 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 )
 
+const (
+	// defaultPerPage 默认每页数量
+	defaultPerPage = 15
+	// defaultBatchSize 默认批量插入分块大小
+	defaultBatchSize = 500
+)
+
+// sqlStatement SQL语句及参数
+type sqlStatement struct {
+	query string
+	args  []interface{}
+	// records 插入语句对应的结构体，用于回填自增主键
+	records []reflect.Value
+}
+
+// unionClause UNION子句
+type unionClause struct {
+	all   bool
+	query *queryBuilder
+}
+
+// cteClause 公用表表达式（WITH子句）
+type cteClause struct {
+	name      string
+	recursive bool
+	query     *queryBuilder
+}
+
 // queryBuilder 查询构建器实现
 type queryBuilder struct {
 	orm        *ORM
 	tx         Tx
+	ctx        context.Context
+	model      interface{}
 	tableName  string
+	fromSub    *queryBuilder
 	selectCols []string
+	selectArgs []interface{}
+	distinct   bool
 	conditions []QueryCondition
 	joins      []JoinClause
 	orders     []OrderClause
-	groups     []string
+	groups     []groupClause
 	havings    []HavingClause
 	limitNum   int
 	offsetNum  int
+	batchSize  int
+	lockMode   string
+	lockOpts   []LockOption
+	preloads   []preloadSpec
+	unscoped   bool
+	trashed    bool
+	unions     []unionClause
+	ctes       []cteClause
+	dryRun     *dryRunRecorder
+	usePrimary bool
+	useReplica bool
+	nonZero    bool
+	cacheTTL   time.Duration
+
+	// upsert 设置
+	upsert          bool
+	conflictColumns []string
+	updateColumns   []string
 }
 
 // NewQueryBuilder 创建新的查询构建器
@@ -32,10 +86,21 @@ func NewQueryBuilder(orm *ORM, tableName string) QueryBuilder {
 
 // NewTransactionQueryBuilder 创建事务查询构建器
 func NewTransactionQueryBuilder(tx Tx, tableName string) QueryBuilder {
-	return &queryBuilder{
+	qb := &queryBuilder{
 		tx:        tx,
 		tableName: tableName,
 	}
+	if t, ok := tx.(*transaction); ok {
+		qb.orm = t.orm
+		qb.ctx = t.ctx
+	}
+	return qb
+}
+
+// WithContext 设置查询上下文
+func (qb *queryBuilder) WithContext(ctx context.Context) QueryBuilder {
+	qb.ctx = ctx
+	return qb
 }
 
 // Select 选择字段
@@ -44,14 +109,43 @@ func (qb *queryBuilder) Select(columns ...string) QueryBuilder {
 	return qb
 }
 
+// Distinct 查询去重后的结果，指定列时同时设置查询列，如 Distinct("city")
+// 设置后Count和聚合函数基于去重后的结果计算
+func (qb *queryBuilder) Distinct(columns ...string) QueryBuilder {
+	qb.distinct = true
+	if len(columns) > 0 {
+		qb.selectCols = columns
+		qb.selectArgs = nil
+	}
+	return qb
+}
+
+// SelectRaw 追加原始SELECT表达式，如 "COUNT(*) AS cnt, MAX(price) AS max_price"
+func (qb *queryBuilder) SelectRaw(expression string, args ...interface{}) QueryBuilder {
+	qb.selectCols = append(qb.selectCols, expression)
+	qb.selectArgs = append(qb.selectArgs, args...)
+	return qb
+}
+
 // From 设置表名
 func (qb *queryBuilder) From(table string) QueryBuilder {
 	qb.tableName = table
 	return qb
 }
 
-// Where 添加WHERE条件
+// FromSub 以子查询作为数据源（派生表），alias为派生表的别名
+// 如 FromSub(orm.Table("orders").Select("user_id", "SUM(amount) AS total").GroupBy("user_id"), "t")
+func (qb *queryBuilder) FromSub(sub QueryBuilder, alias string) QueryBuilder {
+	if builder, ok := sub.(*queryBuilder); ok {
+		qb.fromSub = builder
+		qb.tableName = alias
+	}
+	return qb
+}
+
+// Where 添加WHERE条件，参数为单个map时支持 :name 命名参数
 func (qb *queryBuilder) Where(condition string, args ...interface{}) QueryBuilder {
+	condition, args, _ = bindNamed(condition, args)
 	qb.conditions = append(qb.conditions, QueryCondition{
 		Column:   condition,
 		Operator: "=",
@@ -61,7 +155,89 @@ func (qb *queryBuilder) Where(condition string, args ...interface{}) QueryBuilde
 	return qb
 }
 
-// WhereIn 添加IN条件
+// OrWhere 添加OR连接的WHERE条件
+func (qb *queryBuilder) OrWhere(condition string, args ...interface{}) QueryBuilder {
+	condition, args, _ = bindNamed(condition, args)
+	qb.conditions = append(qb.conditions, QueryCondition{
+		Column:   condition,
+		Operator: "=",
+		Value:    args,
+		Logic:    "OR",
+	})
+	return qb
+}
+
+// WhereGroup 添加以AND连接的括号分组条件
+func (qb *queryBuilder) WhereGroup(fn func(qb QueryBuilder)) QueryBuilder {
+	return qb.whereGroup("AND", fn)
+}
+
+// OrWhereGroup 添加以OR连接的括号分组条件
+func (qb *queryBuilder) OrWhereGroup(fn func(qb QueryBuilder)) QueryBuilder {
+	return qb.whereGroup("OR", fn)
+}
+
+// whereGroup 收集回调中的条件作为一个分组
+func (qb *queryBuilder) whereGroup(logic string, fn func(qb QueryBuilder)) QueryBuilder {
+	group := &queryBuilder{orm: qb.orm, tableName: qb.tableName}
+	fn(group)
+
+	if len(group.conditions) > 0 {
+		qb.conditions = append(qb.conditions, QueryCondition{
+			Operator:   "GROUP",
+			Logic:      logic,
+			Conditions: group.conditions,
+		})
+	}
+	return qb
+}
+
+// WhereMap 将map中的键值对添加为等值条件，nil值生成IS NULL条件，切片值生成IN条件
+func (qb *queryBuilder) WhereMap(conditions map[string]interface{}) QueryBuilder {
+	columns := make([]string, 0, len(conditions))
+	for column := range conditions {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		qb.whereEqual(column, conditions[column])
+	}
+	return qb
+}
+
+// WhereStruct 将结构体中的非零值字段添加为等值条件
+func (qb *queryBuilder) WhereStruct(model interface{}) QueryBuilder {
+	columns, values := extractNonZeroColumnsAndValues(model)
+	for i, column := range columns {
+		qb.whereEqual(column, values[i])
+	}
+	return qb
+}
+
+// whereEqual 添加列等值条件，切片值（[]byte除外）按IN条件处理，避免被当作原始条件的参数
+func (qb *queryBuilder) whereEqual(column string, value interface{}) {
+	if value == nil {
+		qb.WhereNull(column)
+		return
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = v.Index(i).Interface()
+		}
+		qb.WhereIn(column, values...)
+		return
+	}
+	qb.conditions = append(qb.conditions, QueryCondition{
+		Column:   column,
+		Operator: "=",
+		Value:    value,
+		Logic:    "AND",
+	})
+}
+
+// WhereIn 添加IN条件，values 也可以是单个查询构建器，作为子查询嵌入
 func (qb *queryBuilder) WhereIn(column string, values ...interface{}) QueryBuilder {
 	qb.conditions = append(qb.conditions, QueryCondition{
 		Column:   column,
@@ -72,7 +248,7 @@ func (qb *queryBuilder) WhereIn(column string, values ...interface{}) QueryBuild
 	return qb
 }
 
-// WhereNotIn 添加NOT IN条件
+// WhereNotIn 添加NOT IN条件，values 也可以是单个查询构建器，作为子查询嵌入
 func (qb *queryBuilder) WhereNotIn(column string, values ...interface{}) QueryBuilder {
 	qb.conditions = append(qb.conditions, QueryCondition{
 		Column:   column,
@@ -83,6 +259,26 @@ func (qb *queryBuilder) WhereNotIn(column string, values ...interface{}) QueryBu
 	return qb
 }
 
+// WhereExists 添加EXISTS子查询条件
+func (qb *queryBuilder) WhereExists(sub QueryBuilder) QueryBuilder {
+	qb.conditions = append(qb.conditions, QueryCondition{
+		Operator: "EXISTS",
+		Value:    sub,
+		Logic:    "AND",
+	})
+	return qb
+}
+
+// WhereNotExists 添加NOT EXISTS子查询条件
+func (qb *queryBuilder) WhereNotExists(sub QueryBuilder) QueryBuilder {
+	qb.conditions = append(qb.conditions, QueryCondition{
+		Operator: "NOT EXISTS",
+		Value:    sub,
+		Logic:    "AND",
+	})
+	return qb
+}
+
 // WhereBetween 添加BETWEEN条件
 func (qb *queryBuilder) WhereBetween(column string, start, end interface{}) QueryBuilder {
 	qb.conditions = append(qb.conditions, QueryCondition{
@@ -114,26 +310,40 @@ func (qb *queryBuilder) WhereNotNull(column string) QueryBuilder {
 	return qb
 }
 
-// OrderBy 添加排序
+// OrderBy 添加排序，列名按方言加引号，方向只接受ASC和DESC，其余值按ASC处理
 func (qb *queryBuilder) OrderBy(column string, direction ...string) QueryBuilder {
-	dir := "ASC"
-	if len(direction) > 0 {
-		dir = strings.ToUpper(direction[0])
-	}
 	qb.orders = append(qb.orders, OrderClause{
 		Column:    column,
-		Direction: dir,
+		Direction: orderDirection(direction...),
+	})
+	return qb
+}
+
+// OrderByRaw 添加原样使用的排序表达式，如 OrderByRaw("FIELD(status, 'paid', 'pending')")
+// 表达式不做任何转义，不要拼接用户输入
+func (qb *queryBuilder) OrderByRaw(expression string) QueryBuilder {
+	qb.orders = append(qb.orders, OrderClause{
+		Column: expression,
+		Raw:    true,
 	})
 	return qb
 }
 
 // GroupBy 添加分组
 func (qb *queryBuilder) GroupBy(columns ...string) QueryBuilder {
-	qb.groups = append(qb.groups, columns...)
+	for _, column := range columns {
+		qb.groups = append(qb.groups, groupClause{expression: column})
+	}
 	return qb
 }
 
-// Having 添加HAVING条件
+// GroupByRaw 添加带参数的分组表达式，如 GroupByRaw("strftime(?, created_at)", "%Y-%m")
+func (qb *queryBuilder) GroupByRaw(expression string, args ...interface{}) QueryBuilder {
+	qb.groups = append(qb.groups, groupClause{expression: expression, args: args})
+	return qb
+}
+
+// Having 添加HAVING条件，多个条件以AND连接
 func (qb *queryBuilder) Having(condition string, args ...interface{}) QueryBuilder {
 	qb.havings = append(qb.havings, HavingClause{
 		Condition: condition,
@@ -142,6 +352,12 @@ func (qb *queryBuilder) Having(condition string, args ...interface{}) QueryBuild
 	return qb
 }
 
+// HavingRaw 添加原样使用的HAVING表达式，如 HavingRaw("SUM(amount) > ? OR COUNT(*) > ?", 100, 5)
+// 表达式整体加括号后与其他条件以AND连接
+func (qb *queryBuilder) HavingRaw(expression string, args ...interface{}) QueryBuilder {
+	return qb.Having("("+expression+")", args...)
+}
+
 // Limit 设置限制数量
 func (qb *queryBuilder) Limit(limit int) QueryBuilder {
 	qb.limitNum = limit
@@ -154,81 +370,193 @@ func (qb *queryBuilder) Offset(offset int) QueryBuilder {
 	return qb
 }
 
-// Join 添加JOIN
-func (qb *queryBuilder) Join(table, condition string) QueryBuilder {
-	qb.joins = append(qb.joins, JoinClause{
-		Type:      "INNER",
-		Table:     table,
-		Condition: condition,
-	})
-	return qb
+// Join 添加INNER JOIN，表可带别名，条件中的?按顺序绑定args
+// 如 Join("orders o", "o.user_id = users.id AND o.status = ?", "paid")
+func (qb *queryBuilder) Join(table, condition string, args ...interface{}) QueryBuilder {
+	return qb.join("INNER", table, condition, args)
 }
 
 // LeftJoin 添加LEFT JOIN
-func (qb *queryBuilder) LeftJoin(table, condition string) QueryBuilder {
-	qb.joins = append(qb.joins, JoinClause{
-		Type:      "LEFT",
-		Table:     table,
-		Condition: condition,
-	})
-	return qb
+func (qb *queryBuilder) LeftJoin(table, condition string, args ...interface{}) QueryBuilder {
+	return qb.join("LEFT", table, condition, args)
 }
 
 // RightJoin 添加RIGHT JOIN
-func (qb *queryBuilder) RightJoin(table, condition string) QueryBuilder {
-	qb.joins = append(qb.joins, JoinClause{
-		Type:      "RIGHT",
-		Table:     table,
-		Condition: condition,
-	})
-	return qb
+func (qb *queryBuilder) RightJoin(table, condition string, args ...interface{}) QueryBuilder {
+	return qb.join("RIGHT", table, condition, args)
 }
 
 // InnerJoin 添加INNER JOIN
-func (qb *queryBuilder) InnerJoin(table, condition string) QueryBuilder {
+func (qb *queryBuilder) InnerJoin(table, condition string, args ...interface{}) QueryBuilder {
+	return qb.join("INNER", table, condition, args)
+}
+
+// CrossJoin 添加CROSS JOIN
+func (qb *queryBuilder) CrossJoin(table string) QueryBuilder {
+	return qb.join("CROSS", table, "", nil)
+}
+
+// join 添加JOIN子句
+func (qb *queryBuilder) join(joinType, table, condition string, args []interface{}) QueryBuilder {
 	qb.joins = append(qb.joins, JoinClause{
-		Type:      "INNER",
+		Type:      joinType,
 		Table:     table,
 		Condition: condition,
+		Args:      args,
 	})
 	return qb
 }
 
-// Get 获取多条记录
-func (qb *queryBuilder) Get(dest interface{}) error {
-	query, args := qb.buildSelectSQL()
+// ForUpdate 对查询的行加排他锁（SELECT ... FOR UPDATE），需在事务中使用
+func (qb *queryBuilder) ForUpdate(options ...LockOption) QueryBuilder {
+	qb.lockMode = "UPDATE"
+	qb.lockOpts = options
+	return qb
+}
 
-	var rows *sql.Rows
-	var err error
+// ForShare 对查询的行加共享锁（SELECT ... FOR SHARE），需在事务中使用
+func (qb *queryBuilder) ForShare(options ...LockOption) QueryBuilder {
+	qb.lockMode = "SHARE"
+	qb.lockOpts = options
+	return qb
+}
 
-	if qb.tx != nil {
-		rows, err = qb.tx.Query(query, args...)
-	} else {
-		rows, err = qb.orm.Query(query, args...)
+// Union 使用UNION合并另一个查询的结果（去重），排序和分页作用于合并后的结果
+func (qb *queryBuilder) Union(other QueryBuilder) QueryBuilder {
+	return qb.union(other, false)
+}
+
+// UnionAll 使用UNION ALL合并另一个查询的结果（保留重复），排序和分页作用于合并后的结果
+func (qb *queryBuilder) UnionAll(other QueryBuilder) QueryBuilder {
+	return qb.union(other, true)
+}
+
+// union 添加UNION子句
+func (qb *queryBuilder) union(other QueryBuilder, all bool) QueryBuilder {
+	if query, ok := other.(*queryBuilder); ok {
+		qb.unions = append(qb.unions, unionClause{all: all, query: query})
 	}
+	return qb
+}
 
-	if err != nil {
-		return err
+// With 添加公用表表达式，name 可包含列名列表，如 "tree(id, parent_id)"
+func (qb *queryBuilder) With(name string, sub QueryBuilder) QueryBuilder {
+	return qb.with(name, sub, false)
+}
+
+// WithRecursive 添加递归公用表表达式，sub 通常为基础查询 UnionAll 引用自身的递归查询
+func (qb *queryBuilder) WithRecursive(name string, sub QueryBuilder) QueryBuilder {
+	return qb.with(name, sub, true)
+}
+
+// with 添加WITH子句
+func (qb *queryBuilder) with(name string, sub QueryBuilder, recursive bool) QueryBuilder {
+	if query, ok := sub.(*queryBuilder); ok {
+		qb.ctes = append(qb.ctes, cteClause{name: name, recursive: recursive, query: query})
+	}
+	return qb
+}
+
+// buildWithClause 构建WITH子句，SQL Server不使用RECURSIVE关键字
+func (qb *queryBuilder) buildWithClause() (string, []interface{}) {
+	if len(qb.ctes) == 0 {
+		return "", nil
+	}
+
+	var parts []string
+	var args []interface{}
+	recursive := false
+	for _, cte := range qb.ctes {
+		cteSQL, cteArgs := cte.query.buildSelectSQL()
+		parts = append(parts, fmt.Sprintf("%s AS (%s)", quoteTableRef(qb.dialect(), cte.name), cteSQL))
+		args = append(args, cteArgs...)
+		recursive = recursive || cte.recursive
 	}
-	defer rows.Close()
 
-	return scanRows(rows, dest)
+	keyword := "WITH "
+	switch qb.dialect().(type) {
+	case *SQLServerDialect, *OracleDialect:
+	default:
+		if recursive {
+			keyword = "WITH RECURSIVE "
+		}
+	}
+	return keyword + strings.Join(parts, ", "), args
 }
 
-// First 获取第一条记录
-func (qb *queryBuilder) First(dest interface{}) error {
-	qb.limitNum = 1
+// buildLockClause 构建行锁子句，SQLite和ClickHouse不支持行锁时返回空
+// SQL Server通过表提示加锁，见lockTableHint
+func (qb *queryBuilder) buildLockClause() string {
+	if qb.lockMode == "" {
+		return ""
+	}
+	switch qb.dialect().(type) {
+	case *SQLiteDialect, *ClickHouseDialect, *SQLServerDialect:
+		return ""
+	case *OracleDialect:
+		// Oracle没有共享行锁
+		if qb.lockMode == "SHARE" {
+			return ""
+		}
+	}
+
+	clause := "FOR " + qb.lockMode
+	for _, option := range qb.lockOpts {
+		clause += " " + string(option)
+	}
+	return clause
+}
+
+// lockTableHint 构建SQL Server的行锁表提示，紧跟在FROM的表名之后
+// FOR UPDATE对应UPDLOCK，FOR SHARE对应HOLDLOCK，NOWAIT对应NOWAIT，SKIP LOCKED对应READPAST
+func (qb *queryBuilder) lockTableHint() string {
+	if qb.lockMode == "" {
+		return ""
+	}
+	if _, ok := qb.dialect().(*SQLServerDialect); !ok {
+		return ""
+	}
+
+	hints := []string{"UPDLOCK", "ROWLOCK"}
+	if qb.lockMode == "SHARE" {
+		hints = []string{"HOLDLOCK", "ROWLOCK"}
+	}
+	for _, option := range qb.lockOpts {
+		switch option {
+		case NoWait:
+			hints = append(hints, "NOWAIT")
+		case SkipLocked:
+			hints = append(hints, "READPAST")
+		}
+	}
+	return " WITH (" + strings.Join(hints, ", ") + ")"
+}
+
+// Get 获取多条记录
+func (qb *queryBuilder) Get(dest interface{}) error {
 	query, args := qb.buildSelectSQL()
 
-	var row *sql.Row
+	err := qb.cachedQuery("get", dest, query, args, func() error {
+		rows, err := qb.query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
 
-	if qb.tx != nil {
-		row = qb.tx.QueryRow(query, args...)
-	} else {
-		row = qb.orm.QueryRow(query, args...)
+		if err := scanRows(rows, dest, qb.orm.location()); err != nil {
+			return err
+		}
+		return qb.preload(dest)
+	})
+	if err != nil {
+		return err
 	}
+	return qb.afterFind(dest)
+}
 
-	return scanRow(row, dest)
+// First 获取第一条记录到结构体，没有匹配的记录时返回ErrRecordNotFound
+func (qb *queryBuilder) First(dest interface{}) error {
+	return qb.findOne(dest)
 }
 
 // Find 查找记录（别名）
@@ -237,220 +565,897 @@ func (qb *queryBuilder) Find(dest interface{}) error {
 }
 
 // Count 统计记录数
-func (qb *queryBuilder) Count() (int64, error) {
-	query, args := qb.buildCountSQL()
+// 可指定统计的列，如 Count("email") 不计NULL值，Count("distinct user_id") 统计去重后的数量；
+// 按列统计推荐使用CountColumn和CountDistinct
+func (qb *queryBuilder) Count(column ...string) (int64, error) {
+	return qb.count(countExpression(qb.dialect(), column...))
+}
 
-	var row *sql.Row
+// CountColumn 统计指定列非NULL值的数量，生成 COUNT(column)
+func (qb *queryBuilder) CountColumn(column string) (int64, error) {
+	return qb.count(countColumnExpression(qb.dialect(), column))
+}
 
-	if qb.tx != nil {
-		row = qb.tx.QueryRow(query, args...)
-	} else {
-		row = qb.orm.QueryRow(query, args...)
-	}
+// CountDistinct 统计指定列去重后的非NULL值数量，生成 COUNT(DISTINCT column)
+func (qb *queryBuilder) CountDistinct(column string) (int64, error) {
+	return qb.count(countDistinctExpression(qb.dialect(), column))
+}
+
+// count 执行COUNT聚合查询
+func (qb *queryBuilder) count(expression string) (int64, error) {
+	query, args := qb.buildAggregateSQL(expression)
 
 	var count int64
-	err := row.Scan(&count)
+	err := qb.cachedQuery("count", &count, query, args, func() error {
+		return qb.queryRow(query, args...).Scan(&count)
+	})
 	return count, err
 }
 
-// Exists 检查记录是否存在
+// Exists 检查记录是否存在，使用EXISTS子查询，匹配到第一条记录即返回
 func (qb *queryBuilder) Exists() (bool, error) {
-	count, err := qb.Count()
-	return count > 0, err
+	query, args := qb.buildExistsSQL()
+
+	var exists bool
+	err := qb.queryRow(query, args...).Scan(&exists)
+	return exists, err
 }
 
-// Insert 插入记录
-func (qb *queryBuilder) Insert(data interface{}) error {
-	query, args := qb.buildInsertSQL(data)
+// Sum 计算列的总和，无匹配记录时返回0
+func (qb *queryBuilder) Sum(column string) (float64, error) {
+	return qb.aggregate("SUM", column)
+}
 
-	if qb.tx != nil {
-		_, err := qb.tx.Exec(query, args...)
-		return err
-	} else {
-		_, err := qb.orm.Exec(query, args...)
-		return err
-	}
+// Avg 计算列的平均值，无匹配记录时返回0
+func (qb *queryBuilder) Avg(column string) (float64, error) {
+	return qb.aggregate("AVG", column)
 }
 
-// InsertBatch 批量插入记录
-func (qb *queryBuilder) InsertBatch(data interface{}) error {
-	query, args := qb.buildBatchInsertSQL(data)
+// Min 计算列的最小值，无匹配记录时返回0
+func (qb *queryBuilder) Min(column string) (float64, error) {
+	return qb.aggregate("MIN", column)
+}
 
-	if qb.tx != nil {
-		_, err := qb.tx.Exec(query, args...)
-		return err
-	} else {
-		_, err := qb.orm.Exec(query, args...)
-		return err
-	}
+// Max 计算列的最大值，无匹配记录时返回0
+func (qb *queryBuilder) Max(column string) (float64, error) {
+	return qb.aggregate("MAX", column)
 }
 
-// Update 更新记录
-func (qb *queryBuilder) Update(data interface{}) error {
-	query, args := qb.buildUpdateSQL(data)
+// aggregate 执行聚合函数查询
+func (qb *queryBuilder) aggregate(function, column string) (float64, error) {
+	query, args := qb.buildAggregateSQL(fmt.Sprintf("%s(%s)", function, quoteColumn(qb.dialect(), column)))
 
-	if qb.tx != nil {
-		_, err := qb.tx.Exec(query, args...)
-		return err
-	} else {
-		_, err := qb.orm.Exec(query, args...)
-		return err
+	var result sql.NullFloat64
+	if err := qb.queryRow(query, args...).Scan(&result); err != nil {
+		return 0, err
 	}
+	return result.Float64, nil
 }
 
-// UpdateColumns 更新指定列
-func (qb *queryBuilder) UpdateColumns(columns map[string]interface{}) error {
-	query, args := qb.buildUpdateColumnsSQL(columns)
-
-	if qb.tx != nil {
-		_, err := qb.tx.Exec(query, args...)
-		return err
-	} else {
-		_, err := qb.orm.Exec(query, args...)
-		return err
+// Pluck 查询单列的值到切片中，如 Pluck("email", &emails)
+func (qb *queryBuilder) Pluck(column string, dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest必须是切片指针")
 	}
-}
+	sliceValue := destValue.Elem()
+	elemType := sliceValue.Type().Elem()
 
-// Delete 删除记录
-func (qb *queryBuilder) Delete() error {
-	query, args := qb.buildDeleteSQL()
+	pluck := *qb
+	pluck.selectCols = []string{column}
+	pluck.selectArgs = nil
+	query, args := pluck.buildSelectSQL()
 
-	if qb.tx != nil {
-		_, err := qb.tx.Exec(query, args...)
-		return err
-	} else {
-		_, err := qb.orm.Exec(query, args...)
+	rows, err := qb.query(query, args...)
+	if err != nil {
 		return err
 	}
-}
+	defer rows.Close()
 
-// ToSQL 构建SQL语句
-func (qb *queryBuilder) ToSQL() (string, []interface{}) {
-	return qb.buildSelectSQL()
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := rows.Scan(elem.Interface()); err != nil {
+			return err
+		}
+		sliceValue.Set(reflect.Append(sliceValue, elem.Elem()))
+	}
+
+	return rows.Err()
 }
 
-// buildSelectSQL 构建SELECT SQL
-func (qb *queryBuilder) buildSelectSQL() (string, []interface{}) {
-	var parts []string
-	var args []interface{}
+// GetMaps 查询多条记录到map切片，键为列名，用于没有对应结构体的报表等临时查询
+func (qb *queryBuilder) GetMaps() ([]map[string]interface{}, error) {
+	query, args := qb.buildSelectSQL()
 
-	// SELECT子句
-	if len(qb.selectCols) > 0 {
-		parts = append(parts, "SELECT "+strings.Join(qb.selectCols, ", "))
-	} else {
-		parts = append(parts, "SELECT *")
+	rows, err := qb.query(query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	// FROM子句
-	parts = append(parts, "FROM "+qb.tableName)
+	return scanMaps(rows, 0, qb.orm.location())
+}
 
-	// JOIN子句
-	for _, join := range qb.joins {
-		parts = append(parts, fmt.Sprintf("%s JOIN %s ON %s", join.Type, join.Table, join.Condition))
-	}
+// FirstMap 查询第一条记录到map，没有匹配的记录时返回ErrRecordNotFound
+func (qb *queryBuilder) FirstMap() (map[string]interface{}, error) {
+	first := *qb
+	first.limitNum = 1
+	query, args := first.buildSelectSQL()
 
-	// WHERE子句
-	if len(qb.conditions) > 0 {
-		whereClause, whereArgs := qb.buildWhereClause()
-		parts = append(parts, "WHERE "+whereClause)
-		args = append(args, whereArgs...)
+	rows, err := qb.query(query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	// GROUP BY子句
-	if len(qb.groups) > 0 {
-		parts = append(parts, "GROUP BY "+strings.Join(qb.groups, ", "))
+	results, err := scanMaps(rows, 1, qb.orm.location())
+	if err != nil {
+		return nil, err
 	}
+	if len(results) == 0 {
+		return nil, errRecordNotFound
+	}
+	return results[0], nil
+}
 
-	// HAVING子句
-	if len(qb.havings) > 0 {
-		havingClause, havingArgs := qb.buildHavingClause()
-		parts = append(parts, "HAVING "+havingClause)
-		args = append(args, havingArgs...)
+// Paginate 分页查询，统计总数并查询指定页的数据
+func (qb *queryBuilder) Paginate(page, perPage int, dest interface{}) (*Pagination, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
 	}
 
-	// ORDER BY子句
-	if len(qb.orders) > 0 {
-		var orderParts []string
-		for _, order := range qb.orders {
-			orderParts = append(orderParts, order.Column+" "+order.Direction)
-		}
-		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
+	total, err := qb.Count()
+	if err != nil {
+		return nil, err
 	}
 
-	// LIMIT子句
-	if qb.limitNum > 0 {
-		parts = append(parts, fmt.Sprintf("LIMIT %d", qb.limitNum))
+	lastPage := int((total + int64(perPage) - 1) / int64(perPage))
+	if lastPage < 1 {
+		lastPage = 1
 	}
 
-	// OFFSET子句
-	if qb.offsetNum > 0 {
-		parts = append(parts, fmt.Sprintf("OFFSET %d", qb.offsetNum))
+	pageQuery := *qb
+	pageQuery.limitNum = perPage
+	pageQuery.offsetNum = (page - 1) * perPage
+	if err := pageQuery.Get(dest); err != nil {
+		return nil, err
 	}
 
-	return strings.Join(parts, " "), args
+	return &Pagination{
+		Total:    total,
+		Page:     page,
+		PerPage:  perPage,
+		LastPage: lastPage,
+	}, nil
 }
 
-// buildCountSQL 构建COUNT SQL
-func (qb *queryBuilder) buildCountSQL() (string, []interface{}) {
-	var parts []string
-	var args []interface{}
+// Insert 插入记录
+func (qb *queryBuilder) Insert(data interface{}) error {
+	_, err := qb.InsertAffected(data)
+	return err
+}
+
+// InsertAffected 插入记录并返回受影响的行数
+// upsert时的行数与数据库相关，如MySQL更新已有记录时返回2，数据未变化时返回0
+func (qb *queryBuilder) InsertAffected(data interface{}) (int64, error) {
+	if qb.upsert {
+		if err := qb.validateUpsert(data); err != nil {
+			return 0, err
+		}
+	}
+	if err := qb.beforeInsert(data); err != nil {
+		return 0, err
+	}
+	data = qb.stampTimestamps(data, false)
+	if err := fillUUIDs(data); err != nil {
+		return 0, err
+	}
+	query, args := qb.buildInsertSQL(data)
+	affected, err := qb.execInsert(sqlStatement{query: query, args: args, records: insertRecords(data)})
+	if err != nil {
+		return affected, err
+	}
+	return affected, qb.afterInsert(data)
+}
+
+// InsertBatch 批量插入记录，并回填零值的自增主键
+// 数据量超过批次大小时分块执行，不在事务中时自动开启事务保证整体写入
+func (qb *queryBuilder) InsertBatch(data interface{}) error {
+	if qb.upsert {
+		if err := qb.validateUpsert(data); err != nil {
+			return err
+		}
+	}
+
+	if err := qb.beforeInsert(data); err != nil {
+		return err
+	}
+	data = qb.stampTimestamps(data, false)
+	if err := fillUUIDs(data); err != nil {
+		return err
+	}
+	statements := qb.buildBatchInsertStatements(data)
+	if len(statements) <= 1 || qb.tx != nil || qb.dryRun != nil {
+		if err := qb.execStatements(statements); err != nil {
+			return err
+		}
+		return qb.afterInsert(data)
+	}
+
+	tx, err := qb.orm.BeginTx(qb.context(), nil)
+	if err != nil {
+		return err
+	}
+
+	txQuery := *qb
+	txQuery.tx = tx
+	if err := txQuery.execStatements(statements); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return qb.afterInsert(data)
+}
+
+// BatchSize 设置批量插入的分块大小
+func (qb *queryBuilder) BatchSize(size int) QueryBuilder {
+	qb.batchSize = size
+	return qb
+}
+
+// execStatements 依次执行多条插入语句
+func (qb *queryBuilder) execStatements(statements []sqlStatement) error {
+	for _, statement := range statements {
+		if _, err := qb.execInsert(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update 更新记录
+func (qb *queryBuilder) Update(data interface{}) error {
+	_, err := qb.UpdateAffected(data)
+	return err
+}
+
+// UpdateAffected 更新记录并返回受影响的行数
+func (qb *queryBuilder) UpdateAffected(data interface{}) (int64, error) {
+	if err := qb.beforeUpdate(data); err != nil {
+		return 0, err
+	}
+	qb = qb.byPrimaryKey(data)
+	data = qb.stampTimestamps(data, true)
+
+	var affected int64
+	var err error
+	if column, field, ok := versionField(data); ok {
+		affected, err = qb.updateWithVersion(data, column, field)
+	} else {
+		query, args := qb.buildUpdateSQL(data)
+		affected, err = qb.execAffected(query, args...)
+	}
+	if err != nil {
+		return affected, err
+	}
+	return affected, qb.afterUpdate(data)
+}
+
+// UpdateNonZero 按结构体更新记录，只更新非零值字段，用于部分更新
+// 零值、空字符串和nil指针字段不会出现在SET子句中，需要置零时使用UpdateColumns
+func (qb *queryBuilder) UpdateNonZero(data interface{}) error {
+	x := *qb
+	x.nonZero = true
+	if columns, _ := x.updateColumnsAndValues(data); len(columns) == 0 {
+		return fmt.Errorf("没有需要更新的非零值字段")
+	}
+	_, err := x.UpdateAffected(data)
+	return err
+}
+
+// updateColumnsAndValues 获取UPDATE语句的列和值，UpdateNonZero时跳过零值字段和主键
+func (qb *queryBuilder) updateColumnsAndValues(data interface{}) ([]string, []interface{}) {
+	if !qb.nonZero {
+		return qb.extractColumnsAndValues(data)
+	}
+
+	primary := make(map[string]bool)
+	for _, column := range primaryKeyColumns(data) {
+		primary[column] = true
+	}
+	columns, values := extractNonZeroColumnsAndValues(data)
+	n := 0
+	for i, column := range columns {
+		if !primary[column] {
+			columns[n], values[n] = column, values[i]
+			n++
+		}
+	}
+	return columns[:n], values[:n]
+}
+
+// updateWithVersion 基于版本号的乐观锁更新
+// 在条件中追加当前版本号并将其加一，没有记录被更新时返回ErrStaleObject
+func (qb *queryBuilder) updateWithVersion(data interface{}, versionColumn string, versionValue reflect.Value) (int64, error) {
+	query, args, next := qb.buildVersionedUpdateSQL(data, versionColumn, versionValue)
+	affected, err := qb.execAffected(query, args...)
+	if err != nil || qb.dryRun != nil {
+		return affected, err
+	}
+	if affected == 0 {
+		return 0, ErrStaleObject
+	}
+
+	if versionValue.CanSet() {
+		versionValue.Set(reflect.ValueOf(next).Convert(versionValue.Type()))
+	}
+	return affected, nil
+}
+
+// buildVersionedUpdateSQL 构建带版本号条件的UPDATE语句，返回语句、参数和新版本号
+func (qb *queryBuilder) buildVersionedUpdateSQL(data interface{}, versionColumn string, versionValue reflect.Value) (string, []interface{}, int64) {
+	current, next := versionValues(versionValue)
+
+	columns, values := qb.updateColumnsAndValues(data)
+	found := false
+	for i, col := range columns {
+		if col == versionColumn {
+			values[i] = next
+			found = true
+		}
+	}
+	if !found {
+		columns = append(columns, versionColumn)
+		values = append(values, next)
+	}
+
+	versioned := *qb
+	versioned.conditions = append(append([]QueryCondition{}, qb.conditions...), QueryCondition{
+		Column:   versionColumn,
+		Operator: "=",
+		Value:    current,
+		Logic:    "AND",
+	})
+
+	query, args := versioned.buildSetSQL(columns, values)
+	return query, args, next
+}
+
+// UpdateColumns 更新指定列
+func (qb *queryBuilder) UpdateColumns(columns map[string]interface{}) error {
+	_, err := qb.UpdateColumnsAffected(columns)
+	return err
+}
+
+// UpdateColumnsAffected 更新指定列并返回受影响的行数
+func (qb *queryBuilder) UpdateColumnsAffected(columns map[string]interface{}) (int64, error) {
+	qb = qb.byPrimaryKey(qb.model)
+	columns = qb.stampUpdatedAtColumn(columns)
+	query, args := qb.buildUpdateColumnsSQL(columns)
+	return qb.execAffected(query, args...)
+}
+
+// Delete 删除记录
+func (qb *queryBuilder) Delete() error {
+	_, err := qb.DeleteAffected()
+	return err
+}
+
+// DeleteAffected 删除记录并返回受影响的行数
+// 通过Model(&record)删除带主键值的记录时调用模型的删除钩子
+func (qb *queryBuilder) DeleteAffected() (int64, error) {
+	var record interface{}
+	if _, _, ok := primaryKeyValues(qb.model); ok {
+		record = qb.model
+	}
+	if err := qb.beforeDelete(record); err != nil {
+		return 0, err
+	}
+
+	qb = qb.byPrimaryKey(qb.model)
+	query, args := qb.buildDeleteSQL()
+	affected, err := qb.execAffected(query, args...)
+	if err != nil {
+		return affected, err
+	}
+	return affected, qb.afterDelete(record)
+}
+
+// byPrimaryKey 未设置任何条件时，按data的主键值限定更新或删除的记录
+// 支持复合主键；data没有主键或主键为零值时原样返回
+func (qb *queryBuilder) byPrimaryKey(data interface{}) *queryBuilder {
+	if len(qb.conditions) > 0 {
+		return qb
+	}
+	columns, values, ok := primaryKeyValues(data)
+	if !ok {
+		return qb
+	}
+
+	x := *qb
+	x.conditions = nil
+	for i, column := range columns {
+		x.whereEqual(column, values[i])
+	}
+	return &x
+}
+
+// DeleteByIDs 按主键批量删除记录并返回受影响的行数，主键列取自模型的primary标签，默认为id
+// ID数量超过批次大小时分块执行，不在事务中时自动开启事务保证整体删除
+func (qb *queryBuilder) DeleteByIDs(ids ...interface{}) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	column := "id"
+	if columns := primaryKeyColumns(qb.model); len(columns) > 1 {
+		return 0, fmt.Errorf("复合主键不支持DeleteByIDs，请使用条件删除")
+	} else if len(columns) == 1 {
+		column = columns[0]
+	}
+
+	batchSize := qb.getBatchSize()
+	if len(ids) <= batchSize || qb.tx != nil || qb.dryRun != nil {
+		return qb.deleteIDChunks(column, ids, batchSize)
+	}
+
+	tx, err := qb.orm.BeginTx(qb.context(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	txQuery := *qb
+	txQuery.tx = tx
+	affected, err := txQuery.deleteIDChunks(column, ids, batchSize)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return affected, tx.Commit()
+}
+
+// deleteIDChunks 按批次大小分块执行IN条件删除，已有条件与每个分块以AND组合
+func (qb *queryBuilder) deleteIDChunks(column string, ids []interface{}, batchSize int) (int64, error) {
+	var total int64
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		chunk := *qb
+		chunk.conditions = append([]QueryCondition{}, qb.conditions...)
+		chunk.WhereIn(column, ids[start:end]...)
+
+		query, args := chunk.buildDeleteSQL()
+		affected, err := chunk.execAffected(query, args...)
+		if err != nil {
+			return total, err
+		}
+		total += affected
+	}
+	return total, nil
+}
+
+// context 获取查询上下文
+func (qb *queryBuilder) context() context.Context {
+	if qb.ctx != nil {
+		return qb.ctx
+	}
+	return context.Background()
+}
+
+// dialect 获取当前数据库方言
+func (qb *queryBuilder) dialect() Dialect {
+	if qb.orm == nil {
+		return &MySQLDialect{}
+	}
+	return NewDatabaseManager(qb.orm).GetDialect()
+}
+
+// query 在事务或连接上执行查询
+func (qb *queryBuilder) query(query string, args ...interface{}) (*sql.Rows, error) {
+	query = rebind(qb.dialect(), query)
+	if qb.readsFromReplica() {
+		return qb.orm.readQueryContext(qb.context(), query, args...)
+	}
+	if qb.tx != nil {
+		return qb.tx.QueryContext(qb.context(), query, args...)
+	}
+	return qb.orm.QueryContext(qb.context(), query, args...)
+}
+
+// queryRow 在事务或连接上执行单行查询
+func (qb *queryBuilder) queryRow(query string, args ...interface{}) *sql.Row {
+	query = rebind(qb.dialect(), query)
+	if qb.readsFromReplica() {
+		return qb.orm.readQueryRowContext(qb.context(), query, args...)
+	}
+	if qb.tx != nil {
+		return qb.tx.QueryRowContext(qb.context(), query, args...)
+	}
+	return qb.orm.QueryRowContext(qb.context(), query, args...)
+}
+
+// queryPrimary 在事务或主库上执行返回结果集的写语句，如带RETURNING的INSERT
+func (qb *queryBuilder) queryPrimary(query string, args ...interface{}) (*sql.Rows, error) {
+	query = rebind(qb.dialect(), query)
+	var rows *sql.Rows
+	var err error
+	if qb.tx != nil {
+		rows, err = qb.tx.QueryContext(qb.context(), query, args...)
+	} else {
+		rows, err = qb.orm.QueryContext(qb.context(), query, args...)
+	}
+	if err == nil {
+		qb.invalidateCache()
+	}
+	return rows, err
+}
+
+// stampTimestamps 为结构体或结构体切片填充时间戳
+// 非指针结构体会被复制后再填充，以免修改调用方的值
+func (qb *queryBuilder) stampTimestamps(data interface{}, isUpdate bool) interface{} {
+	mm := NewModelManager(qb.orm)
+
+	v := reflect.ValueOf(data)
+	switch {
+	case v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct:
+		mm.SetTimestamps(data, isUpdate)
+	case v.Kind() == reflect.Struct:
+		copied := reflect.New(v.Type())
+		copied.Elem().Set(v)
+		mm.SetTimestamps(copied.Interface(), isUpdate)
+		return copied.Interface()
+	case v.Kind() == reflect.Slice || (v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice):
+		v = reflect.Indirect(v)
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if item.Kind() == reflect.Struct {
+				item = item.Addr()
+			}
+			if !item.IsNil() {
+				mm.SetTimestamps(item.Interface(), isUpdate)
+			}
+		}
+	}
+	return data
+}
+
+// stampUpdatedAtColumn 模型包含更新时间列且未显式设置时，为更新的列追加该列
+func (qb *queryBuilder) stampUpdatedAtColumn(columns map[string]interface{}) map[string]interface{} {
+	if qb.model == nil {
+		return columns
+	}
+
+	_, updatedAtColumn := NewModelManager(qb.orm).timestampColumns()
+	if _, ok := columns[updatedAtColumn]; ok {
+		return columns
+	}
+
+	modelValue := reflect.Indirect(reflect.ValueOf(qb.model))
+	if modelValue.Kind() != reflect.Struct || !findFieldByColumn(modelValue, updatedAtColumn).IsValid() {
+		return columns
+	}
+
+	stamped := make(map[string]interface{}, len(columns)+1)
+	for col, val := range columns {
+		stamped[col] = val
+	}
+	stamped[updatedAtColumn] = time.Now()
+	return stamped
+}
+
+// exec 在事务或连接上执行SQL语句，违反唯一约束和外键约束的错误转换为对应的哨兵错误
+func (qb *queryBuilder) exec(query string, args ...interface{}) (sql.Result, error) {
+	query = rebind(qb.dialect(), query)
+	if qb.dryRun != nil {
+		qb.dryRun.record(query, args)
+		return dryRunResult{}, nil
+	}
 
-	parts = append(parts, "SELECT COUNT(*)")
-	parts = append(parts, "FROM "+qb.tableName)
+	var result sql.Result
+	var err error
+	if qb.tx != nil {
+		result, err = qb.tx.ExecContext(qb.context(), query, args...)
+	} else {
+		result, err = qb.orm.ExecContext(qb.context(), query, args...)
+	}
+	if err == nil {
+		qb.invalidateCache()
+	}
+	return result, translateError(err)
+}
+
+// execAffected 执行SQL语句并返回受影响的行数
+func (qb *queryBuilder) execAffected(query string, args ...interface{}) (int64, error) {
+	result, err := qb.exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ToSQL 构建SQL语句
+func (qb *queryBuilder) ToSQL() (string, []interface{}) {
+	query, args := qb.buildSelectSQL()
+	return rebind(qb.dialect(), query), args
+}
+
+// buildSelectSQL 构建SELECT SQL
+func (qb *queryBuilder) buildSelectSQL() (string, []interface{}) {
+	if scoped := qb.scoped(); scoped != qb {
+		return scoped.buildSelectSQL()
+	}
+
+	var c sqlClauses
+	c.add(qb.buildWithClause())
+	c.add(qb.buildSelectCore())
+
+	// ORDER BY子句
+	if len(qb.orders) > 0 {
+		var orderParts []string
+		for _, order := range qb.orders {
+			if order.Raw {
+				orderParts = append(orderParts, order.Column)
+			} else {
+				orderParts = append(orderParts, quoteIdentifier(qb.dialect(), order.Column)+" "+orderDirection(order.Direction))
+			}
+		}
+		c.add("ORDER BY "+strings.Join(orderParts, ", "), nil)
+	} else if _, ok := qb.dialect().(*SQLServerDialect); ok && (qb.limitNum > 0 || qb.offsetNum > 0) {
+		// SQL Server的OFFSET/FETCH必须跟在ORDER BY之后，未指定排序时不保证返回顺序
+		c.add("ORDER BY (SELECT NULL)", nil)
+	}
+
+	// LIMIT/OFFSET为整数，按方言语法直接写入SQL，不占用参数位置
+	c.add(qb.dialect().LimitOffsetSQL(qb.limitNum, qb.offsetNum), nil)
+
+	// 行锁子句
+	c.add(qb.buildLockClause(), nil)
+
+	return c.build()
+}
+
+// buildSelectCore 构建不含排序、分页和行锁的SELECT SQL，包含UNION子句
+func (qb *queryBuilder) buildSelectCore() (string, []interface{}) {
+	if scoped := qb.scoped(); scoped != qb {
+		return scoped.buildSelectCore()
+	}
+
+	var c sqlClauses
+
+	// SELECT子句，列名加引号，表达式原样使用
+	selectKeyword := "SELECT "
+	if qb.distinct {
+		selectKeyword = "SELECT DISTINCT "
+	}
+	if len(qb.selectCols) > 0 {
+		columns := make([]string, len(qb.selectCols))
+		for i, column := range qb.selectCols {
+			columns[i] = quoteColumn(qb.dialect(), column)
+		}
+		c.add(selectKeyword+strings.Join(columns, ", "), qb.selectArgs)
+	} else {
+		c.add(selectKeyword+"*", nil)
+	}
+
+	qb.addSourceClauses(&c)
+
+	// GROUP BY子句
+	c.add(qb.buildGroupByClause())
+
+	// HAVING子句
+	if len(qb.havings) > 0 {
+		havingClause, havingArgs := qb.buildHavingClause()
+		c.add("HAVING "+havingClause, havingArgs)
+	}
+
+	// UNION子句
+	for _, union := range qb.unions {
+		if union.all {
+			c.add("UNION ALL", nil)
+		} else {
+			c.add("UNION", nil)
+		}
+		c.add(union.query.buildSelectCore())
+	}
+
+	return c.build()
+}
+
+// addSourceClauses 追加FROM、JOIN和WHERE子句
+func (qb *queryBuilder) addSourceClauses(c *sqlClauses) {
+	if qb.fromSub != nil {
+		subSQL, subArgs := qb.fromSub.buildSelectSQL()
+		c.add(fmt.Sprintf("FROM (%s)%s%s", subSQL, tableAliasKeyword(qb.dialect()), quoteIdentifier(qb.dialect(), qb.tableName)), subArgs)
+	} else {
+		c.add("FROM "+quoteTableRef(qb.dialect(), qb.tableName)+qb.lockTableHint(), nil)
+	}
 
-	// JOIN子句
 	for _, join := range qb.joins {
-		parts = append(parts, fmt.Sprintf("%s JOIN %s ON %s", join.Type, join.Table, join.Condition))
+		c.add(join.build(qb.dialect()), join.Args)
 	}
 
-	// WHERE子句
 	if len(qb.conditions) > 0 {
 		whereClause, whereArgs := qb.buildWhereClause()
-		parts = append(parts, "WHERE "+whereClause)
-		args = append(args, whereArgs...)
+		c.add("WHERE "+whereClause, whereArgs)
 	}
+}
 
-	return strings.Join(parts, " "), args
+// buildCountSQL 构建COUNT SQL
+func (qb *queryBuilder) buildCountSQL() (string, []interface{}) {
+	return qb.buildAggregateSQL("COUNT(*)")
+}
+
+// countExpression 构建COUNT表达式，未指定列时为COUNT(*)，"distinct 列"生成COUNT(DISTINCT 列)
+func countExpression(d Dialect, column ...string) string {
+	if len(column) == 0 || strings.TrimSpace(column[0]) == "" {
+		return "COUNT(*)"
+	}
+
+	expression := strings.TrimSpace(column[0])
+	if fields := strings.Fields(expression); len(fields) > 1 && strings.EqualFold(fields[0], "distinct") {
+		return countDistinctExpression(d, strings.Join(fields[1:], " "))
+	}
+	return countColumnExpression(d, expression)
+}
+
+// countColumnExpression 构建 COUNT(列) 表达式
+func countColumnExpression(d Dialect, column string) string {
+	return "COUNT(" + quoteColumn(d, column) + ")"
+}
+
+// countDistinctExpression 构建 COUNT(DISTINCT 列) 表达式
+func countDistinctExpression(d Dialect, column string) string {
+	return "COUNT(DISTINCT " + quoteColumn(d, column) + ")"
+}
+
+// buildExistsSQL 构建EXISTS查询SQL，子查询只取一行且不排序
+// SQL Server和Oracle不支持直接选择EXISTS表达式，使用CASE WHEN转换为0/1
+func (qb *queryBuilder) buildExistsSQL() (string, []interface{}) {
+	withClause, args := qb.buildWithClause()
+
+	inner := *qb
+	inner.ctes = nil
+	inner.orders = nil
+	inner.lockMode = ""
+	inner.lockOpts = nil
+	if len(inner.unions) == 0 {
+		inner.selectCols = []string{"1"}
+		inner.selectArgs = nil
+	}
+
+	_, sqlServer := qb.dialect().(*SQLServerDialect)
+	if sqlServer {
+		inner.limitNum, inner.offsetNum = 0, 0
+	} else {
+		inner.limitNum = 1
+	}
+	innerSQL, innerArgs := inner.buildSelectSQL()
+	args = append(args, innerArgs...)
+
+	query := fmt.Sprintf("SELECT EXISTS (%s)", innerSQL)
+	if sqlServer {
+		query = fmt.Sprintf("SELECT CASE WHEN EXISTS (%s) THEN 1 ELSE 0 END", innerSQL)
+	} else if _, oracle := qb.dialect().(*OracleDialect); oracle {
+		query = fmt.Sprintf("SELECT CASE WHEN EXISTS (%s) THEN 1 ELSE 0 END FROM dual", innerSQL)
+	}
+	if withClause != "" {
+		query = withClause + " " + query
+	}
+	return query, args
+}
+
+// buildAggregateSQL 构建聚合查询SQL
+// 包含合并查询、分组或HAVING条件时，基于子查询的结果聚合，如分组后的Count返回分组数
+func (qb *queryBuilder) buildAggregateSQL(expression string) (string, []interface{}) {
+	if scoped := qb.scoped(); scoped != qb {
+		return scoped.buildAggregateSQL(expression)
+	}
+
+	var c sqlClauses
+	c.add(qb.buildWithClause())
+
+	if qb.distinct || len(qb.unions) > 0 || len(qb.groups) > 0 || len(qb.havings) > 0 {
+		query, coreArgs := qb.buildSelectCore()
+		c.add(fmt.Sprintf("SELECT %s FROM (%s)%saggregate_result", expression, query, tableAliasKeyword(qb.dialect())), coreArgs)
+		return c.build()
+	}
+
+	c.add("SELECT "+expression, nil)
+	qb.addSourceClauses(&c)
+	return c.build()
 }
 
 // buildInsertSQL 构建INSERT SQL
 func (qb *queryBuilder) buildInsertSQL(data interface{}) (string, []interface{}) {
 	columns, values := qb.extractColumnsAndValues(data)
 
+	if qb.upsert {
+		return qb.buildUpsertSQL(data, columns, 1), values
+	}
+
 	placeholders := make([]string, len(values))
 	for i := range placeholders {
 		placeholders[i] = "?"
 	}
 
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-		qb.tableName,
-		strings.Join(columns, ", "),
+		quoteTableRef(qb.dialect(), qb.tableName),
+		quoteIdentifiers(qb.dialect(), columns),
 		strings.Join(placeholders, ", "))
 
 	return query, values
 }
 
-// buildBatchInsertSQL 构建批量INSERT SQL
-func (qb *queryBuilder) buildBatchInsertSQL(data interface{}) (string, []interface{}) {
+// buildBatchInsertStatements 构建批量INSERT语句
+// 数据按批次大小分块，块内列集合相同的连续行合并为一条语句
+func (qb *queryBuilder) buildBatchInsertStatements(data interface{}) []sqlStatement {
 	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
 	if v.Kind() != reflect.Slice {
-		return qb.buildInsertSQL(data)
+		query, args := qb.buildInsertSQL(data)
+		return []sqlStatement{{query: query, args: args, records: insertRecords(data)}}
 	}
 
-	if v.Len() == 0 {
-		return "", nil
+	batchSize := qb.getBatchSize()
+
+	var statements []sqlStatement
+	for start := 0; start < v.Len(); start += batchSize {
+		end := start + batchSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+
+		var columns []string
+		var rows [][]interface{}
+		var records []reflect.Value
+		var first interface{}
+
+		flush := func() {
+			if len(rows) > 0 {
+				query, args := qb.buildBatchInsertSQL(first, columns, rows)
+				statements = append(statements, sqlStatement{query: query, args: args, records: records})
+			}
+			rows = nil
+			records = nil
+		}
+
+		for i := start; i < end; i++ {
+			item := v.Index(i).Interface()
+			itemColumns, values := qb.extractColumnsAndValues(item)
+			if len(rows) > 0 && !sameColumns(columns, itemColumns) {
+				flush()
+			}
+			if len(rows) == 0 {
+				columns = itemColumns
+				first = item
+			}
+			rows = append(rows, values)
+			if record := reflect.Indirect(v.Index(i)); record.Kind() == reflect.Struct && record.CanAddr() {
+				records = append(records, record)
+			}
+		}
+		flush()
 	}
 
-	// 获取第一个元素的列名
-	firstItem := v.Index(0).Interface()
-	columns, _ := qb.extractColumnsAndValues(firstItem)
+	return statements
+}
 
+// buildBatchInsertSQL 构建列集合相同的多行INSERT SQL
+func (qb *queryBuilder) buildBatchInsertSQL(first interface{}, columns []string, rows [][]interface{}) (string, []interface{}) {
 	var allValues []interface{}
 	var valuePlaceholders []string
 
-	for i := 0; i < v.Len(); i++ {
-		item := v.Index(i).Interface()
-		_, values := qb.extractColumnsAndValues(item)
+	for _, values := range rows {
 		allValues = append(allValues, values...)
 
 		placeholders := make([]string, len(values))
@@ -460,54 +1465,79 @@ func (qb *queryBuilder) buildBatchInsertSQL(data interface{}) (string, []interfa
 		valuePlaceholders = append(valuePlaceholders, "("+strings.Join(placeholders, ", ")+")")
 	}
 
+	if qb.upsert {
+		return qb.buildUpsertSQL(first, columns, len(rows)), allValues
+	}
+
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-		qb.tableName,
-		strings.Join(columns, ", "),
+		quoteTableRef(qb.dialect(), qb.tableName),
+		quoteIdentifiers(qb.dialect(), columns),
 		strings.Join(valuePlaceholders, ", "))
 
 	return query, allValues
 }
 
-// buildUpdateSQL 构建UPDATE SQL
-func (qb *queryBuilder) buildUpdateSQL(data interface{}) (string, []interface{}) {
-	columns, values := qb.extractColumnsAndValues(data)
+// getBatchSize 获取批量插入的分块大小
+func (qb *queryBuilder) getBatchSize() int {
+	if qb.batchSize > 0 {
+		return qb.batchSize
+	}
+	if qb.orm != nil && qb.orm.config.BatchSize > 0 {
+		return qb.orm.config.BatchSize
+	}
+	return defaultBatchSize
+}
 
-	var setParts []string
-	for _, col := range columns {
-		setParts = append(setParts, col+" = ?")
+// sameColumns 判断两组列名是否一致
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
 
-	var parts []string
-	var args []interface{}
+// buildUpdateSQL 构建UPDATE SQL
+func (qb *queryBuilder) buildUpdateSQL(data interface{}) (string, []interface{}) {
+	columns, values := qb.updateColumnsAndValues(data)
+	return qb.buildSetSQL(columns, values)
+}
 
-	parts = append(parts, "UPDATE "+qb.tableName)
-	parts = append(parts, "SET "+strings.Join(setParts, ", "))
-	args = append(args, values...)
+// buildUpdateColumnsSQL 构建UPDATE指定列SQL
+func (qb *queryBuilder) buildUpdateColumnsSQL(columns map[string]interface{}) (string, []interface{}) {
+	names := make([]string, 0, len(columns))
+	for col := range columns {
+		names = append(names, col)
+	}
+	sort.Strings(names)
 
-	// WHERE子句
-	if len(qb.conditions) > 0 {
-		whereClause, whereArgs := qb.buildWhereClause()
-		parts = append(parts, "WHERE "+whereClause)
-		args = append(args, whereArgs...)
+	values := make([]interface{}, len(names))
+	for i, col := range names {
+		values[i] = columns[col]
 	}
 
-	return strings.Join(parts, " "), args
+	return qb.buildSetSQL(names, values)
 }
 
-// buildUpdateColumnsSQL 构建UPDATE指定列SQL
-func (qb *queryBuilder) buildUpdateColumnsSQL(columns map[string]interface{}) (string, []interface{}) {
-	var setParts []string
-	var values []interface{}
+// buildSetSQL 根据列和值构建UPDATE SQL
+func (qb *queryBuilder) buildSetSQL(columns []string, values []interface{}) (string, []interface{}) {
+	if scoped := qb.scoped(); scoped != qb {
+		return scoped.buildSetSQL(columns, values)
+	}
 
-	for col, val := range columns {
-		setParts = append(setParts, col+" = ?")
-		values = append(values, val)
+	var setParts []string
+	for _, col := range columns {
+		setParts = append(setParts, quoteIdentifier(qb.dialect(), col)+" = ?")
 	}
 
 	var parts []string
 	var args []interface{}
 
-	parts = append(parts, "UPDATE "+qb.tableName)
+	parts = append(parts, "UPDATE "+quoteTableRef(qb.dialect(), qb.tableName))
 	parts = append(parts, "SET "+strings.Join(setParts, ", "))
 	args = append(args, values...)
 
@@ -523,10 +1553,17 @@ func (qb *queryBuilder) buildUpdateColumnsSQL(columns map[string]interface{}) (s
 
 // buildDeleteSQL 构建DELETE SQL
 func (qb *queryBuilder) buildDeleteSQL() (string, []interface{}) {
+	if column, ok := qb.softDeleteColumn(); ok {
+		return qb.buildSoftDeleteSQL(column)
+	}
+	if scoped := qb.scoped(); scoped != qb {
+		return scoped.buildDeleteSQL()
+	}
+
 	var parts []string
 	var args []interface{}
 
-	parts = append(parts, "DELETE FROM "+qb.tableName)
+	parts = append(parts, "DELETE FROM "+quoteTableRef(qb.dialect(), qb.tableName))
 
 	// WHERE子句
 	if len(qb.conditions) > 0 {
@@ -540,38 +1577,71 @@ func (qb *queryBuilder) buildDeleteSQL() (string, []interface{}) {
 
 // buildWhereClause 构建WHERE子句
 func (qb *queryBuilder) buildWhereClause() (string, []interface{}) {
+	return buildConditions(qb.dialect(), qb.conditions)
+}
+
+// buildConditions 构建条件表达式，分组条件递归生成并加括号
+// WhereIn、WhereNull等方法传入的列名按方言加引号，原始条件原样使用
+func buildConditions(d Dialect, conditions []QueryCondition) (string, []interface{}) {
 	var parts []string
 	var args []interface{}
 
-	for i, condition := range qb.conditions {
+	for i, condition := range conditions {
 		if i > 0 {
 			parts = append(parts, condition.Logic)
 		}
 
 		switch condition.Operator {
+		case "GROUP":
+			groupClause, groupArgs := buildConditions(d, condition.Conditions)
+			parts = append(parts, "("+groupClause+")")
+			args = append(args, groupArgs...)
 		case "IN", "NOT IN":
+			column := quoteIdentifier(d, condition.Column)
+			if sub, ok := subQueryOf(condition.Values); ok {
+				subSQL, subArgs := sub.buildSelectSQL()
+				parts = append(parts, fmt.Sprintf("%s %s (%s)", column, condition.Operator, subSQL))
+				args = append(args, subArgs...)
+				continue
+			}
+			if len(condition.Values) == 0 {
+				// 空列表的 IN () 是语法错误：IN不匹配任何记录，NOT IN匹配全部记录
+				if condition.Operator == "IN" {
+					parts = append(parts, "1 = 0")
+				} else {
+					parts = append(parts, "1 = 1")
+				}
+				continue
+			}
 			placeholders := make([]string, len(condition.Values))
 			for j := range placeholders {
 				placeholders[j] = "?"
 			}
 			parts = append(parts, fmt.Sprintf("%s %s (%s)",
-				condition.Column, condition.Operator, strings.Join(placeholders, ", ")))
+				column, condition.Operator, strings.Join(placeholders, ", ")))
 			args = append(args, condition.Values...)
+		case "EXISTS", "NOT EXISTS":
+			sub, ok := condition.Value.(*queryBuilder)
+			if !ok {
+				continue
+			}
+			subSQL, subArgs := sub.buildSelectSQL()
+			parts = append(parts, fmt.Sprintf("%s (%s)", condition.Operator, subSQL))
+			args = append(args, subArgs...)
 		case "BETWEEN":
-			parts = append(parts, fmt.Sprintf("%s BETWEEN ? AND ?", condition.Column))
+			parts = append(parts, fmt.Sprintf("%s BETWEEN ? AND ?", quoteIdentifier(d, condition.Column)))
 			args = append(args, condition.Values...)
 		case "IS NULL", "IS NOT NULL":
-			parts = append(parts, fmt.Sprintf("%s %s", condition.Column, condition.Operator))
+			parts = append(parts, fmt.Sprintf("%s %s", quoteIdentifier(d, condition.Column), condition.Operator))
 		default:
-			if condition.Value != nil {
-				if values, ok := condition.Value.([]interface{}); ok && len(values) > 0 {
-					// 处理复杂条件，如 "name = ? AND age > ?"
-					parts = append(parts, condition.Column)
-					args = append(args, values...)
-				} else {
-					parts = append(parts, fmt.Sprintf("%s %s ?", condition.Column, condition.Operator))
-					args = append(args, condition.Value)
-				}
+			if values, ok := condition.Value.([]interface{}); ok {
+				// 处理原始条件，如 "name = ? AND age > ?"，参数为查询构建器时展开为子查询
+				clause, clauseArgs := expandSubQueries(condition.Column, values)
+				parts = append(parts, clause)
+				args = append(args, clauseArgs...)
+			} else if condition.Value != nil {
+				parts = append(parts, fmt.Sprintf("%s %s ?", quoteIdentifier(d, condition.Column), comparisonOperator(condition.Operator)))
+				args = append(args, condition.Value)
 			}
 		}
 	}
@@ -579,6 +1649,74 @@ func (qb *queryBuilder) buildWhereClause() (string, []interface{}) {
 	return strings.Join(parts, " "), args
 }
 
+// expandSubQueries 将原始条件中对应查询构建器参数的 ? 替换为子查询SQL并合并参数
+// 如 Where("amount > (?)", orm.Table("orders").Select("AVG(amount)"))
+func expandSubQueries(condition string, values []interface{}) (string, []interface{}) {
+	hasSub := false
+	for _, value := range values {
+		if _, ok := value.(*queryBuilder); ok {
+			hasSub = true
+			break
+		}
+	}
+	if !hasSub {
+		return condition, values
+	}
+
+	var builder strings.Builder
+	var args []interface{}
+	index := 0
+	var quote rune
+	for _, r := range condition {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?' && index < len(values):
+			value := values[index]
+			index++
+			if sub, ok := value.(*queryBuilder); ok {
+				subSQL, subArgs := sub.buildSelectSQL()
+				builder.WriteString(subSQL)
+				args = append(args, subArgs...)
+			} else {
+				builder.WriteRune(r)
+				args = append(args, value)
+			}
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), append(args, values[index:]...)
+}
+
+// subQueryOf 判断IN条件的值是否为单个查询构建器
+func subQueryOf(values []interface{}) (*queryBuilder, bool) {
+	if len(values) != 1 {
+		return nil, false
+	}
+	sub, ok := values[0].(*queryBuilder)
+	return sub, ok
+}
+
+// buildGroupByClause 构建GROUP BY子句，参数按分组表达式的顺序排列
+func (qb *queryBuilder) buildGroupByClause() (string, []interface{}) {
+	if len(qb.groups) == 0 {
+		return "", nil
+	}
+
+	expressions := make([]string, len(qb.groups))
+	var args []interface{}
+	for i, group := range qb.groups {
+		expressions[i] = quoteColumn(qb.dialect(), group.expression)
+		args = append(args, group.args...)
+	}
+	return "GROUP BY " + strings.Join(expressions, ", "), args
+}
+
 // buildHavingClause 构建HAVING子句
 func (qb *queryBuilder) buildHavingClause() (string, []interface{}) {
 	var parts []string
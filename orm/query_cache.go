@@ -0,0 +1,302 @@
+package orm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryCache 查询结果缓存的存储，可替换为Redis等外部存储
+// 值为gob编码的查询结果，tags为查询涉及的表名，写入这些表时按标签失效
+type QueryCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration, tags []string)
+	Invalidate(tags ...string)
+}
+
+// memoryCacheEntry 内存缓存项
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+	tags    []string
+}
+
+// memoryQueryCache 进程内的查询结果缓存，过期项在读取或定期清理时移除
+type memoryQueryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	tags    map[string]map[string]struct{}
+	sweepAt int
+}
+
+// minSweepSize 内存缓存项数达到该值后开始定期清理过期项
+const minSweepSize = 1024
+
+// NewMemoryQueryCache 创建进程内的查询结果缓存，未设置缓存时Cache(ttl)默认使用
+func NewMemoryQueryCache() QueryCache {
+	return &memoryQueryCache{
+		entries: make(map[string]memoryCacheEntry),
+		tags:    make(map[string]map[string]struct{}),
+		sweepAt: minSweepSize,
+	}
+}
+
+// Get 获取未过期的缓存值
+func (c *memoryQueryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		c.remove(key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set 写入缓存值
+func (c *memoryQueryCache) Set(key string, value []byte, ttl time.Duration, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(key)
+	c.entries[key] = memoryCacheEntry{value: value, expires: time.Now().Add(ttl), tags: tags}
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	if len(c.entries) >= c.sweepAt {
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				c.remove(key)
+			}
+		}
+		c.sweepAt = 2*len(c.entries) + minSweepSize
+	}
+}
+
+// Invalidate 移除带有任一标签的缓存
+func (c *memoryQueryCache) Invalidate(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(key)
+		}
+	}
+}
+
+// remove 移除缓存项及其标签索引，调用方需持有锁
+func (c *memoryQueryCache) remove(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// SetQueryCache 设置查询结果缓存的存储，nil表示恢复为默认的内存缓存
+func (o *ORM) SetQueryCache(cache QueryCache) {
+	o.cacheMu.Lock()
+	defer o.cacheMu.Unlock()
+	o.cache = cache
+}
+
+// InvalidateCache 使涉及指定表的缓存失效，用于原始SQL写入等查询构建器无法感知的修改
+func (o *ORM) InvalidateCache(tables ...string) {
+	o.cacheMu.Lock()
+	cache := o.cache
+	o.cacheMu.Unlock()
+
+	if cache != nil {
+		cache.Invalidate(cacheTags(tables)...)
+	}
+}
+
+// queryCache 获取查询结果缓存，未设置时创建内存缓存
+func (o *ORM) queryCache() QueryCache {
+	o.cacheMu.Lock()
+	defer o.cacheMu.Unlock()
+
+	if o.cache == nil {
+		o.cache = NewMemoryQueryCache()
+	}
+	return o.cache
+}
+
+// Cache 缓存本次查询的结果，有效期为ttl；Get、First、Count等读操作在有效期内直接返回缓存
+// 通过查询构建器写入同一表时缓存失效；事务内和加锁的查询不使用缓存
+func (qb *queryBuilder) Cache(ttl time.Duration) QueryBuilder {
+	qb.cacheTTL = ttl
+	return qb
+}
+
+// usesCache 本次查询是否使用缓存
+func (qb *queryBuilder) usesCache() bool {
+	return qb.cacheTTL > 0 && qb.tx == nil && qb.lockMode == "" && qb.orm != nil
+}
+
+// cachedQuery 缓存命中时将结果解码到dest，否则执行load并缓存dest
+// 结果无法gob编码时（如包含interface字段）不缓存，查询照常执行
+func (qb *queryBuilder) cachedQuery(kind string, dest interface{}, query string, args []interface{}, load func() error) error {
+	if !qb.usesCache() {
+		return load()
+	}
+
+	cache := qb.orm.queryCache()
+	key := fmt.Sprintf("%s|%T|%s|%#v", kind, dest, query, args)
+	if data, ok := cache.Get(key); ok {
+		// gob不写入零值字段，解码前先清空dest以免残留旧值
+		target := reflect.ValueOf(dest).Elem()
+		target.Set(reflect.Zero(target.Type()))
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(dest) == nil {
+			return nil
+		}
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if gob.NewEncoder(&buf).Encode(dest) == nil {
+		cache.Set(key, buf.Bytes(), qb.cacheTTL, append(qb.cacheTags(), qb.preloadTags(dest)...))
+	}
+	return nil
+}
+
+// cacheTags 查询涉及的表名，作为缓存失效的标签
+// 包括派生表、JOIN、UNION、CTE以及条件中子查询涉及的表
+func (qb *queryBuilder) cacheTags() []string {
+	tables := []string{qb.tableName}
+	if qb.fromSub != nil {
+		tables = append(tables, qb.fromSub.cacheTags()...)
+	}
+	for _, join := range qb.joins {
+		tables = append(tables, join.Table)
+	}
+	for _, union := range qb.unions {
+		tables = append(tables, union.query.cacheTags()...)
+	}
+	tags := cacheTags(tables)
+	for _, cte := range qb.ctes {
+		tags = append(tags, cte.query.cacheTags()...)
+	}
+	return append(tags, conditionTags(qb.conditions)...)
+}
+
+// conditionTags 条件中子查询涉及的表名，递归处理分组条件
+func conditionTags(conditions []QueryCondition) []string {
+	var tags []string
+	for _, condition := range conditions {
+		tags = append(tags, conditionTags(condition.Conditions)...)
+		if sub, ok := condition.Value.(*queryBuilder); ok {
+			tags = append(tags, sub.cacheTags()...)
+		}
+		values := condition.Values
+		if raw, ok := condition.Value.([]interface{}); ok {
+			values = raw
+		}
+		for _, value := range values {
+			if sub, ok := value.(*queryBuilder); ok {
+				tags = append(tags, sub.cacheTags()...)
+			}
+		}
+	}
+	return tags
+}
+
+// preloadTags 预加载关联涉及的表名（含多对多中间表），预加载在缓存的查询内执行，关联表写入时同样需要失效
+func (qb *queryBuilder) preloadTags(dest interface{}) []string {
+	if len(qb.preloads) == 0 {
+		return nil
+	}
+	t := reflect.TypeOf(dest)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return cacheTags(qb.relationTables(t, buildPreloadTree(qb.preloads)))
+}
+
+// relationTables 按预加载树解析关联模型的表名，无法解析的关联在加载时报错，此处跳过
+func (qb *queryBuilder) relationTables(modelType reflect.Type, nodes []*preloadNode) []string {
+	var tables []string
+	for _, node := range nodes {
+		_, relatedType, relation, err := qb.orm.lookupRelation(reflect.New(modelType).Elem(), node.name)
+		if err != nil {
+			continue
+		}
+		tables = append(tables, qb.orm.getTableName(reflect.New(relatedType).Interface()))
+		if relation.Type == Many2Many {
+			tables = append(tables, relation.JoinTable)
+		}
+		tables = append(tables, qb.relationTables(relatedType, node.children)...)
+	}
+	return tables
+}
+
+// invalidateCache 写操作成功后使涉及该表的缓存失效，事务中的写操作在提交后失效
+func (qb *queryBuilder) invalidateCache() {
+	if t, ok := qb.tx.(*transaction); ok && t.written != nil {
+		t.written.add(qb.tableName)
+		return
+	}
+	if qb.orm != nil {
+		qb.orm.InvalidateCache(qb.tableName)
+	}
+}
+
+// writtenTables 事务中写入过的表，在同一事务的各个副本间共享
+type writtenTables struct {
+	mu     sync.Mutex
+	tables []string
+}
+
+// add 记录写入的表
+func (w *writtenTables) add(table string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tables = append(w.tables, table)
+}
+
+// drain 取出并清空记录的表
+func (w *writtenTables) drain() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	tables := w.tables
+	w.tables = nil
+	return tables
+}
+
+// cacheTags 将表引用（可带别名和引号）规范化为小写表名
+func cacheTags(tables []string) []string {
+	tags := make([]string, 0, len(tables))
+	for _, table := range tables {
+		fields := strings.Fields(table)
+		if len(fields) == 0 {
+			continue
+		}
+		tags = append(tags, strings.ToLower(strings.Trim(fields[0], "`\"[]")))
+	}
+	return tags
+}
@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fastgox/utils/logger"
+)
+
+// QueryLogWriter SQL日志输出接口，logger.Logger 实现了该接口
+type QueryLogWriter interface {
+	Info(format string, v ...interface{})
+	Warn(format string, v ...interface{})
+	Error(format string, v ...interface{})
+}
+
+// QueryLoggerConfig SQL日志配置
+type QueryLoggerConfig struct {
+	Writer        QueryLogWriter                    // 日志输出，为空时使用logger模块的 sql 日志
+	SlowThreshold time.Duration                     // 慢查询阈值，超过时以WARN级别记录，0表示不区分慢查询
+	SlowOnly      bool                              // 只记录慢查询和执行失败的SQL
+	MaskArgs      bool                              // 隐藏全部参数值
+	ArgMasker     func(arg interface{}) interface{} // 自定义参数脱敏，优先于MaskArgs
+}
+
+// NewQueryLogger 创建记录SQL、参数、影响行数和耗时的执行钩子
+func NewQueryLogger(config QueryLoggerConfig) QueryHook {
+	writer := config.Writer
+	if writer == nil {
+		if sqlLogger, err := logger.GetLogger("sql"); err == nil {
+			writer = sqlLogger
+		}
+	}
+
+	return func(ctx context.Context, event *QueryEvent) {
+		slow := config.SlowThreshold > 0 && event.Duration >= config.SlowThreshold
+		if writer == nil || (config.SlowOnly && !slow && event.Err == nil) {
+			return
+		}
+
+		message := fmt.Sprintf("[%s] %s | 参数: %s | 影响行数: %s",
+			event.Duration, event.SQL, formatQueryArgs(event.Args, config), formatRows(event.RowsAffected))
+		if event.InTransaction {
+			message += " | 事务"
+		}
+
+		switch {
+		case event.Err != nil:
+			writer.Error("SQL执行失败 %s | 错误: %v", message, event.Err)
+		case slow:
+			writer.Warn("慢查询(阈值 %s) %s", config.SlowThreshold, message)
+		default:
+			writer.Info("%s", message)
+		}
+	}
+}
+
+// formatQueryArgs 格式化参数，按配置进行脱敏
+func formatQueryArgs(args []interface{}, config QueryLoggerConfig) string {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case config.ArgMasker != nil:
+			arg = config.ArgMasker(arg)
+		case config.MaskArgs:
+			arg = "***"
+		}
+		if s, ok := arg.(string); ok {
+			values[i] = fmt.Sprintf("%q", s)
+		} else {
+			values[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// formatRows 格式化影响行数，未知时显示为 -
+func formatRows(rows int64) string {
+	if rows < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", rows)
+}
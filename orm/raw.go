@@ -0,0 +1,113 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// RawStatement 原始SQL语句，用于执行查询构建器无法表达的复杂查询
+type RawStatement struct {
+	orm   *ORM
+	tx    Tx
+	ctx   context.Context
+	query string
+	args  []interface{}
+}
+
+// RawQuery 创建原始SQL语句
+func (o *ORM) RawQuery(query string, args ...interface{}) *RawStatement {
+	return &RawStatement{orm: o, query: query, args: args}
+}
+
+// RawQuery 在会话中创建原始SQL语句
+func (s *Session) RawQuery(query string, args ...interface{}) *RawStatement {
+	return s.orm.RawQuery(query, args...).WithContext(s.ctx)
+}
+
+// RawQuery 在事务中创建原始SQL语句
+func (t *transaction) RawQuery(query string, args ...interface{}) *RawStatement {
+	return &RawStatement{orm: t.orm, tx: t, ctx: t.ctx, query: query, args: args}
+}
+
+// WithContext 设置执行上下文
+func (rs *RawStatement) WithContext(ctx context.Context) *RawStatement {
+	rs.ctx = ctx
+	return rs
+}
+
+// Scan 执行查询并扫描结果
+// dest 可以是结构体切片指针、结构体指针（取第一行）、基础类型或时间指针（取第一行第一列），
+// 以及基础类型或时间的切片指针（取每行第一列）
+func (rs *RawStatement) Scan(dest interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		return fmt.Errorf("dest必须是非空指针")
+	}
+
+	rows, err := rs.rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	switch destValue.Elem().Kind() {
+	case reflect.Slice:
+		return scanRows(rows, dest, rs.orm.location())
+	case reflect.Struct:
+		if _, ok := dest.(sql.Scanner); !ok {
+			return scanStruct(rows, dest, rs.orm.location())
+		}
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return errRecordNotFound
+	}
+	if err := rows.Scan(dest); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+// Rows 执行查询并返回原始结果集，调用方负责关闭
+func (rs *RawStatement) Rows() (*sql.Rows, error) {
+	return rs.rows()
+}
+
+// ToSQL 获取SQL语句和参数
+func (rs *RawStatement) ToSQL() (string, []interface{}) {
+	return rebind(rs.dialect(), rs.query), rs.args
+}
+
+// dialect 获取当前数据库方言
+func (rs *RawStatement) dialect() Dialect {
+	if rs.orm == nil {
+		return &MySQLDialect{}
+	}
+	return NewDatabaseManager(rs.orm).GetDialect()
+}
+
+// rows 在事务或连接上执行查询
+func (rs *RawStatement) rows() (*sql.Rows, error) {
+	ctx := rs.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	query, args := rs.ToSQL()
+	if rs.tx != nil {
+		return rs.tx.QueryContext(ctx, query, args...)
+	}
+	return rs.orm.QueryContext(ctx, query, args...)
+}
+
+// 全局便捷方法
+
+// RawQuery 创建原始SQL语句
+func RawQuery(query string, args ...interface{}) *RawStatement {
+	return GetGlobalORM().RawQuery(query, args...)
+}
@@ -0,0 +1,92 @@
+package orm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultConnection 默认连接名，Use(DefaultConnection) 返回通过Init初始化的全局ORM
+const DefaultConnection = "default"
+
+var (
+	// connections 已注册的命名连接
+	connections   = make(map[string]*ORM)
+	connectionsMu sync.RWMutex
+)
+
+// Register 创建并连接命名连接，用于同时访问多个数据库或分片
+// 同名连接已存在时返回错误，需先调用Unregister
+func Register(name string, config *Config) error {
+	if name == "" || name == DefaultConnection {
+		return fmt.Errorf("连接名不能为空或使用保留名称: %s", DefaultConnection)
+	}
+
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	if _, exists := connections[name]; exists {
+		return fmt.Errorf("连接已注册: %s", name)
+	}
+
+	o := New(config)
+	if err := o.Connect(); err != nil {
+		return fmt.Errorf("连接 %s 失败: %w", name, err)
+	}
+	connections[name] = o
+	return nil
+}
+
+// Use 获取命名连接，连接不存在时panic
+func Use(name string) *ORM {
+	o, err := Connection(name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return o
+}
+
+// Connection 获取命名连接，连接不存在时返回错误
+func Connection(name string) (*ORM, error) {
+	if name == DefaultConnection {
+		if globalORM == nil {
+			return nil, fmt.Errorf("ORM未初始化，请先调用Init()方法")
+		}
+		return globalORM, nil
+	}
+
+	connectionsMu.RLock()
+	defer connectionsMu.RUnlock()
+
+	o, exists := connections[name]
+	if !exists {
+		return nil, fmt.Errorf("连接未注册: %s", name)
+	}
+	return o, nil
+}
+
+// Unregister 关闭并移除命名连接
+func Unregister(name string) error {
+	connectionsMu.Lock()
+	o, exists := connections[name]
+	delete(connections, name)
+	connectionsMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("连接未注册: %s", name)
+	}
+	return o.Close()
+}
+
+// Connections 获取已注册的连接名（不含默认连接），按名称排序
+func Connections() []string {
+	connectionsMu.RLock()
+	defer connectionsMu.RUnlock()
+
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,559 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RelationType 关联类型
+type RelationType string
+
+const (
+	HasOne    RelationType = "has_one"
+	HasMany   RelationType = "has_many"
+	BelongsTo RelationType = "belongs_to"
+	Many2Many RelationType = "many2many"
+)
+
+// Relation 关联定义
+type Relation struct {
+	Type           RelationType `json:"type"`
+	ForeignKey     string       `json:"foreign_key"`      // 外键列，HasOne/HasMany位于子表，BelongsTo位于当前表
+	References     string       `json:"references"`       // 外键引用的列，默认为id；Many2Many中为当前模型的键
+	JoinTable      string       `json:"join_table"`       // Many2Many中间表
+	JoinForeignKey string       `json:"join_foreign_key"` // 中间表中引用当前模型的列
+	JoinReferences string       `json:"join_references"`  // 中间表中引用关联模型的列
+}
+
+// RelationInterface 通过方法声明关联的模型接口，键为结构体字段名
+type RelationInterface interface {
+	Relations() map[string]Relation
+}
+
+// preloadSpec 预加载声明
+type preloadSpec struct {
+	path       string
+	conditions []func(QueryBuilder) QueryBuilder
+}
+
+// preloadNode 按路径展开后的预加载节点
+type preloadNode struct {
+	name       string
+	conditions []func(QueryBuilder) QueryBuilder
+	children   []*preloadNode
+}
+
+// Preload 预加载关联，查询完成后按外键批量查询关联数据并回填到结构体字段
+// 支持用点号加载嵌套关联（如 "Orders.Items"），conditions 作用于路径中的最后一级关联
+func (qb *queryBuilder) Preload(relation string, conditions ...func(QueryBuilder) QueryBuilder) QueryBuilder {
+	qb.preloads = append(qb.preloads, preloadSpec{path: relation, conditions: conditions})
+	return qb
+}
+
+// preload 为查询结果加载所有预加载关联
+func (qb *queryBuilder) preload(dest interface{}) error {
+	if len(qb.preloads) == 0 {
+		return nil
+	}
+
+	parents := collectStructs(reflect.ValueOf(dest))
+	if len(parents) == 0 {
+		return nil
+	}
+
+	return qb.loadRelations(parents, buildPreloadTree(qb.preloads))
+}
+
+// buildPreloadTree 将预加载路径展开为树，同一关联只加载一次
+func buildPreloadTree(specs []preloadSpec) []*preloadNode {
+	var roots []*preloadNode
+	for _, spec := range specs {
+		nodes := &roots
+		names := strings.Split(spec.path, ".")
+		for i, name := range names {
+			var node *preloadNode
+			for _, existing := range *nodes {
+				if existing.name == name {
+					node = existing
+					break
+				}
+			}
+			if node == nil {
+				node = &preloadNode{name: name}
+				*nodes = append(*nodes, node)
+			}
+			if i == len(names)-1 {
+				node.conditions = append(node.conditions, spec.conditions...)
+			}
+			nodes = &node.children
+		}
+	}
+	return roots
+}
+
+// loadRelations 依次加载关联，并递归加载嵌套关联
+func (qb *queryBuilder) loadRelations(parents []reflect.Value, nodes []*preloadNode) error {
+	for _, node := range nodes {
+		field, err := qb.loadRelation(parents, node)
+		if err != nil {
+			return err
+		}
+		if len(node.children) == 0 {
+			continue
+		}
+
+		var children []reflect.Value
+		for _, parent := range parents {
+			children = append(children, collectStructs(parent.FieldByIndex(field.Index))...)
+		}
+		if len(children) == 0 {
+			continue
+		}
+		if err := qb.loadRelations(children, node.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRelation 加载单个关联到父记录，返回关联字段
+func (qb *queryBuilder) loadRelation(parents []reflect.Value, node *preloadNode) (reflect.StructField, error) {
+	field, relatedType, relation, err := qb.orm.lookupRelation(parents[0], node.name)
+	if err != nil {
+		return field, err
+	}
+
+	if relation.Type == Many2Many {
+		return field, qb.loadMany2Many(parents, field, relatedType, relation, node)
+	}
+
+	// HasOne/HasMany 用父记录的引用列匹配子表外键，BelongsTo 用当前表外键匹配关联表的引用列
+	ownerKey, relatedKey := relation.References, relation.ForeignKey
+	if relation.Type == BelongsTo {
+		ownerKey, relatedKey = relation.ForeignKey, relation.References
+	}
+
+	ownerColumns, relatedColumns := keyColumns(ownerKey), keyColumns(relatedKey)
+	if len(ownerColumns) != len(relatedColumns) {
+		return field, fmt.Errorf("关联 %s 的外键 %s 与引用列 %s 数量不一致", node.name, relation.ForeignKey, relation.References)
+	}
+
+	keys := relationKeys(parents, ownerColumns)
+	if len(keys) == 0 {
+		return field, nil
+	}
+
+	grouped, err := qb.loadRelated(relatedType, relatedColumns, keys, node)
+	if err != nil {
+		return field, err
+	}
+
+	for _, parent := range parents {
+		assignRelation(parent.FieldByIndex(field.Index), grouped[compositeKey(parent, ownerColumns)])
+	}
+	return field, nil
+}
+
+// loadMany2Many 通过中间表加载多对多关联
+func (qb *queryBuilder) loadMany2Many(parents []reflect.Value, field reflect.StructField, relatedType reflect.Type, relation Relation, node *preloadNode) error {
+	var keys []interface{}
+	for _, key := range relationKeys(parents, []string{relation.References}) {
+		keys = append(keys, key[0])
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pairs, err := qb.pivotPairs(relation, keys)
+	if err != nil {
+		return fmt.Errorf("预加载关联 %s 失败: %w", node.name, err)
+	}
+
+	var relatedKeys [][]interface{}
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		if k := keyString(pair[1]); !seen[k] {
+			seen[k] = true
+			relatedKeys = append(relatedKeys, []interface{}{pair[1]})
+		}
+	}
+	if len(relatedKeys) == 0 {
+		for _, parent := range parents {
+			assignRelation(parent.FieldByIndex(field.Index), nil)
+		}
+		return nil
+	}
+
+	grouped, err := qb.loadRelated(relatedType, []string{relatedKeyColumn(relatedType)}, relatedKeys, node)
+	if err != nil {
+		return err
+	}
+
+	owned := make(map[string][]reflect.Value)
+	for _, pair := range pairs {
+		ownerKey := keyString(pair[0])
+		owned[ownerKey] = append(owned[ownerKey], grouped[keyString(pair[1])]...)
+	}
+
+	for _, parent := range parents {
+		key := findFieldByColumn(parent, relation.References)
+		assignRelation(parent.FieldByIndex(field.Index), owned[relationKey(key)])
+	}
+	return nil
+}
+
+// loadRelated 按关联键批量查询关联记录，并按关联键分组
+// 关联键按批次大小分批查询以免超出数据库的参数上限，预加载条件分别作用于每一批
+// 单列键使用IN条件，复合键使用按列匹配的OR条件组；预加载条件在关联键条件之后应用，可追加过滤和排序
+func (qb *queryBuilder) loadRelated(relatedType reflect.Type, relatedColumns []string, keys [][]interface{}, node *preloadNode) (map[string][]reflect.Value, error) {
+	grouped := make(map[string][]reflect.Value)
+	batchSize := qb.getBatchSize()
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		items, err := qb.loadRelatedChunk(relatedType, relatedColumns, keys[start:end], node)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < items.Len(); i++ {
+			item := items.Index(i)
+			for _, column := range relatedColumns {
+				if !findFieldByColumn(item, column).IsValid() {
+					return nil, fmt.Errorf("关联模型 %s 不存在列: %s", relatedType.Name(), column)
+				}
+			}
+			k := compositeKey(item, relatedColumns)
+			grouped[k] = append(grouped[k], item)
+		}
+	}
+	return grouped, nil
+}
+
+// loadRelatedChunk 查询一批关联键对应的关联记录
+func (qb *queryBuilder) loadRelatedChunk(relatedType reflect.Type, relatedColumns []string, keys [][]interface{}, node *preloadNode) (reflect.Value, error) {
+	related := reflect.New(reflect.SliceOf(relatedType))
+	var builder QueryBuilder = qb.relatedBuilder(reflect.New(relatedType).Interface())
+	if len(relatedColumns) == 1 {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = key[0]
+		}
+		builder = builder.WhereIn(relatedColumns[0], values...)
+	} else {
+		builder = builder.WhereGroup(func(group QueryBuilder) {
+			for _, key := range keys {
+				key := key
+				group.OrWhereGroup(func(match QueryBuilder) {
+					for i, column := range relatedColumns {
+						match.WhereMap(map[string]interface{}{column: key[i]})
+					}
+				})
+			}
+		})
+	}
+	for _, condition := range node.conditions {
+		builder = condition(builder)
+	}
+	if err := builder.Get(related.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("预加载关联 %s 失败: %w", node.name, err)
+	}
+	return related.Elem(), nil
+}
+
+// pivotPairs 查询中间表中当前模型键与关联模型键的对应关系，键按批次大小分批查询
+func (qb *queryBuilder) pivotPairs(relation Relation, keys []interface{}) ([][2]interface{}, error) {
+	var pairs [][2]interface{}
+	batchSize := qb.getBatchSize()
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		chunk, err := qb.pivotPairsChunk(relation, keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, chunk...)
+	}
+	return pairs, nil
+}
+
+// pivotPairsChunk 查询一批当前模型键在中间表中的对应关系
+func (qb *queryBuilder) pivotPairsChunk(relation Relation, keys []interface{}) ([][2]interface{}, error) {
+	pivot := qb.tableBuilder(relation.JoinTable)
+	pivot.Select(relation.JoinForeignKey, relation.JoinReferences).WhereIn(relation.JoinForeignKey, keys...)
+	query, args := pivot.buildSelectSQL()
+
+	rows, err := pivot.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs [][2]interface{}
+	for rows.Next() {
+		var pair [2]interface{}
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+// lookupRelation 查找关联字段并解析关联定义
+func (o *ORM) lookupRelation(parent reflect.Value, name string) (reflect.StructField, reflect.Type, Relation, error) {
+	parentType := parent.Type()
+	field, ok := parentType.FieldByName(name)
+	if !ok {
+		return field, nil, Relation{}, fmt.Errorf("模型 %s 不存在关联字段: %s", parentType.Name(), name)
+	}
+
+	relatedType := field.Type
+	for relatedType.Kind() == reflect.Ptr || relatedType.Kind() == reflect.Slice {
+		relatedType = relatedType.Elem()
+	}
+	if relatedType.Kind() != reflect.Struct {
+		return field, nil, Relation{}, fmt.Errorf("关联字段 %s 必须是结构体、结构体指针或其切片", name)
+	}
+
+	relation, err := o.resolveRelation(parent, field, relatedType)
+	return field, relatedType, relation, err
+}
+
+// relatedBuilder 创建与当前构建器共享连接、事务和上下文的关联查询构建器
+func (qb *queryBuilder) relatedBuilder(model interface{}) *queryBuilder {
+	builder := qb.tableBuilder(qb.orm.getTableName(model))
+	builder.model = model
+	return builder
+}
+
+// tableBuilder 创建与当前构建器共享连接、事务和上下文的指定表查询构建器
+func (qb *queryBuilder) tableBuilder(tableName string) *queryBuilder {
+	return &queryBuilder{
+		orm:        qb.orm,
+		tx:         qb.tx,
+		ctx:        qb.ctx,
+		tableName:  tableName,
+		usePrimary: qb.usePrimary,
+		useReplica: qb.useReplica,
+	}
+}
+
+// resolveRelation 解析关联定义，优先使用Relations()方法，其次使用relation标签
+// 未声明的外键、中间表及其键列按配置的命名策略和表前缀补全
+func (o *ORM) resolveRelation(parent reflect.Value, field reflect.StructField, relatedType reflect.Type) (Relation, error) {
+	var relation Relation
+	found := false
+
+	if parent.CanAddr() {
+		if r, ok := parent.Addr().Interface().(RelationInterface); ok {
+			relation, found = r.Relations()[field.Name]
+		}
+	}
+	if !found {
+		tag := relationTag(field)
+		if tag == "" {
+			return relation, fmt.Errorf("字段 %s 未声明关联关系", field.Name)
+		}
+		relation = parseRelationTag(tag)
+	}
+
+	switch relation.Type {
+	case HasOne, HasMany:
+		if relation.ForeignKey == "" {
+			relation.ForeignKey = o.foreignKeyName(parent.Type().Name())
+		}
+	case BelongsTo:
+		if relation.ForeignKey == "" {
+			relation.ForeignKey = o.foreignKeyName(field.Name)
+		}
+	case Many2Many:
+		if relation.JoinTable == "" {
+			relation.JoinTable = o.joinTableName(parent.Type().Name(), relatedType.Name())
+		}
+		if relation.JoinForeignKey == "" {
+			relation.JoinForeignKey = o.foreignKeyName(parent.Type().Name())
+		}
+		if relation.JoinReferences == "" {
+			relation.JoinReferences = o.foreignKeyName(relatedType.Name())
+		}
+	default:
+		return relation, fmt.Errorf("字段 %s 的关联类型不支持: %s", field.Name, relation.Type)
+	}
+	if relation.References == "" {
+		relation.References = "id"
+	}
+	return relation, nil
+}
+
+// parseRelationTag 解析relation标签，如 relation:"has_many,foreign_key:user_id,references:id"
+// 多对多：relation:"many2many,join_table:user_roles,join_foreign_key:user_id,join_references:role_id"
+func parseRelationTag(tag string) Relation {
+	var relation Relation
+	for i, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if i == 0 {
+			relation.Type = RelationType(part)
+			continue
+		}
+		if strings.HasPrefix(part, "foreign_key:") {
+			relation.ForeignKey = strings.TrimPrefix(part, "foreign_key:")
+		} else if strings.HasPrefix(part, "references:") {
+			relation.References = strings.TrimPrefix(part, "references:")
+		} else if strings.HasPrefix(part, "join_table:") {
+			relation.JoinTable = strings.TrimPrefix(part, "join_table:")
+		} else if strings.HasPrefix(part, "join_foreign_key:") {
+			relation.JoinForeignKey = strings.TrimPrefix(part, "join_foreign_key:")
+		} else if strings.HasPrefix(part, "join_references:") {
+			relation.JoinReferences = strings.TrimPrefix(part, "join_references:")
+		}
+	}
+	return relation
+}
+
+// relationTag 获取字段的关联声明，orm标签中的 many2many:中间表 等同于 relation:"many2many,join_table:中间表"
+func relationTag(field reflect.StructField) string {
+	if tag := field.Tag.Get("relation"); tag != "" {
+		return tag
+	}
+	for _, part := range strings.Split(field.Tag.Get("orm"), ",") {
+		if joinTable, ok := strings.CutPrefix(strings.TrimSpace(part), "many2many:"); ok {
+			return "many2many,join_table:" + joinTable
+		}
+	}
+	return ""
+}
+
+// isRelationField 判断字段是否为关联字段，关联字段不映射为列
+func isRelationField(field reflect.StructField) bool {
+	return relationTag(field) != ""
+}
+
+// collectStructs 收集结构体、结构体指针或其切片中的可寻址结构体
+func collectStructs(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{v}
+	case reflect.Slice:
+		var result []reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			result = append(result, collectStructs(v.Index(i).Addr())...)
+		}
+		return result
+	}
+	return nil
+}
+
+// keyColumns 拆分关联键列，复合键的多个列用+连接，如 "order_id+tenant_id"
+func keyColumns(key string) []string {
+	var columns []string
+	for _, column := range strings.Split(key, "+") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// relationKeys 收集父记录中指定列的去重键值，任一列为零值的记录被跳过
+func relationKeys(parents []reflect.Value, columns []string) [][]interface{} {
+	seen := make(map[string]bool)
+	var keys [][]interface{}
+	for _, parent := range parents {
+		key := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			field := findFieldByColumn(parent, column)
+			if !field.IsValid() || isZeroValue(field) {
+				key = nil
+				break
+			}
+			key = append(key, field.Interface())
+		}
+		if key == nil {
+			continue
+		}
+
+		if k := compositeKey(parent, columns); !seen[k] {
+			seen[k] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// compositeKey 将记录中多列的键值拼接为分组用的字符串
+func compositeKey(v reflect.Value, columns []string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = relationKey(findFieldByColumn(v, column))
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// relationKey 将键值规范化为字符串，避免int与int64等类型差异导致匹配失败
+func relationKey(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return keyString(v.Interface())
+}
+
+// keyString 将键值转换为字符串，[]byte按字符串处理
+func keyString(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// relatedKeyColumn 获取关联模型的主键列，未声明时默认为id
+func relatedKeyColumn(relatedType reflect.Type) string {
+	if columns := primaryKeyColumns(reflect.New(relatedType).Interface()); len(columns) > 0 {
+		return columns[0]
+	}
+	return "id"
+}
+
+// assignRelation 将关联记录写入字段，支持结构体、结构体指针及其切片
+func assignRelation(field reflect.Value, items []reflect.Value) {
+	switch field.Kind() {
+	case reflect.Slice:
+		isPtr := field.Type().Elem().Kind() == reflect.Ptr
+		slice := reflect.MakeSlice(field.Type(), 0, len(items))
+		for _, item := range items {
+			if isPtr {
+				slice = reflect.Append(slice, item.Addr())
+			} else {
+				slice = reflect.Append(slice, item)
+			}
+		}
+		field.Set(slice)
+	case reflect.Ptr:
+		if len(items) > 0 {
+			field.Set(items[0].Addr())
+		}
+	case reflect.Struct:
+		if len(items) > 0 {
+			field.Set(items[0])
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replicaRetryInterval 不可用的只读副本在该时间后重新参与选择
+const replicaRetryInterval = 30 * time.Second
+
+// replicaPool 只读副本连接池，按轮询选择可用副本
+type replicaPool struct {
+	replicas []*replica
+	next     uint32
+}
+
+// replica 只读副本
+type replica struct {
+	db        *sql.DB
+	config    *Config
+	mu        sync.Mutex
+	downUntil time.Time
+	closed    bool
+}
+
+// connectReplicas 连接配置中的只读副本，未设置的字段沿用主库配置
+// 连接失败的副本会被标记为不可用，稍后重试，不影响主库连接
+func connectReplicas(primary *Config) *replicaPool {
+	if len(primary.Replicas) == 0 {
+		return nil
+	}
+
+	pool := &replicaPool{}
+	for i := range primary.Replicas {
+		r := &replica{config: replicaConfig(primary, primary.Replicas[i])}
+		if db, err := openDB(r.config); err == nil {
+			r.db = db
+		} else {
+			r.downUntil = time.Now().Add(replicaRetryInterval)
+		}
+		pool.replicas = append(pool.replicas, r)
+	}
+	return pool
+}
+
+// replicaConfig 合并副本配置，未设置的字段使用主库配置
+func replicaConfig(primary *Config, config Config) *Config {
+	if config.Type == "" {
+		config.Type = primary.Type
+	}
+	if config.Host == "" {
+		config.Host = primary.Host
+	}
+	if config.Port == 0 {
+		config.Port = primary.Port
+	}
+	if config.Username == "" {
+		config.Username = primary.Username
+	}
+	if config.Password == "" {
+		config.Password = primary.Password
+	}
+	if config.Database == "" {
+		config.Database = primary.Database
+	}
+	if config.SSLMode == "" {
+		config.SSLMode = primary.SSLMode
+	}
+	if config.Charset == "" {
+		config.Charset = primary.Charset
+	}
+	if config.Timezone == "" {
+		config.Timezone = primary.Timezone
+	}
+	if config.MaxOpenConns == 0 {
+		config.MaxOpenConns = primary.MaxOpenConns
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = primary.MaxIdleConns
+	}
+	if config.MaxLifetime == 0 {
+		config.MaxLifetime = primary.MaxLifetime
+	}
+	config.Replicas = nil
+	return &config
+}
+
+// pick 轮询选择一个可用副本及其连接，没有可用副本时返回nil
+func (p *replicaPool) pick() (*replica, *sql.DB) {
+	if p == nil {
+		return nil, nil
+	}
+
+	count := len(p.replicas)
+	start := atomic.AddUint32(&p.next, 1)
+	for i := 0; i < count; i++ {
+		r := p.replicas[(int(start)+i)%count]
+		if db := r.available(); db != nil {
+			return r, db
+		}
+	}
+	return nil, nil
+}
+
+// available 获取可用副本的连接，副本不可用或已关闭时返回nil，到达重试时间的副本会尝试重新连接
+func (r *replica) available() *sql.DB {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	if r.downUntil.IsZero() {
+		return r.db
+	}
+	if time.Now().Before(r.downUntil) {
+		return nil
+	}
+
+	if r.db == nil {
+		db, err := openDB(r.config)
+		if err != nil {
+			r.downUntil = time.Now().Add(replicaRetryInterval)
+			return nil
+		}
+		r.db = db
+	} else if err := r.db.Ping(); err != nil {
+		r.downUntil = time.Now().Add(replicaRetryInterval)
+		return nil
+	}
+
+	r.downUntil = time.Time{}
+	return r.db
+}
+
+// markDown 标记副本暂时不可用
+func (r *replica) markDown() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downUntil = time.Now().Add(replicaRetryInterval)
+}
+
+// close 关闭全部副本连接，关闭后的副本不再被选择
+func (p *replicaPool) close() {
+	if p == nil {
+		return
+	}
+	for _, r := range p.replicas {
+		r.mu.Lock()
+		r.closed = true
+		if r.db != nil {
+			r.db.Close()
+			r.db = nil
+		}
+		r.mu.Unlock()
+	}
+}
+
+// readQueryContext 在只读副本上执行查询，没有可用副本或副本连接失败时使用主库
+func (o *ORM) readQueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if r, db := o.replicas.pick(); r != nil {
+		rows, err := o.observeQuery(ctx, false, query, args, func() (*sql.Rows, error) {
+			return db.QueryContext(ctx, query, args...)
+		})
+		if !isConnectionError(err) {
+			return rows, err
+		}
+		r.markDown()
+	}
+	return o.QueryContext(ctx, query, args...)
+}
+
+// readQueryRowContext 在只读副本上执行单行查询，没有可用副本或副本连接失败时使用主库
+func (o *ORM) readQueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if r, db := o.replicas.pick(); r != nil {
+		row := o.observeQueryRow(ctx, false, query, args, func() *sql.Row {
+			return db.QueryRowContext(ctx, query, args...)
+		})
+		if !isConnectionError(row.Err()) {
+			return row
+		}
+		r.markDown()
+	}
+	return o.QueryRowContext(ctx, query, args...)
+}
+
+// connectionErrorMessages 各驱动表示连接已断开的错误信息
+var connectionErrorMessages = []string{
+	"connection is closed",
+	"connection reset",
+	"broken pipe",
+	"invalid connection",
+	"bad connection",
+	"connection refused",
+	"server closed the connection",
+}
+
+// isConnectionError 判断是否为连接层面的错误
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, text := range connectionErrorMessages {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsePrimary 强制在主库上执行读操作，用于写后立即读取等需要强一致的场景
+func (qb *queryBuilder) UsePrimary() QueryBuilder {
+	qb.usePrimary = true
+	qb.useReplica = false
+	return qb
+}
+
+// UseReplica 强制在只读副本上执行读操作，事务中或默认使用主库的读操作也路由到副本，用于可以接受延迟的报表等查询
+// 行锁查询仍在主库执行；未配置副本时按原方式执行，没有可用副本时使用主库
+func (qb *queryBuilder) UseReplica() QueryBuilder {
+	qb.useReplica = true
+	qb.usePrimary = false
+	return qb
+}
+
+// readsFromReplica 当前读操作是否路由到只读副本
+// 事务、行锁查询和指定主库的查询使用主库，UseReplica指定的非行锁查询使用副本
+func (qb *queryBuilder) readsFromReplica() bool {
+	if qb.orm == nil || qb.orm.replicas == nil || qb.lockMode != "" {
+		return false
+	}
+	return qb.useReplica || (qb.tx == nil && !qb.usePrimary)
+}
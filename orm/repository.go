@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+)
+
+// modelSource 可基于模型创建查询构建器的对象，ORM和事务均满足
+type modelSource interface {
+	Model(model interface{}) QueryBuilder
+}
+
+// Repository 泛型仓储，在查询构建器之上提供类型安全的增删改查
+// T 为模型结构体类型（非指针），如 Repository[User]
+type Repository[T any] struct {
+	source modelSource
+}
+
+// NewRepository 创建模型的泛型仓储
+func NewRepository[T any](o *ORM) *Repository[T] {
+	return &Repository[T]{source: o}
+}
+
+// WithTx 返回在事务中执行的仓储副本
+func (r *Repository[T]) WithTx(tx Tx) *Repository[T] {
+	return &Repository[T]{source: tx}
+}
+
+// Query 创建携带上下文的模型查询构建器，用于仓储方法未覆盖的复杂查询
+func (r *Repository[T]) Query(ctx context.Context) QueryBuilder {
+	return r.source.Model(new(T)).WithContext(ctx)
+}
+
+// Find 按主键查询单条记录，复合主键传入[]interface{}；记录不存在时返回ErrRecordNotFound
+func (r *Repository[T]) Find(ctx context.Context, id interface{}) (T, error) {
+	var entity T
+	err := r.Query(ctx).FindByID(id, &entity)
+	return entity, err
+}
+
+// List 按等值条件查询记录列表，filter为空时返回全部记录
+func (r *Repository[T]) List(ctx context.Context, filter map[string]interface{}) ([]T, error) {
+	var entities []T
+	if err := r.Query(ctx).WhereMap(filter).Get(&entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Count 按等值条件统计记录数
+func (r *Repository[T]) Count(ctx context.Context, filter map[string]interface{}) (int64, error) {
+	return r.Query(ctx).WhereMap(filter).Count()
+}
+
+// Create 插入记录，自增主键为零值时回填插入的ID
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return r.Query(ctx).Insert(entity)
+}
+
+// Update 按主键更新记录，带版本号字段时使用乐观锁
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	if _, _, ok := primaryKeyValues(entity); !ok {
+		return fmt.Errorf("更新记录需要主键值")
+	}
+	return r.Query(ctx).Update(entity)
+}
+
+// Delete 按主键删除记录
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	if _, _, ok := primaryKeyValues(entity); !ok {
+		return fmt.Errorf("删除记录需要主键值")
+	}
+	return r.source.Model(entity).WithContext(ctx).Delete()
+}
@@ -0,0 +1,189 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scannerType sql.Scanner接口类型
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// fieldAssigner 扫描完成后将中间值写入字段
+type fieldAssigner func() error
+
+// scanInto 扫描当前行到结构体，列与字段按名称匹配，未匹配的列被忽略
+// 基础类型、time.Time和没有映射字段的结构体按位置取第一列；时间字段按loc解析不带时区的值
+func scanInto(rows *sql.Rows, elem reflect.Value, columns []string, loc *time.Location) error {
+	if scansByPosition(elem.Type()) {
+		return scanFirstColumn(rows, elem, columns, loc)
+	}
+	dests, assigners := scanDestinations(elem, columns, loc)
+	if err := rows.Scan(dests...); err != nil {
+		return err
+	}
+	for _, assign := range assigners {
+		if err := assign(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scansByPosition 是否按位置扫描到该类型，而不是按列名匹配结构体字段
+func scansByPosition(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || isTimeField(t) || reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+	return len(metadataOf(t).lookup) == 0
+}
+
+// scanFirstColumn 扫描当前行的第一列到elem，其余列被忽略
+func scanFirstColumn(rows *sql.Rows, elem reflect.Value, columns []string, loc *time.Location) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("查询结果没有列")
+	}
+	dests := make([]interface{}, len(columns))
+	for i := range dests {
+		var dummy interface{}
+		dests[i] = &dummy
+	}
+
+	var assign fieldAssigner
+	if isTimeField(elem.Type()) {
+		dests[0], assign = timeAssigner(elem, columns[0], loc)
+	} else {
+		dests[0] = elem.Addr().Interface()
+	}
+	if err := rows.Scan(dests...); err != nil {
+		return err
+	}
+	if assign != nil {
+		return assign()
+	}
+	return nil
+}
+
+// scanDestinations 为结构体准备与列对应的扫描目标，未匹配的列使用占位变量
+// 指针字段和sql.Null等实现了sql.Scanner的字段直接扫描；标记为nullable的字段先扫描到中间指针，NULL时写入零值
+// type:JSON的字段先扫描原始值，再反序列化到字段；时间字段先扫描原始值，再按多种格式转换
+func scanDestinations(elem reflect.Value, columns []string, loc *time.Location) ([]interface{}, []fieldAssigner) {
+	scanDest := make([]interface{}, len(columns))
+	var assigners []fieldAssigner
+
+	meta := metadataOf(elem.Type())
+	for i, col := range columns {
+		var field reflect.Value
+		mapped := meta.fieldByColumn(col)
+		if mapped != nil {
+			field = elem.FieldByIndex(mapped.index)
+		}
+		if !field.IsValid() || !field.CanSet() {
+			var dummy interface{}
+			scanDest[i] = &dummy
+			continue
+		}
+
+		fieldTag := mapped.tag
+		if isTimeField(field.Type()) && !isJSONColumn(fieldTag) {
+			dest, assign := timeAssigner(field, col, loc)
+			scanDest[i] = dest
+			assigners = append(assigners, assign)
+			continue
+		}
+		if isJSONColumn(fieldTag) && !reflect.PointerTo(field.Type()).Implements(scannerType) {
+			dest, assign := jsonAssigner(field, col)
+			scanDest[i] = dest
+			assigners = append(assigners, assign)
+			continue
+		}
+		if !fieldTag.Nullable || !needsNullHolder(field.Type()) {
+			scanDest[i] = field.Addr().Interface()
+			continue
+		}
+
+		holder := reflect.New(reflect.PointerTo(field.Type()))
+		scanDest[i] = holder.Interface()
+		assigners = append(assigners, func() error {
+			if holder.Elem().IsNil() {
+				field.Set(reflect.Zero(field.Type()))
+			} else {
+				field.Set(holder.Elem().Elem())
+			}
+			return nil
+		})
+	}
+	return scanDest, assigners
+}
+
+// needsNullHolder 字段类型是否需要中间指针才能接收NULL
+func needsNullHolder(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// scanMaps 扫描结果集到map切片，键为列名，limit大于0时最多扫描limit行
+func scanMaps(rows *sql.Rows, limit int, loc *time.Location) ([]map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanDest := make([]interface{}, len(columns))
+		for i := range values {
+			scanDest[i] = &values[i]
+		}
+		if err := rows.Scan(scanDest...); err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			result[column] = normalizeValue(values[i], columnTypes[i].DatabaseTypeName(), loc)
+		}
+		results = append(results, result)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, rows.Err()
+}
+
+// normalizeValue 统一驱动返回的值：文本以[]byte返回时转为字符串，
+// 整数和浮点列按列类型解析为int64和float64，二进制列保留[]byte，时间转换到loc时区
+func normalizeValue(value interface{}, dbType string, loc *time.Location) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		dbType = strings.ToUpper(dbType)
+		switch {
+		case strings.Contains(dbType, "BLOB") || strings.Contains(dbType, "BINARY") || dbType == "BYTEA" || dbType == "IMAGE":
+			return append([]byte(nil), v...)
+		case strings.Contains(dbType, "INT"):
+			if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return n
+			}
+		case dbType == "FLOAT" || dbType == "DOUBLE" || dbType == "REAL" || strings.HasPrefix(dbType, "FLOAT"):
+			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
+				return f
+			}
+		}
+		return string(v)
+	case time.Time:
+		if loc != nil {
+			return v.In(loc)
+		}
+	}
+	return value
+}
@@ -2,6 +2,7 @@ package orm
 
 import (
 	"fmt"
+	"strings"
 )
 
 // schema 表结构实现
@@ -19,9 +20,12 @@ func (s *schema) CreateTable(tableName string, callback func(TableInterface)) er
 	table := NewTableBuilder(tableName, s.orm)
 	callback(table)
 
-	sql := table.(*tableBuilder).ToSQL()
-	_, err := s.orm.Exec(sql)
-	return err
+	for _, sql := range table.(*tableBuilder).createStatements() {
+		if _, err := s.orm.Exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DropTable 删除表
@@ -59,12 +63,16 @@ func (s *schema) HasTable(tableName string) (bool, error) {
 		sql = "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name = ?"
 	case SQLServer:
 		sql = "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ?"
+	case ClickHouse:
+		sql = "SELECT COUNT(*) FROM system.tables WHERE database = currentDatabase() AND name = ?"
+	case Oracle:
+		sql = "SELECT COUNT(*) FROM user_tables WHERE table_name = ?"
 	default:
 		return false, fmt.Errorf("不支持的数据库类型")
 	}
 
 	var count int
-	err := s.orm.QueryRow(sql, tableName).Scan(&count)
+	err := s.orm.QueryRow(rebind(NewDatabaseManager(s.orm).GetDialect(), sql), tableName).Scan(&count)
 	return count > 0, err
 }
 
@@ -80,12 +88,16 @@ func (s *schema) HasColumn(tableName, columnName string) (bool, error) {
 		sql = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
 	case SQLServer:
 		sql = "SELECT COUNT(*) FROM information_schema.columns WHERE table_name = ? AND column_name = ?"
+	case ClickHouse:
+		sql = "SELECT COUNT(*) FROM system.columns WHERE database = currentDatabase() AND table = ? AND name = ?"
+	case Oracle:
+		sql = "SELECT COUNT(*) FROM user_tab_columns WHERE table_name = ? AND column_name = ?"
 	default:
 		return false, fmt.Errorf("不支持的数据库类型")
 	}
 
 	var count int
-	err := s.orm.QueryRow(sql, tableName, columnName).Scan(&count)
+	err := s.orm.QueryRow(rebind(NewDatabaseManager(s.orm).GetDialect(), sql), tableName, columnName).Scan(&count)
 	return count > 0, err
 }
 
@@ -97,6 +109,9 @@ type tableBuilder struct {
 	indexes   []IndexDefinition
 	alterMode bool
 	alterOps  []AlterOperation
+	// foreign 最近一次通过Foreign声明外键的列，OnDelete和OnUpdate作用于该列
+	foreign string
+	options TableOptions
 }
 
 // IndexDefinition 索引定义
@@ -445,6 +460,27 @@ func (tb *tableBuilder) Foreign(column, references string) TableInterface {
 			tb.columns[i].References = references
 		}
 	}
+	tb.foreign = column
+	return tb
+}
+
+// OnDelete 设置最近声明的外键在引用记录删除时的动作，如 CASCADE、SET NULL、RESTRICT
+func (tb *tableBuilder) OnDelete(action string) TableInterface {
+	for i := range tb.columns {
+		if tb.columns[i].Name == tb.foreign {
+			tb.columns[i].OnDelete = strings.ToUpper(action)
+		}
+	}
+	return tb
+}
+
+// OnUpdate 设置最近声明的外键在引用列更新时的动作
+func (tb *tableBuilder) OnUpdate(action string) TableInterface {
+	for i := range tb.columns {
+		if tb.columns[i].Name == tb.foreign {
+			tb.columns[i].OnUpdate = strings.ToUpper(action)
+		}
+	}
 	return tb
 }
 
@@ -488,10 +524,39 @@ func (tb *tableBuilder) AutoIncrement() TableInterface {
 	return tb
 }
 
-// ToSQL 生成创建表SQL
+// Engine 设置存储引擎（MySQL、ClickHouse）
+func (tb *tableBuilder) Engine(engine string) TableInterface {
+	tb.options.Engine = engine
+	return tb
+}
+
+// Charset 设置表的默认字符集（MySQL）
+func (tb *tableBuilder) Charset(charset string) TableInterface {
+	tb.options.Charset = charset
+	return tb
+}
+
+// Collation 设置表的默认排序规则（MySQL）
+func (tb *tableBuilder) Collation(collation string) TableInterface {
+	tb.options.Collation = collation
+	return tb
+}
+
+// TableComment 设置表注释
+func (tb *tableBuilder) TableComment(comment string) TableInterface {
+	tb.options.Comment = comment
+	return tb
+}
+
+// ToSQL 生成创建表SQL，PostgreSQL和Oracle的表注释不包含在内
 func (tb *tableBuilder) ToSQL() string {
+	return tb.createStatements()[0]
+}
+
+// createStatements 生成建表语句及设置表注释的语句
+func (tb *tableBuilder) createStatements() []string {
 	dialect := NewDatabaseManager(tb.orm).GetDialect()
-	return dialect.CreateTableSQL(tb.tableName, tb.columns)
+	return createTableStatements(dialect, tb.tableName, tb.columns, tb.options)
 }
 
 // ToAlterSQLs 生成修改表SQL
@@ -0,0 +1,64 @@
+package orm
+
+// DefaultScopeInterface 声明默认作用域的模型接口，通过Model创建的查询会自动应用
+type DefaultScopeInterface interface {
+	DefaultScope(qb QueryBuilder) QueryBuilder
+}
+
+// Scope 应用可复用的查询作用域，如租户过滤、仅查询有效记录等
+func (qb *queryBuilder) Scope(scopes ...func(QueryBuilder) QueryBuilder) QueryBuilder {
+	var result QueryBuilder = qb
+	for _, scope := range scopes {
+		result = scope(result)
+	}
+	return result
+}
+
+// Unscoped 忽略模型的默认作用域和软删除：查询包含已删除的记录，Delete直接删除记录
+func (qb *queryBuilder) Unscoped() QueryBuilder {
+	qb.unscoped = true
+	return qb
+}
+
+// scoped 返回应用了模型默认作用域和软删除过滤的构建器副本，都没有时返回自身
+// 已有条件中包含OR或原始条件时先整体分组，避免默认条件被OR（包括原始条件中的OR）绕过
+func (qb *queryBuilder) scoped() *queryBuilder {
+	if qb.unscoped {
+		return qb
+	}
+	scoper, hasScope := qb.model.(DefaultScopeInterface)
+	softDelete, hasSoftDelete := softDeleteColumn(qb.model)
+	hasSoftDelete = hasSoftDelete && !qb.trashed
+	if !hasScope && !hasSoftDelete {
+		return qb
+	}
+
+	x := *qb
+	x.unscoped = true
+	x.conditions = append([]QueryCondition{}, qb.conditions...)
+	x.joins = append([]JoinClause{}, qb.joins...)
+	x.orders = append([]OrderClause{}, qb.orders...)
+	for _, condition := range qb.conditions {
+		if condition.Logic == "OR" || isRawCondition(condition) {
+			x.conditions = []QueryCondition{{Operator: "GROUP", Logic: "AND", Conditions: qb.conditions}}
+			break
+		}
+	}
+
+	if hasSoftDelete {
+		x.WhereNull(x.softDeleteCondition(softDelete))
+	}
+	if !hasScope {
+		return &x
+	}
+	if result, ok := scoper.DefaultScope(&x).(*queryBuilder); ok {
+		return result
+	}
+	return &x
+}
+
+// isRawCondition 是否为Where传入的原始条件，其中可能包含OR
+func isRawCondition(condition QueryCondition) bool {
+	_, ok := condition.Value.([]interface{})
+	return ok && condition.Operator == "="
+}
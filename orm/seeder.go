@@ -0,0 +1,162 @@
+package orm
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeedManager 数据填充管理器，已执行的填充记录在seeds表中，重复运行时跳过
+type SeedManager struct {
+	orm         *ORM
+	seeders     []Seeder
+	environment string
+}
+
+// NewSeedManager 创建数据填充管理器，当前环境默认取Config.Environment
+func NewSeedManager(orm *ORM) *SeedManager {
+	return &SeedManager{
+		orm:         orm,
+		seeders:     make([]Seeder, 0),
+		environment: orm.config.Environment,
+	}
+}
+
+// AddSeeder 添加数据填充，按添加顺序执行
+func (sm *SeedManager) AddSeeder(seeders ...Seeder) {
+	sm.seeders = append(sm.seeders, seeders...)
+}
+
+// Environment 设置当前环境，只执行未限定环境或限定环境包含当前环境的填充
+func (sm *SeedManager) Environment(environment string) *SeedManager {
+	sm.environment = environment
+	return sm
+}
+
+// Run 依次执行未执行过的数据填充，每个填充与其执行记录在同一事务中提交
+func (sm *SeedManager) Run() error {
+	if err := sm.ensureSeedTable(); err != nil {
+		return err
+	}
+
+	executed, err := sm.getExecutedSeeds()
+	if err != nil {
+		return err
+	}
+
+	dialect := NewDatabaseManager(sm.orm).GetDialect()
+	for _, seeder := range sm.seeders {
+		name := seeder.Name()
+		if _, exists := executed[name]; exists || !sm.matchEnvironment(seeder) {
+			continue
+		}
+
+		fmt.Printf("运行数据填充: %s\n", name)
+		err := NewTransactionManager(sm.orm).WithTransaction(func(tx Tx) error {
+			if err := seeder.Run(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(rebind(dialect, "INSERT INTO seeds (name) VALUES (?)"), name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("数据填充 %s 失败: %w", name, err)
+		}
+		executed[name] = time.Now()
+	}
+	return nil
+}
+
+// matchEnvironment 填充是否在当前环境执行，限定了环境的填充在未设置环境时不执行
+func (sm *SeedManager) matchEnvironment(seeder Seeder) bool {
+	restricted, ok := seeder.(SeederEnvironments)
+	if !ok {
+		return true
+	}
+	environments := restricted.Environments()
+	if len(environments) == 0 {
+		return true
+	}
+	for _, environment := range environments {
+		if environment == sm.environment {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSeedTable 确保填充记录表存在
+func (sm *SeedManager) ensureSeedTable() error {
+	dialect := NewDatabaseManager(sm.orm).GetDialect()
+
+	sql := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			%s VARCHAR(255) PRIMARY KEY,
+			%s TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+		dialect.Quote("seeds"),
+		dialect.Quote("name"),
+		dialect.Quote("executed_at"),
+	)
+
+	_, err := sm.orm.Exec(sql)
+	return err
+}
+
+// getExecutedSeeds 获取已执行的填充
+func (sm *SeedManager) getExecutedSeeds() (map[string]time.Time, error) {
+	executed := make(map[string]time.Time)
+
+	rows, err := sm.orm.Query("SELECT name, executed_at FROM seeds")
+	if err != nil {
+		return executed, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var executedAt time.Time
+
+		if err := rows.Scan(&name, &executedAt); err != nil {
+			return executed, err
+		}
+
+		executed[name] = executedAt
+	}
+
+	return executed, rows.Err()
+}
+
+// funcSeeder 以函数定义的数据填充
+type funcSeeder struct {
+	name         string
+	run          func(tx Tx) error
+	environments []string
+}
+
+// NewSeeder 以函数创建数据填充，environments为空时在所有环境执行
+func NewSeeder(name string, run func(tx Tx) error, environments ...string) Seeder {
+	return &funcSeeder{name: name, run: run, environments: environments}
+}
+
+// Name 获取填充名称
+func (s *funcSeeder) Name() string {
+	return s.name
+}
+
+// Run 执行填充
+func (s *funcSeeder) Run(tx Tx) error {
+	return s.run(tx)
+}
+
+// Environments 获取填充限定的环境
+func (s *funcSeeder) Environments() []string {
+	return s.environments
+}
+
+// Seed 使用全局ORM执行数据填充
+func Seed(seeders ...Seeder) error {
+	sm := NewSeedManager(GetGlobalORM())
+	sm.AddSeeder(seeders...)
+	return sm.Run()
+}
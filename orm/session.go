@@ -0,0 +1,52 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Session 携带上下文的ORM会话
+type Session struct {
+	orm *ORM
+	ctx context.Context
+}
+
+// Context 获取会话上下文
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Table 创建携带上下文的查询构建器
+func (s *Session) Table(tableName string) QueryBuilder {
+	return s.orm.Table(tableName).WithContext(s.ctx)
+}
+
+// Model 基于模型创建携带上下文的查询构建器
+func (s *Session) Model(model interface{}) QueryBuilder {
+	return s.orm.Model(model).WithContext(s.ctx)
+}
+
+// Query 执行查询
+func (s *Session) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.orm.QueryContext(s.ctx, query, args...)
+}
+
+// QueryRow 执行单行查询
+func (s *Session) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.orm.QueryRowContext(s.ctx, query, args...)
+}
+
+// Exec 执行SQL语句
+func (s *Session) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.orm.ExecContext(s.ctx, query, args...)
+}
+
+// Begin 开始事务，事务中的操作沿用会话上下文
+func (s *Session) Begin() (Tx, error) {
+	return s.orm.BeginTx(s.ctx, nil)
+}
+
+// WithTransaction 在沿用会话上下文的事务中执行函数，上下文取消时事务回滚
+func (s *Session) WithTransaction(fn func(tx Tx) error) error {
+	return NewTransactionManager(s.orm).WithTransactionContext(s.ctx, nil, fn)
+}
@@ -0,0 +1,76 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var nullTimeType = reflect.TypeOf(sql.NullTime{})
+
+// isSoftDeleteField 判断字段是否为软删除字段
+// 标记soft_delete的字段，或列名为deleted_at且类型为*time.Time、sql.NullTime的字段
+func isSoftDeleteField(field *modelField) bool {
+	if field.tag.SoftDelete {
+		return true
+	}
+	if field.column != "deleted_at" {
+		return false
+	}
+	return field.field.Type == timePtrType || field.field.Type == nullTimeType
+}
+
+// softDeleteColumn 获取模型的软删除列名
+func softDeleteColumn(model interface{}) (string, bool) {
+	t := structType(model)
+	if t == nil {
+		return "", false
+	}
+	if field := metadataOf(t).softDelete; field != nil {
+		return field.column, true
+	}
+	return "", false
+}
+
+// softDeleteColumn 获取需要按软删除处理的列名，Unscoped时返回false
+func (qb *queryBuilder) softDeleteColumn() (string, bool) {
+	if qb.unscoped {
+		return "", false
+	}
+	return softDeleteColumn(qb.model)
+}
+
+// softDeleteCondition 未删除记录的过滤列，存在JOIN时加上表名前缀避免列名歧义
+func (qb *queryBuilder) softDeleteCondition(column string) string {
+	if len(qb.joins) == 0 {
+		return column
+	}
+	fields := strings.Fields(qb.tableName)
+	return fields[len(fields)-1] + "." + column
+}
+
+// Restore 恢复软删除的记录，未设置条件时按模型的主键值恢复
+func (qb *queryBuilder) Restore() error {
+	_, err := qb.RestoreAffected()
+	return err
+}
+
+// RestoreAffected 恢复软删除的记录并返回受影响的行数
+func (qb *queryBuilder) RestoreAffected() (int64, error) {
+	column, ok := softDeleteColumn(qb.model)
+	if !ok {
+		return 0, fmt.Errorf("模型 %T 没有软删除字段", qb.model)
+	}
+
+	restore := *qb.byPrimaryKey(qb.model)
+	restore.trashed = true
+	query, args := restore.buildSetSQL([]string{column}, []interface{}{nil})
+	return restore.execAffected(query, args...)
+}
+
+// buildSoftDeleteSQL 构建软删除的UPDATE SQL，只更新尚未删除的记录
+func (qb *queryBuilder) buildSoftDeleteSQL(column string) (string, []interface{}) {
+	return qb.buildSetSQL([]string{column}, []interface{}{time.Now()})
+}
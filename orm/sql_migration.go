@@ -0,0 +1,342 @@
+package orm
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	upMigrationSuffix   = ".up.sql"
+	downMigrationSuffix = ".down.sql"
+)
+
+// SQLMigration 以SQL文本定义的迁移
+type SQLMigration struct {
+	version string
+	upSQL   string
+	downSQL string
+	orm     *ORM
+}
+
+// NewSQLMigration 创建SQL迁移，upSQL和downSQL可包含以分号分隔的多条语句
+// 通过MigrationManager添加时使用管理器的连接，否则使用全局ORM
+func NewSQLMigration(version, upSQL, downSQL string) *SQLMigration {
+	return &SQLMigration{
+		version: version,
+		upSQL:   upSQL,
+		downSQL: downSQL,
+	}
+}
+
+// Version 获取版本
+func (m *SQLMigration) Version() string {
+	return m.version
+}
+
+// Up 执行上迁移SQL
+func (m *SQLMigration) Up() error {
+	return m.run(m.upSQL)
+}
+
+// UpStatements 获取上迁移拆分后的SQL语句
+func (m *SQLMigration) UpStatements() []string {
+	return splitSQLStatements(m.upSQL)
+}
+
+// Down 执行下迁移SQL，未提供下迁移时返回错误
+func (m *SQLMigration) Down() error {
+	if strings.TrimSpace(m.downSQL) == "" {
+		return fmt.Errorf("迁移 %s 未提供下迁移SQL", m.version)
+	}
+	return m.run(m.downSQL)
+}
+
+// run 在事务中依次执行SQL语句
+func (m *SQLMigration) run(script string) error {
+	db := m.orm
+	if db == nil {
+		db = GetGlobalORM()
+	}
+	if db == nil {
+		return fmt.Errorf("迁移 %s 没有可用的数据库连接", m.version)
+	}
+
+	return NewTransactionManager(db).WithTransaction(func(tx Tx) error {
+		for _, statement := range splitSQLStatements(script) {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("执行语句失败: %w\n%s", err, statement)
+			}
+		}
+		return nil
+	})
+}
+
+// LoadSQLMigrations 从文件系统目录加载SQL迁移文件，支持os.DirFS和go:embed的embed.FS
+// 文件命名为 <版本>.up.sql 和 <版本>.down.sql，例如 20240101120000_create_users.up.sql
+func (mm *MigrationManager) LoadSQLMigrations(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+
+	migrations := make(map[string]*SQLMigration)
+	var versions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		var version string
+		var up bool
+		switch {
+		case strings.HasSuffix(name, upMigrationSuffix):
+			version, up = strings.TrimSuffix(name, upMigrationSuffix), true
+		case strings.HasSuffix(name, downMigrationSuffix):
+			version = strings.TrimSuffix(name, downMigrationSuffix)
+		default:
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("读取迁移文件 %s 失败: %w", name, err)
+		}
+
+		migration, exists := migrations[version]
+		if !exists {
+			migration = NewSQLMigration(version, "", "")
+			migrations[version] = migration
+			versions = append(versions, version)
+		}
+		if up {
+			migration.upSQL = string(content)
+		} else {
+			migration.downSQL = string(content)
+		}
+	}
+
+	for _, version := range versions {
+		migration := migrations[version]
+		if strings.TrimSpace(migration.upSQL) == "" {
+			return fmt.Errorf("迁移 %s 缺少上迁移文件 %s%s", version, version, upMigrationSuffix)
+		}
+		mm.AddMigration(migration)
+	}
+	return nil
+}
+
+// LoadSQLMigrationsFromDir 从本地目录加载SQL迁移文件
+func (mm *MigrationManager) LoadSQLMigrationsFromDir(dir string) error {
+	return mm.LoadSQLMigrations(os.DirFS(dir), ".")
+}
+
+// CreateSQLMigrationFiles 在目录下创建以当前时间为版本前缀的空迁移文件，返回上、下迁移文件路径
+func CreateSQLMigrationFiles(dir, name string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", fmt.Errorf("迁移名称不能为空")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("创建迁移目录失败: %w", err)
+	}
+
+	version := time.Now().Format("20060102150405") + "_" + camelToSnake(strings.ReplaceAll(name, " ", "_"))
+	upPath := filepath.Join(dir, version+upMigrationSuffix)
+	downPath := filepath.Join(dir, version+downMigrationSuffix)
+	for _, file := range []string{upPath, downPath} {
+		if _, err := os.Stat(file); err == nil {
+			return "", "", fmt.Errorf("迁移文件已存在: %s", file)
+		}
+		content := fmt.Sprintf("-- %s\n", filepath.Base(file))
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			return "", "", fmt.Errorf("写入迁移文件失败: %w", err)
+		}
+	}
+	return upPath, downPath, nil
+}
+
+// noSplitDirective 单独成行时整个脚本作为一条语句执行，用于拆分规则无法处理的存储过程等脚本
+const noSplitDirective = "-- orm:no-split"
+
+// splitSQLStatements 按分号拆分SQL脚本，忽略引号、PostgreSQL美元符号引用（$$ … $$）和注释内的分号
+// 触发器、存储过程等定义中 BEGIN … END 块内的分号不拆分；MySQL条件注释（/*! … */）和优化器提示（/*+ … */）保留在语句中
+func splitSQLStatements(script string) []string {
+	for _, line := range strings.Split(script, "\n") {
+		if strings.TrimSpace(line) == noSplitDirective {
+			if statement := strings.TrimSpace(script); statement != "" {
+				return []string{statement}
+			}
+			return nil
+		}
+	}
+
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	var dollarTag string
+	lineComment, blockComment, keepComment := false, false, false
+	depth := 0
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case lineComment:
+			if r == '\n' {
+				lineComment = false
+				current.WriteRune(r)
+			}
+			continue
+		case blockComment:
+			if keepComment {
+				current.WriteRune(r)
+			}
+			if r == '*' && next == '/' {
+				if keepComment {
+					current.WriteRune(next)
+				}
+				blockComment = false
+				i++
+			}
+			continue
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			continue
+		case dollarTag != "":
+			if strings.HasPrefix(string(runes[i:]), dollarTag) {
+				current.WriteString(dollarTag)
+				i += len([]rune(dollarTag)) - 1
+				dollarTag = ""
+			} else {
+				current.WriteRune(r)
+			}
+			continue
+		}
+
+		switch {
+		case r == '-' && next == '-':
+			lineComment = true
+			i++
+		case r == '/' && next == '*':
+			blockComment = true
+			keepComment = i+2 < len(runes) && (runes[i+2] == '!' || runes[i+2] == '+')
+			if keepComment {
+				current.WriteString("/*")
+			}
+			i++
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '$' && (i == 0 || !isIdentRune(runes[i-1])):
+			if tag := dollarQuoteTag(runes[i:]); tag != "" {
+				dollarTag = tag
+				current.WriteString(tag)
+				i += len([]rune(tag)) - 1
+			} else {
+				current.WriteRune(r)
+			}
+		case isIdentRune(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			end := i
+			for end < len(runes) && isIdentRune(runes[end]) {
+				end++
+			}
+			switch strings.ToUpper(string(runes[i:end])) {
+			case "BEGIN":
+				if isRoutineDefinition(current.String()) {
+					depth++
+				}
+			case "CASE":
+				depth++
+			case "END":
+				// END IF、END LOOP等结束流程控制，END CASE与END一样结束CASE块
+				next, length := nextWord(runes[end:])
+				switch next {
+				case "IF", "LOOP", "WHILE", "REPEAT":
+				case "CASE":
+					end += length
+					fallthrough
+				default:
+					if depth > 0 {
+						depth--
+					}
+				}
+			}
+			current.WriteString(string(runes[i:end]))
+			i = end - 1
+		case r == ';' && depth == 0:
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// isIdentRune 判断字符是否可以出现在标识符或关键字中
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// dollarQuoteTag 解析PostgreSQL美元符号引用的定界符，如 $$ 或 $body$，不是定界符时（如 $1 占位符）返回空
+func dollarQuoteTag(runes []rune) string {
+	for i := 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '$':
+			return string(runes[:i+1])
+		case r == '_' || unicode.IsLetter(r) || (i > 1 && unicode.IsDigit(r)):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// isRoutineDefinition 判断语句是否为触发器、存储过程、函数或事件的定义，其中的 BEGIN 开始语句块而非事务
+func isRoutineDefinition(statement string) bool {
+	fields := strings.Fields(strings.ToUpper(statement))
+	if len(fields) == 0 || fields[0] != "CREATE" {
+		return false
+	}
+	for _, field := range fields[1:] {
+		switch field {
+		case "TRIGGER", "PROCEDURE", "FUNCTION", "EVENT":
+			return true
+		}
+	}
+	return false
+}
+
+// nextWord 跳过空白后读取下一个单词，返回其大写形式和包含空白在内的长度
+func nextWord(runes []rune) (string, int) {
+	start := 0
+	for start < len(runes) && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	end := start
+	for end < len(runes) && isIdentRune(runes[end]) {
+		end++
+	}
+	return strings.ToUpper(string(runes[start:end])), end
+}
@@ -0,0 +1,219 @@
+package orm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+// StmtCacheStats 预处理语句缓存的统计信息
+type StmtCacheStats struct {
+	Size      int    // 当前缓存的语句数
+	Capacity  int    // 缓存容量
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中（新预处理）次数
+	Evictions uint64 // 因容量淘汰或连接失效而移除的次数
+}
+
+// cachedStmt 缓存的预处理语句，refs记录正在使用的调用方数量
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// stmtCache 按SQL缓存预处理语句，超出容量时淘汰最久未使用的语句
+// sql.Stmt 由连接池管理，在新连接上首次执行时自动重新预处理
+type stmtCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// newStmtCache 创建指定容量的预处理语句缓存
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// cacheable 是否缓存该SQL，只缓存单条增删改查语句，DDL和多语句脚本直接执行
+func cacheable(query string) bool {
+	switch sqlOperation(query) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE":
+	default:
+		return false
+	}
+	return !strings.Contains(strings.TrimRight(strings.TrimSpace(query), ";"), ";")
+}
+
+// acquire 获取SQL对应的预处理语句，未缓存时预处理并加入缓存
+// 使用完毕后必须调用release，被淘汰的语句在最后一个使用者释放后关闭
+func (c *stmtCache) acquire(ctx context.Context, db *sql.DB, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if elem, ok := c.items[query]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*cachedStmt)
+		entry.refs++
+		c.hits++
+		c.mu.Unlock()
+		return entry, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 并发预处理同一SQL时保留先加入缓存的语句
+	if elem, ok := c.items[query]; ok {
+		stmt.Close()
+		entry := elem.Value.(*cachedStmt)
+		entry.refs++
+		return entry, nil
+	}
+
+	entry := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+	return entry, nil
+}
+
+// release 释放语句的使用，err为连接层面的错误时将语句移出缓存
+func (c *stmtCache) release(entry *cachedStmt, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil && isConnectionError(err) {
+		if elem, ok := c.items[entry.query]; ok && elem.Value == entry {
+			c.removeElement(elem)
+		}
+	}
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// removeElement 将语句移出缓存，没有使用者时立即关闭，调用方需持有锁
+func (c *stmtCache) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cachedStmt)
+	delete(c.items, entry.query)
+	entry.evicted = true
+	c.evictions++
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// clear 关闭并移除所有缓存的语句
+func (c *stmtCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.order.Len() > 0 {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// stats 获取缓存统计信息
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return StmtCacheStats{
+		Size:      c.order.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// StmtCacheStats 获取预处理语句缓存的统计信息，未开启缓存时返回零值
+func (o *ORM) StmtCacheStats() StmtCacheStats {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.stmts == nil {
+		return StmtCacheStats{}
+	}
+	return o.stmts.stats()
+}
+
+// resetStmtCache 按配置重建预处理语句缓存，调用方需持有写锁
+func (o *ORM) resetStmtCache() {
+	if o.stmts != nil {
+		o.stmts.clear()
+		o.stmts = nil
+	}
+	if o.config.StmtCacheSize > 0 {
+		o.stmts = newStmtCache(o.config.StmtCacheSize)
+	}
+}
+
+// primary 获取主库连接和预处理语句缓存，只在读取时持有读锁，执行和重试等待期间不阻塞Connect和Close
+func (o *ORM) primary() (*sql.DB, *stmtCache) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.db, o.stmts
+}
+
+// dbQuery 在主库执行查询，开启缓存时使用缓存的预处理语句
+func dbQuery(ctx context.Context, db *sql.DB, stmts *stmtCache, query string, args []interface{}) (*sql.Rows, error) {
+	if stmts == nil || !cacheable(query) {
+		return db.QueryContext(ctx, query, args...)
+	}
+	entry, err := stmts.acquire(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := entry.stmt.QueryContext(ctx, args...)
+	stmts.release(entry, err)
+	return rows, err
+}
+
+// dbQueryRow 在主库执行单行查询，开启缓存时使用缓存的预处理语句
+func dbQueryRow(ctx context.Context, db *sql.DB, stmts *stmtCache, query string, args []interface{}) *sql.Row {
+	if stmts == nil || !cacheable(query) {
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	entry, err := stmts.acquire(ctx, db, query)
+	if err != nil {
+		// 预处理失败时直接执行，由sql.Row携带错误
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	row := entry.stmt.QueryRowContext(ctx, args...)
+	stmts.release(entry, row.Err())
+	return row
+}
+
+// dbExec 在主库执行SQL语句，开启缓存时使用缓存的预处理语句
+func dbExec(ctx context.Context, db *sql.DB, stmts *stmtCache, query string, args []interface{}) (sql.Result, error) {
+	if stmts == nil || !cacheable(query) {
+		return db.ExecContext(ctx, query, args...)
+	}
+	entry, err := stmts.acquire(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	result, err := entry.stmt.ExecContext(ctx, args...)
+	stmts.release(entry, err)
+	return result, err
+}
@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// timeLayouts 解析时间字符串时依次尝试的格式，覆盖MySQL、PostgreSQL、SQLite和SQL Server的常见输出
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// loadLocation 解析Config.Timezone，为空时使用本地时区
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 %s: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// location 获取解析时间使用的时区
+func (o *ORM) location() *time.Location {
+	if o == nil {
+		return time.Local
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.loc == nil {
+		return time.Local
+	}
+	return o.loc
+}
+
+// isTimeField 字段是否为time.Time或*time.Time
+func isTimeField(t reflect.Type) bool {
+	return t == timeType || t == timePtrType
+}
+
+// parseTimeValue 将驱动返回的值转换为时间
+// 支持time.Time、多种格式的时间字符串以及秒或毫秒级的Unix时间戳，不带时区的值按loc解析
+func parseTimeValue(src interface{}, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	switch v := src.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		if v.IsZero() {
+			return v, nil
+		}
+		return v.In(loc), nil
+	case int64:
+		return unixTime(float64(v), loc), nil
+	case float64:
+		return unixTime(v, loc), nil
+	case []byte:
+		return parseTimeString(string(v), loc)
+	case string:
+		return parseTimeString(v, loc)
+	}
+	return time.Time{}, fmt.Errorf("无法将 %T 转换为时间", src)
+}
+
+// parseTimeString 解析时间字符串，纯数字按Unix时间戳处理
+func parseTimeString(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, nil
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return unixTime(n, loc), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间: %s", s)
+}
+
+// unixTime 将Unix时间戳转换为时间，绝对值不小于1e12时视为毫秒
+func unixTime(n float64, loc *time.Location) time.Time {
+	if math.Abs(n) >= 1e12 {
+		return time.UnixMilli(int64(n)).In(loc)
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*1e9)).In(loc)
+}
+
+// timeAssigner 返回时间字段的扫描目标及扫描后的转换函数，NULL写入零值或nil
+func timeAssigner(field reflect.Value, name string, loc *time.Location) (interface{}, fieldAssigner) {
+	var raw interface{}
+	return &raw, func() error {
+		if raw == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+
+		t, err := parseTimeValue(raw, loc)
+		if err != nil {
+			return fmt.Errorf("列 %s: %w", name, err)
+		}
+		if field.Type() == timePtrType {
+			field.Set(reflect.ValueOf(&t))
+		} else {
+			field.Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+}
@@ -3,38 +3,138 @@ package orm
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// IsolationLevel 事务隔离级别
+type IsolationLevel = sql.IsolationLevel
+
+// 常用的事务隔离级别，LevelDefault使用数据库的默认级别
+const (
+	LevelDefault         = sql.LevelDefault
+	LevelReadUncommitted = sql.LevelReadUncommitted
+	LevelReadCommitted   = sql.LevelReadCommitted
+	LevelRepeatableRead  = sql.LevelRepeatableRead
+	LevelSerializable    = sql.LevelSerializable
 )
 
 // transaction 事务实现
 type transaction struct {
-	tx *sql.Tx
+	tx      *sql.Tx
+	orm     *ORM
+	ctx     context.Context
+	written *writtenTables
+	// depth 嵌套事务的层数，外层事务为0
+	depth int
+}
+
+// context 获取事务上下文
+func (t *transaction) context() context.Context {
+	if t.ctx != nil {
+		return t.ctx
+	}
+	return context.Background()
 }
 
 // Query 执行查询
 func (t *transaction) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return t.tx.Query(query, args...)
+	return t.QueryContext(t.context(), query, args...)
+}
+
+// QueryContext 执行带上下文的查询
+func (t *transaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = t.orm.bindNamedSQL(query, args)
+	return t.orm.observeQuery(ctx, true, query, args, func() (*sql.Rows, error) {
+		return t.tx.QueryContext(ctx, query, args...)
+	})
 }
 
 // QueryRow 执行单行查询
 func (t *transaction) QueryRow(query string, args ...interface{}) *sql.Row {
-	return t.tx.QueryRow(query, args...)
+	return t.QueryRowContext(t.context(), query, args...)
+}
+
+// QueryRowContext 执行带上下文的单行查询
+func (t *transaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = t.orm.bindNamedSQL(query, args)
+	return t.orm.observeQueryRow(ctx, true, query, args, func() *sql.Row {
+		return t.tx.QueryRowContext(ctx, query, args...)
+	})
 }
 
 // Exec 执行SQL语句
 func (t *transaction) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return t.tx.Exec(query, args...)
+	return t.ExecContext(t.context(), query, args...)
+}
+
+// ExecContext 执行带上下文的SQL语句
+func (t *transaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = t.orm.bindNamedSQL(query, args)
+	return t.orm.observeExec(ctx, true, query, args, func() (sql.Result, error) {
+		return t.tx.ExecContext(ctx, query, args...)
+	})
 }
 
 // Commit 提交事务
 func (t *transaction) Commit() error {
-	return t.tx.Commit()
+	if t.depth > 0 {
+		return fmt.Errorf("嵌套事务随外层事务一起提交，不能单独提交")
+	}
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+	// 提交前其他连接仍读到旧数据，提交后再使事务中写入的表的查询缓存失效
+	if t.written != nil {
+		t.orm.InvalidateCache(t.written.drain()...)
+	}
+	return nil
 }
 
 // Rollback 回滚事务
 func (t *transaction) Rollback() error {
+	if t.depth > 0 {
+		return fmt.Errorf("嵌套事务不能单独回滚，返回错误即可回滚到保存点")
+	}
 	return t.tx.Rollback()
 }
 
+// WithContext 返回使用指定上下文的事务
+func (t *transaction) WithContext(ctx context.Context) Tx {
+	return &transaction{tx: t.tx, orm: t.orm, ctx: ctx, written: t.written, depth: t.depth}
+}
+
+// WithTransaction 在当前事务中创建保存点作为嵌套事务执行函数
+// fn返回错误或panic时只回滚到保存点，外层事务可以继续执行；fn成功时释放保存点，随外层事务一起提交
+func (t *transaction) WithTransaction(fn func(tx Tx) error) error {
+	nested := &transaction{tx: t.tx, orm: t.orm, ctx: t.ctx, written: t.written, depth: t.depth + 1}
+	sp, err := NewSavePoint(t, fmt.Sprintf("orm_savepoint_%d", nested.depth))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			sp.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(nested); err != nil {
+		sp.Rollback()
+		return err
+	}
+
+	return sp.Release()
+}
+
 // Table 在事务中创建查询构建器
 func (t *transaction) Table(tableName string) QueryBuilder {
 	return NewTransactionQueryBuilder(t, tableName)
@@ -42,18 +142,10 @@ func (t *transaction) Table(tableName string) QueryBuilder {
 
 // Model 在事务中基于模型创建查询构建器
 func (t *transaction) Model(model interface{}) QueryBuilder {
-	tableName := getTableNameFromModel(model)
-	return NewTransactionQueryBuilder(t, tableName)
-}
-
-// getTableNameFromModel 从模型获取表名
-func getTableNameFromModel(model interface{}) string {
-	if m, ok := model.(ModelInterface); ok {
-		return m.TableName()
-	}
-
-	// 使用反射获取结构体名称并转换为表名
-	return camelToSnake(getStructName(model))
+	tableName := t.orm.getTableName(model)
+	qb := NewTransactionQueryBuilder(t, tableName).(*queryBuilder)
+	qb.model = model
+	return qb
 }
 
 // TransactionManager 事务管理器
@@ -68,31 +160,44 @@ func NewTransactionManager(orm *ORM) *TransactionManager {
 
 // WithTransaction 在事务中执行函数
 func (tm *TransactionManager) WithTransaction(fn func(tx Tx) error) error {
-	tx, err := tm.orm.Begin()
-	if err != nil {
-		return err
-	}
+	return tm.WithTransactionContext(context.Background(), nil, fn)
+}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			panic(r)
+// WithTransactionOpts 以指定的隔离级别和只读模式在事务中执行函数
+func (tm *TransactionManager) WithTransactionOpts(isolation IsolationLevel, readOnly bool, fn func(tx Tx) error) error {
+	return tm.WithTransactionContext(context.Background(), &sql.TxOptions{Isolation: isolation, ReadOnly: readOnly}, fn)
+}
+
+// WithTransactionContext 在带上下文的事务中执行函数
+// 配置了TxRetries时，遇到序列化失败或死锁会回滚并按指数退避重新执行fn，fn应当可以安全地重复执行；
+// 开启事务时遇到连接错误也会重试，此时fn尚未执行
+func (tm *TransactionManager) WithTransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx Tx) error) error {
+	backoff := tm.orm.config.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		begun, err := tm.runTransaction(ctx, opts, fn)
+		if err == nil || attempt >= tm.orm.config.TxRetries {
+			return err
+		}
+		if !isSerializationFailure(err) && (begun || !isConnectionError(err)) {
+			return err
 		}
-	}()
 
-	if err := fn(tx); err != nil {
-		tx.Rollback()
-		return err
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
 	}
-
-	return tx.Commit()
 }
 
-// WithTransactionContext 在带上下文的事务中执行函数
-func (tm *TransactionManager) WithTransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx Tx) error) error {
+// runTransaction 开启事务执行函数，出错或panic时回滚，begun表示事务是否已开启
+func (tm *TransactionManager) runTransaction(ctx context.Context, opts *sql.TxOptions, fn func(tx Tx) error) (begun bool, err error) {
 	tx, err := tm.orm.BeginTx(ctx, opts)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	defer func() {
@@ -104,40 +209,104 @@ func (tm *TransactionManager) WithTransactionContext(ctx context.Context, opts *
 
 	if err := fn(tx); err != nil {
 		tx.Rollback()
-		return err
+		return true, err
 	}
 
-	return tx.Commit()
+	return true, tx.Commit()
+}
+
+// serializationFailureMessages 各数据库序列化失败和死锁错误的特征文本
+var serializationFailureMessages = []string{
+	"could not serialize access", // PostgreSQL 40001
+	"deadlock detected",          // PostgreSQL 40P01
+	"deadlock found",             // MySQL 1213
+	"was deadlocked",             // SQL Server 1205
+	"update conflict",            // SQL Server 3960 快照隔离更新冲突
+	"database is locked",         // SQLite SQLITE_BUSY
+}
+
+// isSerializationFailure 判断是否为可以通过重试事务解决的并发冲突错误
+// 优先按驱动的错误码判断，其他驱动或包装后丢失类型的错误按错误信息判断
+func isSerializationFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// 40001 serialization_failure，40P01 deadlock_detected
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// 1213 ER_LOCK_DEADLOCK
+		return mysqlErr.Number == 1213
+	}
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		// 1205 死锁牺牲品，3960 快照隔离更新冲突
+		return mssqlErr.Number == 1205 || mssqlErr.Number == 3960
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, text := range serializationFailureMessages {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError 判断是否为重试后可能成功的临时错误：连接错误、序列化失败和死锁
+func isTransientError(err error) bool {
+	return isConnectionError(err) || isSerializationFailure(err)
 }
 
 // SavePoint 保存点
 type SavePoint struct {
-	name string
-	tx   Tx
+	name   string
+	tx     Tx
+	dbType DatabaseType
 }
 
 // NewSavePoint 创建保存点
 func NewSavePoint(tx Tx, name string) (*SavePoint, error) {
+	sp := &SavePoint{name: name, tx: tx}
+	if t, ok := tx.(*transaction); ok {
+		sp.dbType = t.orm.config.Type
+	}
+
 	// 注意：不是所有数据库都支持保存点
-	_, err := tx.Exec("SAVEPOINT " + name)
-	if err != nil {
+	statement := "SAVEPOINT " + name
+	if sp.dbType == SQLServer {
+		statement = "SAVE TRANSACTION " + name
+	}
+	if _, err := tx.Exec(statement); err != nil {
 		return nil, err
 	}
 
-	return &SavePoint{
-		name: name,
-		tx:   tx,
-	}, nil
+	return sp, nil
 }
 
 // Rollback 回滚到保存点
 func (sp *SavePoint) Rollback() error {
-	_, err := sp.tx.Exec("ROLLBACK TO SAVEPOINT " + sp.name)
+	statement := "ROLLBACK TO SAVEPOINT " + sp.name
+	if sp.dbType == SQLServer {
+		statement = "ROLLBACK TRANSACTION " + sp.name
+	}
+	_, err := sp.tx.Exec(statement)
 	return err
 }
 
-// Release 释放保存点
+// Release 释放保存点，SQL Server和Oracle不支持释放保存点，保存点在事务结束时失效
 func (sp *SavePoint) Release() error {
+	if sp.dbType == SQLServer || sp.dbType == Oracle {
+		return nil
+	}
 	_, err := sp.tx.Exec("RELEASE SAVEPOINT " + sp.name)
 	return err
 }
@@ -150,6 +319,12 @@ func WithTransaction(fn func(tx Tx) error) error {
 	return tm.WithTransaction(fn)
 }
 
+// WithTransactionOpts 以指定的隔离级别和只读模式在事务中执行函数
+func WithTransactionOpts(isolation IsolationLevel, readOnly bool, fn func(tx Tx) error) error {
+	tm := NewTransactionManager(GetGlobalORM())
+	return tm.WithTransactionOpts(isolation, readOnly, fn)
+}
+
 // WithTransactionContext 在带上下文的事务中执行函数
 func WithTransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx Tx) error) error {
 	tm := NewTransactionManager(GetGlobalORM())
@@ -14,6 +14,8 @@ const (
 	PostgreSQL DatabaseType = "postgres"
 	SQLite     DatabaseType = "sqlite3"
 	SQLServer  DatabaseType = "sqlserver"
+	ClickHouse DatabaseType = "clickhouse"
+	Oracle     DatabaseType = "oracle"
 )
 
 // Config 数据库配置
@@ -30,6 +32,41 @@ type Config struct {
 	MaxOpenConns int           `json:"max_open_conns" yaml:"max_open_conns"`
 	MaxIdleConns int           `json:"max_idle_conns" yaml:"max_idle_conns"`
 	MaxLifetime  time.Duration `json:"max_lifetime" yaml:"max_lifetime"`
+	BatchSize    int           `json:"batch_size" yaml:"batch_size"` // 批量插入分块大小
+
+	// 表名：未实现TableName()的模型按命名策略（默认SnakeCaseNaming）由结构体名生成表名，并加上TablePrefix
+	TablePrefix    string         `json:"table_prefix" yaml:"table_prefix"`
+	NamingStrategy NamingStrategy `json:"-" yaml:"-"`
+
+	// 自动时间戳列名，为空时使用 created_at / updated_at
+	CreatedAtColumn string `json:"created_at_column" yaml:"created_at_column"`
+	UpdatedAtColumn string `json:"updated_at_column" yaml:"updated_at_column"`
+
+	// 当前环境（如 development、testing、production），用于选择执行的数据填充
+	Environment string `json:"environment" yaml:"environment"`
+
+	// 安全模式：AutoMigrate 只执行新增表、列和索引的语句，修改已有列的语句只打印不执行
+	MigrateSafeMode bool `json:"migrate_safe_mode" yaml:"migrate_safe_mode"`
+
+	// SQL日志：开启后通过logger模块记录执行的SQL，超过慢查询阈值的以WARN级别记录
+	LogQueries    bool          `json:"log_queries" yaml:"log_queries"`
+	SlowThreshold time.Duration `json:"slow_threshold" yaml:"slow_threshold"`
+	MaskQueryArgs bool          `json:"mask_query_args" yaml:"mask_query_args"`
+
+	// 只读副本：查询构建器的读操作轮询路由到副本，写操作和事务使用主库；未设置的字段沿用主库配置
+	Replicas []Config `json:"replicas" yaml:"replicas"`
+
+	// 连接健康检查与重试：Connect失败时重试；事务外的查询遇到连接错误、死锁或序列化失败时重试，写操作只在死锁或序列化失败时重试；等待时间从RetryBackoff（默认100ms）开始逐次加倍
+	HealthCheckInterval time.Duration `json:"health_check_interval" yaml:"health_check_interval"`
+	ConnectRetries      int           `json:"connect_retries" yaml:"connect_retries"`
+	QueryRetries        int           `json:"query_retries" yaml:"query_retries"`
+	RetryBackoff        time.Duration `json:"retry_backoff" yaml:"retry_backoff"`
+
+	// 事务重试：WithTransaction系列方法遇到序列化失败或死锁时重新执行整个事务函数的次数
+	TxRetries int `json:"tx_retries" yaml:"tx_retries"`
+
+	// 预处理语句缓存：按SQL缓存主库上的预处理语句，超出容量时淘汰最久未使用的语句；为0时不缓存
+	StmtCacheSize int `json:"stmt_cache_size" yaml:"stmt_cache_size"`
 }
 
 // DefaultConfig 返回默认配置
@@ -43,6 +80,7 @@ func DefaultConfig() *Config {
 		MaxOpenConns: 100,
 		MaxIdleConns: 10,
 		MaxLifetime:  time.Hour,
+		BatchSize:    500,
 	}
 }
 
@@ -57,6 +95,9 @@ type DB interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 
 	// 事务操作
 	Begin() (Tx, error)
@@ -71,10 +112,16 @@ type Tx interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	Commit() error
 	Rollback() error
+	WithContext(ctx context.Context) Tx
 	Table(tableName string) QueryBuilder
 	Model(model interface{}) QueryBuilder
+	RawQuery(query string, args ...interface{}) *RawStatement
+	WithTransaction(fn func(tx Tx) error) error
 }
 
 // ModelInterface 模型接口
@@ -82,49 +129,143 @@ type ModelInterface interface {
 	TableName() string
 }
 
+// TableOptionsInterface 声明表级选项（存储引擎、字符集、排序规则、表注释）的模型接口
+type TableOptionsInterface interface {
+	TableOptions() TableOptions
+}
+
 // QueryBuilder 查询构建器接口
 type QueryBuilder interface {
+	// 上下文
+	WithContext(ctx context.Context) QueryBuilder
+
 	// SELECT 操作
 	Select(columns ...string) QueryBuilder
+	SelectRaw(expression string, args ...interface{}) QueryBuilder
+	Distinct(columns ...string) QueryBuilder
+	SelectWindow(function string, over *Window, alias string) QueryBuilder
+	RowNumberOver(over *Window, alias string) QueryBuilder
+	RankOver(over *Window, alias string) QueryBuilder
 	From(table string) QueryBuilder
+	FromSub(sub QueryBuilder, alias string) QueryBuilder
 	Where(condition string, args ...interface{}) QueryBuilder
+	OrWhere(condition string, args ...interface{}) QueryBuilder
+	WhereGroup(fn func(qb QueryBuilder)) QueryBuilder
+	OrWhereGroup(fn func(qb QueryBuilder)) QueryBuilder
+	WhereMap(conditions map[string]interface{}) QueryBuilder
+	WhereStruct(model interface{}) QueryBuilder
 	WhereIn(column string, values ...interface{}) QueryBuilder
 	WhereNotIn(column string, values ...interface{}) QueryBuilder
+	WhereExists(sub QueryBuilder) QueryBuilder
+	WhereNotExists(sub QueryBuilder) QueryBuilder
 	WhereBetween(column string, start, end interface{}) QueryBuilder
 	WhereNull(column string) QueryBuilder
 	WhereNotNull(column string) QueryBuilder
 	OrderBy(column string, direction ...string) QueryBuilder
+	OrderByRaw(expression string) QueryBuilder
 	GroupBy(columns ...string) QueryBuilder
+	GroupByRaw(expression string, args ...interface{}) QueryBuilder
 	Having(condition string, args ...interface{}) QueryBuilder
+	HavingRaw(expression string, args ...interface{}) QueryBuilder
 	Limit(limit int) QueryBuilder
 	Offset(offset int) QueryBuilder
-	Join(table, condition string) QueryBuilder
-	LeftJoin(table, condition string) QueryBuilder
-	RightJoin(table, condition string) QueryBuilder
-	InnerJoin(table, condition string) QueryBuilder
+	Join(table, condition string, args ...interface{}) QueryBuilder
+	LeftJoin(table, condition string, args ...interface{}) QueryBuilder
+	RightJoin(table, condition string, args ...interface{}) QueryBuilder
+	InnerJoin(table, condition string, args ...interface{}) QueryBuilder
+	CrossJoin(table string) QueryBuilder
+	ForUpdate(options ...LockOption) QueryBuilder
+	ForShare(options ...LockOption) QueryBuilder
+	Union(other QueryBuilder) QueryBuilder
+	UnionAll(other QueryBuilder) QueryBuilder
+	With(name string, sub QueryBuilder) QueryBuilder
+	WithRecursive(name string, sub QueryBuilder) QueryBuilder
+
+	// 读写分离
+	UsePrimary() QueryBuilder
+	UseReplica() QueryBuilder
+	Cache(ttl time.Duration) QueryBuilder
+
+	// 作用域
+	Scope(scopes ...func(QueryBuilder) QueryBuilder) QueryBuilder
+	Unscoped() QueryBuilder
+
+	// 关联预加载
+	Preload(relation string, conditions ...func(QueryBuilder) QueryBuilder) QueryBuilder
+	Association(name string) *Association
 
 	// 执行查询
 	Get(dest interface{}) error
 	First(dest interface{}) error
 	Find(dest interface{}) error
-	Count() (int64, error)
+	FindByID(id interface{}, dest interface{}) error
+	FindByKey(dest interface{}, keys ...interface{}) error
+	Count(column ...string) (int64, error)
+	CountColumn(column string) (int64, error)
+	CountDistinct(column string) (int64, error)
 	Exists() (bool, error)
+	Sum(column string) (float64, error)
+	Avg(column string) (float64, error)
+	Min(column string) (float64, error)
+	Max(column string) (float64, error)
+	Pluck(column string, dest interface{}) error
+	GetMaps() ([]map[string]interface{}, error)
+	FirstMap() (map[string]interface{}, error)
+	Paginate(page, perPage int, dest interface{}) (*Pagination, error)
+	Chunk(size int, dest interface{}, fn func() error) error
+	FindInBatches(dest interface{}, batchSize int, fn func(tx Tx, batch int) error) error
+	Cursor() (*Cursor, error)
+	Rows() (*sql.Rows, error)
+	Iterate(fn func(dest interface{}) error) error
 
 	// INSERT 操作
 	Insert(data interface{}) error
+	InsertAffected(data interface{}) (int64, error)
 	InsertBatch(data interface{}) error
+	BatchSize(size int) QueryBuilder
+	InsertOrUpdate(data interface{}) error
+	Upsert(data interface{}, conflictColumns ...string) error
+	OnConflict(columns ...string) QueryBuilder
+	DoUpdate(columns ...string) QueryBuilder
+	OnConflictDoNothing(columns ...string) QueryBuilder
+	InsertIgnore(data interface{}) error
+	FirstOrCreate(dest interface{}, attrs map[string]interface{}) error
+	UpdateOrCreate(match, updates map[string]interface{}) error
 
 	// UPDATE 操作
 	Update(data interface{}) error
 	UpdateColumns(columns map[string]interface{}) error
+	UpdateNonZero(data interface{}) error
+	UpdateAffected(data interface{}) (int64, error)
+	UpdateColumnsAffected(columns map[string]interface{}) (int64, error)
+	UpdateBatch(data interface{}, keyColumn string) (int64, error)
 
 	// DELETE 操作
 	Delete() error
+	DeleteAffected() (int64, error)
+	DeleteByIDs(ids ...interface{}) (int64, error)
+	Restore() error
+	RestoreAffected() (int64, error)
 
 	// 构建SQL
 	ToSQL() (string, []interface{})
+	ToSQLInsert(data interface{}) (string, []interface{})
+	ToSQLUpdate(data interface{}) (string, []interface{})
+	ToSQLDelete() (string, []interface{})
+	DryRun() QueryBuilder
+	DryRunStatements() []Statement
 }
 
+// LockOption 行锁选项
+type LockOption string
+
+const (
+	// NoWait 无法立即获得锁时报错
+	NoWait LockOption = "NOWAIT"
+	// SkipLocked 跳过已被锁定的行
+	SkipLocked LockOption = "SKIP LOCKED"
+)
+
 // Migration 迁移接口
 type Migration interface {
 	Up() error
@@ -132,6 +273,23 @@ type Migration interface {
 	Version() string
 }
 
+// MigrationStatements 可预先给出上迁移SQL的迁移，用于Plan和DryRun预览
+// 未实现该接口的代码迁移在计划中只列出版本
+type MigrationStatements interface {
+	UpStatements() []string
+}
+
+// Seeder 数据填充接口，Name作为填充的唯一标识记录在seeds表中
+type Seeder interface {
+	Name() string
+	Run(tx Tx) error
+}
+
+// SeederEnvironments 限定执行环境的数据填充，未实现该接口的填充在所有环境执行
+type SeederEnvironments interface {
+	Environments() []string
+}
+
 // Schema 表结构接口
 type Schema interface {
 	CreateTable(tableName string, callback func(TableInterface)) error
@@ -139,6 +297,9 @@ type Schema interface {
 	AlterTable(tableName string, callback func(TableInterface)) error
 	HasTable(tableName string) (bool, error)
 	HasColumn(tableName, columnName string) (bool, error)
+	ListTables() ([]string, error)
+	ListColumns(tableName string) ([]TableColumn, error)
+	ListIndexes(tableName string) ([]TableIndex, error)
 }
 
 // TableInterface 表定义接口
@@ -163,6 +324,14 @@ type TableInterface interface {
 	Unique(name string, columns ...string) TableInterface
 	Foreign(column, references string) TableInterface
 
+	// 表级选项
+	Engine(engine string) TableInterface
+	Charset(charset string) TableInterface
+	Collation(collation string) TableInterface
+	TableComment(comment string) TableInterface
+	OnDelete(action string) TableInterface
+	OnUpdate(action string) TableInterface
+
 	// 修饰符
 	Nullable() TableInterface
 	NotNull() TableInterface
@@ -176,37 +345,49 @@ type FieldTag struct {
 	Column        string `json:"column"`
 	Type          string `json:"type"`
 	Size          int    `json:"size"`
+	Precision     int    `json:"precision"`
+	Scale         int    `json:"scale"`
 	Primary       bool   `json:"primary"`
 	AutoIncrement bool   `json:"auto_increment"`
 	NotNull       bool   `json:"not_null"`
+	Nullable      bool   `json:"nullable"`
 	Unique        bool   `json:"unique"`
 	Index         string `json:"index"`
+	UniqueIndex   string `json:"unique_index"`
 	Default       string `json:"default"`
 	Comment       string `json:"comment"`
 	ForeignKey    string `json:"foreign_key"`
 	References    string `json:"references"`
+	OnDelete      string `json:"on_delete"`
+	OnUpdate      string `json:"on_update"`
+	Version       bool   `json:"version"`
+	UUID          string `json:"uuid"`
+	SoftDelete    bool   `json:"soft_delete"`
 }
 
 // QueryCondition 查询条件
 type QueryCondition struct {
-	Column   string        `json:"column"`
-	Operator string        `json:"operator"`
-	Value    interface{}   `json:"value"`
-	Values   []interface{} `json:"values"`
-	Logic    string        `json:"logic"` // AND, OR
+	Column     string           `json:"column"`
+	Operator   string           `json:"operator"`
+	Value      interface{}      `json:"value"`
+	Values     []interface{}    `json:"values"`
+	Logic      string           `json:"logic"`      // AND, OR
+	Conditions []QueryCondition `json:"conditions"` // 分组条件
 }
 
 // JoinClause JOIN子句
 type JoinClause struct {
-	Type      string `json:"type"` // INNER, LEFT, RIGHT, FULL
-	Table     string `json:"table"`
-	Condition string `json:"condition"`
+	Type      string        `json:"type"` // INNER, LEFT, RIGHT, FULL, CROSS
+	Table     string        `json:"table"`
+	Condition string        `json:"condition"`
+	Args      []interface{} `json:"args"`
 }
 
 // OrderClause 排序子句
 type OrderClause struct {
 	Column    string `json:"column"`
 	Direction string `json:"direction"` // ASC, DESC
+	Raw       bool   `json:"raw"`       // 原始排序表达式，不加引号
 }
 
 // GroupClause 分组子句
@@ -220,6 +401,14 @@ type HavingClause struct {
 	Args      []interface{} `json:"args"`
 }
 
+// Pagination 分页信息
+type Pagination struct {
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PerPage  int   `json:"per_page"`
+	LastPage int   `json:"last_page"`
+}
+
 // LimitClause 限制子句
 type LimitClause struct {
 	Limit  int `json:"limit"`
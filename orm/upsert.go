@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OnConflict 设置冲突检测列，之后的Insert/InsertBatch将生成upsert语句
+func (qb *queryBuilder) OnConflict(columns ...string) QueryBuilder {
+	qb.upsert = true
+	qb.conflictColumns = columns
+	return qb
+}
+
+// DoUpdate 设置冲突时需要更新的列，未设置时更新除冲突列、主键和创建时间列外的全部插入列
+func (qb *queryBuilder) DoUpdate(columns ...string) QueryBuilder {
+	qb.upsert = true
+	qb.updateColumns = columns
+	return qb
+}
+
+// OnConflictDoNothing 之后的Insert/InsertBatch遇到冲突时跳过该行，不报错也不更新已有记录
+// 未指定冲突列时，PostgreSQL和SQLite跳过任意唯一约束上的冲突，SQL Server和Oracle按模型主键匹配，
+// MySQL生成保持原值的 ON DUPLICATE KEY UPDATE；ClickHouse没有唯一约束，按普通插入执行
+func (qb *queryBuilder) OnConflictDoNothing(columns ...string) QueryBuilder {
+	qb.upsert = true
+	qb.conflictColumns = columns
+	qb.updateColumns = []string{}
+	return qb
+}
+
+// InsertIgnore 插入记录并跳过与已有记录冲突的行，data为切片时批量执行，适合幂等的数据导入
+func (qb *queryBuilder) InsertIgnore(data interface{}) error {
+	qb.OnConflictDoNothing(qb.conflictColumns...)
+	if value := reflect.Indirect(reflect.ValueOf(data)); value.Kind() == reflect.Slice {
+		return qb.InsertBatch(data)
+	}
+	return qb.Insert(data)
+}
+
+// ignoresConflicts 冲突时是否跳过而不更新
+func (qb *queryBuilder) ignoresConflicts() bool {
+	return qb.updateColumns != nil && len(qb.updateColumns) == 0
+}
+
+// InsertOrUpdate 插入记录，记录已存在时更新
+func (qb *queryBuilder) InsertOrUpdate(data interface{}) error {
+	qb.upsert = true
+	return qb.Insert(data)
+}
+
+// Upsert 插入记录，与冲突列上的已有记录冲突时更新，data为切片时批量执行
+// 未指定冲突列时使用OnConflict设置的列或模型主键
+func (qb *queryBuilder) Upsert(data interface{}, conflictColumns ...string) error {
+	qb.upsert = true
+	if len(conflictColumns) > 0 {
+		qb.conflictColumns = conflictColumns
+	}
+	if value := reflect.Indirect(reflect.ValueOf(data)); value.Kind() == reflect.Slice {
+		return qb.InsertBatch(data)
+	}
+	return qb.Insert(data)
+}
+
+// buildUpsertSQL 构建upsert SQL
+// 未通过DoUpdate指定更新列时，冲突列、主键和创建时间列以外的插入列在冲突时被更新，已有记录保留原创建时间
+func (qb *queryBuilder) buildUpsertSQL(data interface{}, columns []string, rowCount int) string {
+	conflictColumns := qb.conflictColumns
+	if len(conflictColumns) == 0 && !qb.ignoresAnyConflict() {
+		conflictColumns = primaryKeyColumns(data)
+	}
+
+	updateColumns := qb.updateColumns
+	if updateColumns == nil {
+		excluded := make(map[string]bool)
+		for _, col := range conflictColumns {
+			excluded[col] = true
+		}
+		for _, col := range primaryKeyColumns(data) {
+			excluded[col] = true
+		}
+		createdAtColumn, _ := NewModelManager(qb.orm).timestampColumns()
+		excluded[createdAtColumn] = true
+		for _, col := range columns {
+			if !excluded[col] {
+				updateColumns = append(updateColumns, col)
+			}
+		}
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	row := "(" + strings.Join(placeholders, ", ") + ")"
+
+	rows := make([]string, rowCount)
+	for i := range rows {
+		rows[i] = row
+	}
+
+	return qb.dialect().UpsertSQL(qb.tableName, columns, strings.Join(rows, ", "), conflictColumns, updateColumns)
+}
+
+// ignoresAnyConflict 是否生成不带冲突列的 ON CONFLICT DO NOTHING，跳过任意唯一约束上的冲突
+func (qb *queryBuilder) ignoresAnyConflict() bool {
+	if len(qb.conflictColumns) > 0 || !qb.ignoresConflicts() {
+		return false
+	}
+	switch qb.dialect().(type) {
+	case *PostgreSQLDialect, *SQLiteDialect:
+		return true
+	}
+	return false
+}
+
+// validateUpsert 检查upsert所需的冲突列
+func (qb *queryBuilder) validateUpsert(data interface{}) error {
+	if len(qb.conflictColumns) > 0 || len(primaryKeyColumns(data)) > 0 || qb.ignoresAnyConflict() {
+		return nil
+	}
+	switch qb.dialect().(type) {
+	case *MySQLDialect, *ClickHouseDialect:
+		return nil
+	default:
+		return fmt.Errorf("upsert需要通过OnConflict指定冲突列或在模型中标记主键")
+	}
+}
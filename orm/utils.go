@@ -4,63 +4,144 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // extractColumnsAndValues 从结构体中提取列名和值
 func (qb *queryBuilder) extractColumnsAndValues(data interface{}) ([]string, []interface{}) {
-	v := reflect.ValueOf(data)
-	t := reflect.TypeOf(data)
-	
-	// 如果是指针，获取其指向的值
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-		t = t.Elem()
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return nil, nil
 	}
-	
+
+	fields := metadataOf(v.Type()).fields
+	columns := make([]string, 0, len(fields))
+	values := make([]interface{}, 0, len(fields))
+
+	for _, field := range fields {
+		fieldValue := v.FieldByIndex(field.index)
+
+		// 跳过零值的自增列和主键列，由数据库生成
+		if (field.tag.AutoIncrement || field.tag.Primary) && isZeroValue(fieldValue) {
+			continue
+		}
+
+		columns = append(columns, field.column)
+		values = append(values, columnValue(field, fieldValue))
+	}
+
+	return columns, values
+}
+
+// extractNonZeroColumnsAndValues 从结构体中提取非零值字段的列名和值
+func extractNonZeroColumnsAndValues(data interface{}) ([]string, []interface{}) {
+	v := reflect.Indirect(reflect.ValueOf(data))
 	if v.Kind() != reflect.Struct {
 		return nil, nil
 	}
-	
+
 	var columns []string
 	var values []interface{}
-	
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		fieldValue := v.Field(i)
-		
-		// 跳过未导出的字段
-		if !fieldValue.CanInterface() {
+
+	for _, field := range metadataOf(v.Type()).fields {
+		fieldValue := v.FieldByIndex(field.index)
+		if isZeroValue(fieldValue) {
 			continue
 		}
-		
-		// 获取字段标签
-		tag := field.Tag.Get("orm")
-		if tag == "-" {
-			continue
+
+		columns = append(columns, field.column)
+		values = append(values, columnValue(field, fieldValue))
+	}
+
+	return columns, values
+}
+
+// primaryKeyColumns 获取结构体中标记为primary的列名
+func primaryKeyColumns(data interface{}) []string {
+	t := structType(data)
+	if t == nil {
+		return nil
+	}
+	return metadataOf(t).primary
+}
+
+// primaryKeyValues 获取结构体的主键列及其值，没有主键或任一主键为零值时返回false
+func primaryKeyValues(data interface{}) ([]string, []interface{}, bool) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil, false
 		}
-		
-		columnName := field.Name
-		if tag != "" {
-			parts := strings.Split(tag, ",")
-			if parts[0] != "" {
-				columnName = parts[0]
-			}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+
+	columns := primaryKeyColumns(data)
+	if len(columns) == 0 {
+		return nil, nil, false
+	}
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		field := findFieldByColumn(v, column)
+		if !field.IsValid() || isZeroValue(field) {
+			return nil, nil, false
 		}
-		
-		// 转换为下划线命名
-		columnName = camelToSnake(columnName)
-		
-		columns = append(columns, columnName)
-		values = append(values, fieldValue.Interface())
+		values[i] = field.Interface()
 	}
-	
-	return columns, values
+	return columns, values, true
+}
+
+// versionField 查找结构体中标记为version的乐观锁字段
+func versionField(data interface{}) (string, reflect.Value, bool) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return "", reflect.Value{}, false
+	}
+
+	field := metadataOf(v.Type()).version
+	if field == nil {
+		return "", reflect.Value{}, false
+	}
+	return field.column, v.FieldByIndex(field.index), true
+}
+
+// versionValues 获取版本号的当前值和下一个值
+func versionValues(field reflect.Value) (int64, int64) {
+	var current int64
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		current = int64(field.Uint())
+	default:
+		current = field.Int()
+	}
+	return current, current + 1
+}
+
+// fieldColumnName 根据orm标签获取字段对应的列名，忽略的字段返回false
+func fieldColumnName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("orm")
+	if tag == "-" || isRelationField(field) {
+		return "", false
+	}
+
+	columnName := field.Name
+	if tag != "" {
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			columnName = parts[0]
+		}
+	}
+
+	// 转换为下划线命名
+	return camelToSnake(columnName), true
 }
 
 // scanRows 扫描多行结果到切片
-func scanRows(rows *sql.Rows, dest interface{}) error {
+func scanRows(rows *sql.Rows, dest interface{}, loc *time.Location) error {
 	destValue := reflect.ValueOf(dest)
 	if destValue.Kind() != reflect.Ptr {
 		return fmt.Errorf("dest必须是指针类型")
@@ -88,20 +169,8 @@ func scanRows(rows *sql.Rows, dest interface{}) error {
 		// 创建新的元素
 		elem := reflect.New(elemType).Elem()
 		
-		// 准备扫描目标
-		scanDest := make([]interface{}, len(columns))
-		for i, col := range columns {
-			field := findFieldByColumn(elem, col)
-			if field.IsValid() && field.CanSet() {
-				scanDest[i] = field.Addr().Interface()
-			} else {
-				var dummy interface{}
-				scanDest[i] = &dummy
-			}
-		}
-		
 		// 扫描行数据
-		if err := rows.Scan(scanDest...); err != nil {
+		if err := scanInto(rows, elem, columns, loc); err != nil {
 			return err
 		}
 		
@@ -116,52 +185,44 @@ func scanRows(rows *sql.Rows, dest interface{}) error {
 	return rows.Err()
 }
 
-// scanRow 扫描单行结果到结构体
-func scanRow(row *sql.Row, dest interface{}) error {
+// scanStruct 扫描结果集的第一行到结构体，无数据时返回ErrRecordNotFound
+func scanStruct(rows *sql.Rows, dest interface{}, loc *time.Location) error {
 	destValue := reflect.ValueOf(dest)
-	if destValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("dest必须是指针类型")
-	}
-	
-	destValue = destValue.Elem()
-	if destValue.Kind() != reflect.Struct {
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("dest必须是结构体指针")
 	}
-	
-	// 这里需要更复杂的实现来处理单行扫描
-	// 暂时返回一个简单的错误，实际使用中需要根据具体需求实现
-	return fmt.Errorf("scanRow方法需要进一步实现")
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return errRecordNotFound
+	}
+
+	if err := scanInto(rows, destValue.Elem(), columns, loc); err != nil {
+		return err
+	}
+	return rows.Err()
 }
 
-// findFieldByColumn 根据列名查找结构体字段
+// findFieldByColumn 根据列名（或别名）查找结构体字段，支持嵌入结构体
 func findFieldByColumn(structValue reflect.Value, columnName string) reflect.Value {
-	structType := structValue.Type()
-	
-	for i := 0; i < structValue.NumField(); i++ {
-		field := structType.Field(i)
-		fieldValue := structValue.Field(i)
-		
-		// 检查orm标签
-		tag := field.Tag.Get("orm")
-		if tag != "" {
-			parts := strings.Split(tag, ",")
-			if parts[0] == columnName {
-				return fieldValue
-			}
-		}
-		
-		// 检查字段名转换
-		if camelToSnake(field.Name) == columnName {
-			return fieldValue
-		}
-		
-		// 直接匹配字段名
-		if strings.EqualFold(field.Name, columnName) {
-			return fieldValue
-		}
+	field, _ := findColumnField(structValue, columnName)
+	return field
+}
+
+// findColumnField 根据列名查找结构体字段，同时返回字段定义
+func findColumnField(structValue reflect.Value, columnName string) (reflect.Value, reflect.StructField) {
+	field := metadataOf(structValue.Type()).fieldByColumn(columnName)
+	if field == nil {
+		return reflect.Value{}, reflect.StructField{}
 	}
-	
-	return reflect.Value{}
+	return structValue.FieldByIndex(field.index), field.field
 }
 
 // getStructName 获取结构体名称
@@ -174,11 +235,12 @@ func getStructName(model interface{}) string {
 }
 
 // parseFieldTag 解析字段标签
-func parseFieldTag(tag string) FieldTag {
+// size和precision须为正整数，scale须为不大于precision的非负整数，否则返回错误
+func parseFieldTag(tag string) (FieldTag, error) {
 	fieldTag := FieldTag{}
 	
 	if tag == "" {
-		return fieldTag
+		return fieldTag, nil
 	}
 	
 	parts := strings.Split(tag, ",")
@@ -204,23 +266,63 @@ func parseFieldTag(tag string) FieldTag {
 			fieldTag.NotNull = true
 		case "unique":
 			fieldTag.Unique = true
+		case "version":
+			fieldTag.Version = true
+		case "soft_delete":
+			fieldTag.SoftDelete = true
+		case "nullable":
+			fieldTag.Nullable = true
+		case "uuid":
+			fieldTag.UUID = "v4"
+		case "index":
+			fieldTag.Index = defaultIndexName
+		case "unique_index":
+			fieldTag.UniqueIndex = defaultIndexName
 		default:
 			if strings.HasPrefix(part, "type:") {
 				fieldTag.Type = strings.TrimPrefix(part, "type:")
 			} else if strings.HasPrefix(part, "size:") {
-				// 解析size，这里简化处理
-				fieldTag.Size = 255 // 默认值
+				n, err := strconv.Atoi(strings.TrimPrefix(part, "size:"))
+				if err != nil || n <= 0 {
+					return fieldTag, fmt.Errorf("%s 须为正整数", part)
+				}
+				fieldTag.Size = n
+			} else if strings.HasPrefix(part, "precision:") {
+				n, err := strconv.Atoi(strings.TrimPrefix(part, "precision:"))
+				if err != nil || n <= 0 {
+					return fieldTag, fmt.Errorf("%s 须为正整数", part)
+				}
+				fieldTag.Precision = n
+			} else if strings.HasPrefix(part, "scale:") {
+				n, err := strconv.Atoi(strings.TrimPrefix(part, "scale:"))
+				if err != nil || n < 0 {
+					return fieldTag, fmt.Errorf("%s 须为非负整数", part)
+				}
+				fieldTag.Scale = n
 			} else if strings.HasPrefix(part, "default:") {
 				fieldTag.Default = strings.TrimPrefix(part, "default:")
 			} else if strings.HasPrefix(part, "comment:") {
 				fieldTag.Comment = strings.TrimPrefix(part, "comment:")
 			} else if strings.HasPrefix(part, "index:") {
 				fieldTag.Index = strings.TrimPrefix(part, "index:")
+			} else if strings.HasPrefix(part, "unique_index:") {
+				fieldTag.UniqueIndex = strings.TrimPrefix(part, "unique_index:")
+			} else if strings.HasPrefix(part, "uuid:") {
+				fieldTag.UUID = strings.TrimPrefix(part, "uuid:")
+			} else if strings.HasPrefix(part, "references:") {
+				fieldTag.References = strings.TrimPrefix(part, "references:")
+			} else if strings.HasPrefix(part, "on_delete:") {
+				fieldTag.OnDelete = strings.ToUpper(strings.TrimPrefix(part, "on_delete:"))
+			} else if strings.HasPrefix(part, "on_update:") {
+				fieldTag.OnUpdate = strings.ToUpper(strings.TrimPrefix(part, "on_update:"))
 			}
 		}
 	}
 	
-	return fieldTag
+	if fieldTag.Precision > 0 && fieldTag.Scale > fieldTag.Precision {
+		return fieldTag, fmt.Errorf("scale:%d 不能大于precision:%d", fieldTag.Scale, fieldTag.Precision)
+	}
+	return fieldTag, nil
 }
 
 // convertValue 转换值类型
@@ -255,11 +357,9 @@ func convertValue(value interface{}, targetType reflect.Type) interface{} {
 	}
 	
 	// 时间类型特殊处理
-	if targetType == reflect.TypeOf(time.Time{}) {
-		if str, ok := value.(string); ok {
-			if t, err := time.Parse("2006-01-02 15:04:05", str); err == nil {
-				return t
-			}
+	if targetType == timeType {
+		if t, err := parseTimeValue(value, time.Local); err == nil {
+			return t
 		}
 	}
 	
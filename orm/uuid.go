@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fastgox/utils/crypto"
+)
+
+// generateUUID 按版本生成UUID，支持v4和v7
+func generateUUID(version string) (string, error) {
+	switch version {
+	case "v4", "4":
+		return crypto.GenerateUUID()
+	case "v7", "7":
+		return crypto.GenerateUUIDv7()
+	}
+	return "", fmt.Errorf("不支持的UUID版本: %s", version)
+}
+
+// fillUUIDs 为插入的记录中为空的uuid字段生成UUID，data可以是结构体指针或结构体切片
+func fillUUIDs(data interface{}) error {
+	v := reflect.ValueOf(data)
+	switch {
+	case v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct:
+		return fillStructUUIDs(v.Elem())
+	case v.Kind() == reflect.Slice || (v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice):
+		v = reflect.Indirect(v)
+		for i := 0; i < v.Len(); i++ {
+			item := reflect.Indirect(v.Index(i))
+			if item.Kind() != reflect.Struct {
+				continue
+			}
+			if err := fillStructUUIDs(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillStructUUIDs 为结构体中为空的uuid字符串字段生成UUID
+func fillStructUUIDs(v reflect.Value) error {
+	for _, meta := range metadataOf(v.Type()).fields {
+		field := v.FieldByIndex(meta.index)
+		if meta.tag.UUID == "" || !field.CanSet() || field.Kind() != reflect.String || field.String() != "" {
+			continue
+		}
+
+		id, err := generateUUID(meta.tag.UUID)
+		if err != nil {
+			return fmt.Errorf("字段 %s 生成UUID失败: %w", meta.field.Name, err)
+		}
+		field.SetString(id)
+	}
+	return nil
+}
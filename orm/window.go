@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Window 窗口定义，用于生成 OVER (PARTITION BY ... ORDER BY ...) 子句
+type Window struct {
+	partitions []string
+	orders     []OrderClause
+}
+
+// Over 创建窗口定义
+func Over() *Window {
+	return &Window{}
+}
+
+// PartitionBy 设置分区列
+func (w *Window) PartitionBy(columns ...string) *Window {
+	w.partitions = append(w.partitions, columns...)
+	return w
+}
+
+// OrderBy 添加窗口内排序
+func (w *Window) OrderBy(column string, direction ...string) *Window {
+	w.orders = append(w.orders, OrderClause{Column: column, Direction: orderDirection(direction...)})
+	return w
+}
+
+// String 生成OVER括号内的窗口定义
+func (w *Window) String() string {
+	if w == nil {
+		return ""
+	}
+
+	var parts []string
+	if len(w.partitions) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitions, ", "))
+	}
+	if len(w.orders) > 0 {
+		var orderParts []string
+		for _, order := range w.orders {
+			orderParts = append(orderParts, order.Column+" "+order.Direction)
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(orderParts, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// SelectWindow 追加窗口函数列，如 SelectWindow("SUM(amount)", orm.Over().PartitionBy("user_id"), "total")
+func (qb *queryBuilder) SelectWindow(function string, over *Window, alias string) QueryBuilder {
+	expression := fmt.Sprintf("%s OVER (%s)", function, over.String())
+	if alias != "" {
+		expression += " AS " + alias
+	}
+	qb.selectCols = append(qb.selectCols, expression)
+	return qb
+}
+
+// RowNumberOver 追加 ROW_NUMBER() 窗口列
+func (qb *queryBuilder) RowNumberOver(over *Window, alias string) QueryBuilder {
+	return qb.SelectWindow("ROW_NUMBER()", over, alias)
+}
+
+// RankOver 追加 RANK() 窗口列
+func (qb *queryBuilder) RankOver(over *Window, alias string) QueryBuilder {
+	return qb.SelectWindow("RANK()", over, alias)
+}
@@ -3,6 +3,7 @@ package crypto_test
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fastgox/utils/crypto"
 )
@@ -344,6 +345,22 @@ func TestCryptoUtils(t *testing.T) {
 			t.Fatalf("随机字符串长度不正确: 期望 16, 得到 %d", len(randomString))
 		}
 
+		// 生成UUID
+		uuid, err := crypto.GenerateUUID()
+		if err != nil || len(uuid) != 36 || uuid[14] != '4' {
+			t.Fatalf("生成UUID失败: %s, %v", uuid, err)
+		}
+
+		first, err := crypto.GenerateUUIDv7()
+		if err != nil || len(first) != 36 || first[14] != '7' {
+			t.Fatalf("生成UUIDv7失败: %s, %v", first, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+		second, _ := crypto.GenerateUUIDv7()
+		if second <= first {
+			t.Fatalf("UUIDv7应按时间递增: %s <= %s", second, first)
+		}
+
 		t.Logf("随机数生成测试通过")
 		t.Logf("随机字符串: %s", randomString)
 	})
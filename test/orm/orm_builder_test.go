@@ -0,0 +1,2244 @@
+package orm_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/fastgox/utils/orm"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3" // SQLite驱动
+)
+
+// Account 账户模型（使用独立ORM实例测试）
+type Account struct {
+	ID      int64   `orm:"id,primary,auto_increment" json:"id"`
+	Name    string  `orm:"name,size:100,not_null" json:"name"`
+	Balance float64 `orm:"balance" json:"balance"`
+	Status  string  `orm:"status,size:20" json:"status"`
+}
+
+// TableName 自定义表名
+func (Account) TableName() string {
+	return "accounts"
+}
+
+// newTestORM 创建基于内存SQLite的独立ORM实例
+func newTestORM(t *testing.T) *orm.ORM {
+	t.Helper()
+
+	db := orm.New(&orm.Config{
+		Type:         orm.SQLite,
+		Database:     ":memory:",
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+	})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name VARCHAR(100) NOT NULL,
+		balance REAL,
+		status VARCHAR(20)
+	)`); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	return db
+}
+
+// seedAccounts 插入测试账户
+func seedAccounts(t *testing.T, db *orm.ORM, accounts ...Account) {
+	t.Helper()
+	for _, account := range accounts {
+		if _, err := db.Exec("INSERT INTO accounts (name, balance, status) VALUES (?, ?, ?)",
+			account.Name, account.Balance, account.Status); err != nil {
+			t.Fatalf("插入账户失败: %v", err)
+		}
+	}
+}
+
+// TestRowsAffected 测试写操作返回受影响的行数
+func TestRowsAffected(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	affected, err := db.Model(&Account{}).Where("status = ?", "active").
+		UpdateColumnsAffected(map[string]interface{}{"balance": 0})
+	if err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if affected != 2 {
+		t.Errorf("期望更新 2 行，实际为 %d", affected)
+	}
+
+	affected, err = db.Model(&Account{}).Where("status = ?", "missing").DeleteAffected()
+	if err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if affected != 0 {
+		t.Errorf("期望删除 0 行，实际为 %d", affected)
+	}
+
+	affected, err = db.Model(&Account{}).Where("status = ?", "frozen").DeleteAffected()
+	if err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("期望删除 1 行，实际为 %d", affected)
+	}
+
+	inserted := &Account{Name: "c", Status: "active"}
+	affected, err = db.Model(&Account{}).InsertAffected(inserted)
+	if err != nil || affected != 1 || inserted.ID == 0 {
+		t.Errorf("期望插入 1 行并回填主键，实际为 %d, %+v, err: %v", affected, inserted, err)
+	}
+}
+
+// TestQueryWithContext 测试上下文在查询构建器与事务中的传递
+func TestQueryWithContext(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Balance: 10, Status: "active"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var accounts []Account
+	err := db.Model(&Account{}).WithContext(ctx).Find(&accounts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("期望返回 context.Canceled，实际为 %v", err)
+	}
+
+	if _, err := db.WithContext(ctx).Table("accounts").Count(); !errors.Is(err, context.Canceled) {
+		t.Errorf("期望会话查询返回 context.Canceled，实际为 %v", err)
+	}
+
+	called := false
+	err = db.WithContext(ctx).WithTransaction(func(tx orm.Tx) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || called {
+		t.Errorf("上下文已取消时不应执行事务函数，err: %v", err)
+	}
+
+	count, err := db.WithContext(context.Background()).Table("accounts").Count()
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("期望数量为 1，实际为 %d", count)
+	}
+}
+
+// TestDialectPlaceholders 测试不同方言的占位符改写
+func TestDialectPlaceholders(t *testing.T) {
+	cases := []struct {
+		dbType   orm.DatabaseType
+		expected string
+	}{
+		{orm.MySQL, "SELECT * FROM `accounts` WHERE status = ? AND name <> '?' AND balance > ?"},
+		{orm.SQLite, "SELECT * FROM `accounts` WHERE status = ? AND name <> '?' AND balance > ?"},
+		{orm.PostgreSQL, `SELECT * FROM "accounts" WHERE status = $1 AND name <> '?' AND balance > $2`},
+		{orm.SQLServer, "SELECT * FROM [accounts] WHERE status = @p1 AND name <> '?' AND balance > @p2"},
+		{orm.ClickHouse, "SELECT * FROM `accounts` WHERE status = ? AND name <> '?' AND balance > ?"},
+		{orm.Oracle, `SELECT * FROM "accounts" WHERE status = :1 AND name <> '?' AND balance > :2`},
+	}
+
+	for _, c := range cases {
+		db := orm.New(&orm.Config{Type: c.dbType})
+		query, args := db.Table("accounts").
+			Where("status = ?", "active").
+			Where("name <> '?' AND balance > ?", 10).
+			ToSQL()
+		if query != c.expected {
+			t.Errorf("%s: 期望SQL为 %q，实际为 %q", c.dbType, c.expected, query)
+		}
+		if len(args) != 2 {
+			t.Errorf("%s: 期望 2 个参数，实际为 %d", c.dbType, len(args))
+		}
+	}
+}
+
+// TestClickHouseAndOracleDialects 测试ClickHouse、Oracle和SQL Server方言的分页、行锁和建表语句
+func TestClickHouseAndOracleDialects(t *testing.T) {
+	oracle := orm.New(&orm.Config{Type: orm.Oracle})
+	query, _ := oracle.Table("accounts").Where("status = ?", "active").OrderBy("id").Limit(10).Offset(20).ForUpdate(orm.SkipLocked).ToSQL()
+	expected := `SELECT * FROM "accounts" WHERE status = :1 ORDER BY "id" ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY FOR UPDATE SKIP LOCKED`
+	if query != expected {
+		t.Errorf("Oracle分页SQL不符合预期: %s", query)
+	}
+	if query, _ := oracle.Table("accounts").Limit(5).ForShare().ToSQL(); query != `SELECT * FROM "accounts" FETCH NEXT 5 ROWS ONLY` {
+		t.Errorf("Oracle不支持共享锁，应忽略ForShare: %s", query)
+	}
+
+	// Oracle不允许在表别名和派生表别名前使用AS
+	query, _ = oracle.Table("accounts a").Join("orders o", "o.account_id = a.id").Select("a.name").ToSQL()
+	if query != `SELECT "a"."name" FROM "accounts" "a" INNER JOIN "orders" "o" ON o.account_id = a.id` {
+		t.Errorf("Oracle表别名前不应有AS: %s", query)
+	}
+	totals := oracle.Table("orders").Select("account_id").GroupBy("account_id")
+	query, _ = oracle.Table("accounts").FromSub(totals, "t").ToSQL()
+	if query != `SELECT * FROM (SELECT "account_id" FROM "orders" GROUP BY "account_id") "t"` {
+		t.Errorf("Oracle派生表别名前不应有AS: %s", query)
+	}
+
+	// SQL Server的OFFSET/FETCH需要ORDER BY，未排序时补充 ORDER BY (SELECT NULL)
+	sqlServer := orm.New(&orm.Config{Type: orm.SQLServer})
+	query, _ = sqlServer.Table("accounts").OrderBy("id").Limit(10).Offset(20).ToSQL()
+	if query != "SELECT * FROM [accounts] ORDER BY [id] ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY" {
+		t.Errorf("SQL Server分页SQL不符合预期: %s", query)
+	}
+	query, _ = sqlServer.Table("accounts").Limit(5).ToSQL()
+	if query != "SELECT * FROM [accounts] ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY" {
+		t.Errorf("SQL Server未排序时应补充ORDER BY: %s", query)
+	}
+
+	clickhouse := orm.New(&orm.Config{Type: orm.ClickHouse})
+	query, _ = clickhouse.Table("events").Limit(10).Offset(20).ForUpdate().ToSQL()
+	if query != "SELECT * FROM `events` LIMIT 10 OFFSET 20" {
+		t.Errorf("ClickHouse分页SQL不符合预期: %s", query)
+	}
+
+	columns := []orm.ColumnDefinition{
+		{Name: "id", Type: "NUMBER(19)", Primary: true, AutoIncrement: true},
+		{Name: "status", Type: "VARCHAR2(20)", NotNull: true, Default: "'draft'"},
+	}
+	createSQL := orm.NewDatabaseManager(oracle).GetDialect().CreateTableSQL("invoice", columns)
+	if createSQL != `CREATE TABLE "invoice" ("id" NUMBER(19) GENERATED BY DEFAULT AS IDENTITY, "status" VARCHAR2(20) DEFAULT 'draft' NOT NULL, PRIMARY KEY ("id"))` {
+		t.Errorf("Oracle建表语句不符合预期: %s", createSQL)
+	}
+	createSQL = orm.NewDatabaseManager(clickhouse).GetDialect().CreateTableSQL("events", columns[:1])
+	if !strings.HasSuffix(createSQL, "ENGINE = MergeTree() ORDER BY (`id`)") {
+		t.Errorf("ClickHouse建表语句应指定引擎和排序键: %s", createSQL)
+	}
+
+	info := orm.NewModelManager(clickhouse).GetTableInfo(&Invoice{})
+	if column := info.GetColumnByName("number"); column == nil || column.Type != "String" {
+		t.Errorf("ClickHouse字符串列类型应为String: %+v", column)
+	}
+	info = orm.NewModelManager(oracle).GetTableInfo(&Invoice{})
+	if column := info.GetColumnByName("number"); column == nil || column.Type != "VARCHAR2(32)" {
+		t.Errorf("Oracle字符串列类型应为VARCHAR2: %+v", column)
+	}
+}
+
+// TestOrWhereGroups 测试OR条件与括号分组
+func TestOrWhereGroups(t *testing.T) {
+	db := orm.New(&orm.Config{Type: orm.MySQL})
+
+	query, args := db.Table("accounts").
+		WhereGroup(func(q orm.QueryBuilder) {
+			q.Where("status = ?", "active").Where("balance > ?", 100)
+		}).
+		OrWhereGroup(func(q orm.QueryBuilder) {
+			q.Where("name = ?", "vip").OrWhere("deleted_at IS NULL")
+		}).
+		ToSQL()
+
+	expected := "SELECT * FROM `accounts` WHERE (status = ? AND balance > ?) OR (name = ? OR deleted_at IS NULL)"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+	if len(args) != 3 {
+		t.Errorf("期望 3 个参数，实际为 %d", len(args))
+	}
+
+	sqliteDB := newTestORM(t)
+	seedAccounts(t, sqliteDB,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 200, Status: "active"},
+		Account{Name: "c", Balance: 300, Status: "frozen"},
+	)
+
+	count, err := sqliteDB.Table("accounts").
+		Where("status = ?", "frozen").
+		OrWhereGroup(func(q orm.QueryBuilder) {
+			q.Where("status = ?", "active").Where("balance > ?", 100)
+		}).
+		Count()
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("期望数量为 2，实际为 %d", count)
+	}
+}
+
+// TestWhereMapAndStruct 测试基于map和结构体的等值条件
+func TestWhereMapAndStruct(t *testing.T) {
+	db := orm.New(&orm.Config{Type: orm.MySQL})
+
+	query, args := db.Table("accounts").
+		WhereMap(map[string]interface{}{"status": "active", "name": "a", "deleted_at": nil}).
+		ToSQL()
+	expected := "SELECT * FROM `accounts` WHERE `deleted_at` IS NULL AND `name` = ? AND `status` = ?"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+	if len(args) != 2 || args[0] != "a" || args[1] != "active" {
+		t.Errorf("参数不符合预期: %v", args)
+	}
+
+	query, args = db.Model(&Account{}).WhereStruct(&Account{Name: "b", Status: "frozen"}).ToSQL()
+	expected = "SELECT * FROM `accounts` WHERE `name` = ? AND `status` = ?"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+	if len(args) != 2 || args[0] != "b" || args[1] != "frozen" {
+		t.Errorf("参数不符合预期: %v", args)
+	}
+
+	// 切片值生成IN条件，不应被当作原始条件
+	query, args = db.Table("accounts").
+		WhereMap(map[string]interface{}{"status": "active", "id": []interface{}{1, 2}, "name": []string{"a", "b"}}).
+		ToSQL()
+	expected = "SELECT * FROM `accounts` WHERE `id` IN (?, ?) AND `name` IN (?, ?) AND `status` = ?"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+	if len(args) != 5 || args[0] != 1 || args[2] != "a" || args[4] != "active" {
+		t.Errorf("参数不符合预期: %v", args)
+	}
+
+	// 空切片不匹配任何记录，空的NOT IN匹配全部记录
+	query, args = db.Table("accounts").WhereMap(map[string]interface{}{"id": []int64{}}).WhereNotIn("status").ToSQL()
+	expected = "SELECT * FROM `accounts` WHERE 1 = 0 AND 1 = 1"
+	if query != expected || len(args) != 0 {
+		t.Errorf("期望SQL为 %q，实际为 %q，参数: %v", expected, query, args)
+	}
+	sqlite := newTestORM(t)
+	seedAccounts(t, sqlite, Account{Name: "a", Status: "active"})
+	if count, err := sqlite.Model(&Account{}).WhereMap(map[string]interface{}{"id": []int64{}}).Count(); err != nil || count != 0 {
+		t.Errorf("空切片条件期望0条记录，实际为 %d, err: %v", count, err)
+	}
+	if count, err := sqlite.Model(&Account{}).WhereNotIn("id").Count(); err != nil || count != 1 {
+		t.Errorf("空NOT IN条件期望1条记录，实际为 %d, err: %v", count, err)
+	}
+}
+
+// TestIdentifierQuoting 测试标识符加引号及排序方向、运算符的校验
+func TestIdentifierQuoting(t *testing.T) {
+	mysqlDB := orm.New(&orm.Config{Type: orm.MySQL})
+
+	query, _ := mysqlDB.Table("accounts").
+		WhereMap(map[string]interface{}{"name` = 1 OR `1": "x"}).
+		OrderBy("balance; DROP TABLE accounts", "DESC; DROP TABLE accounts").
+		ToSQL()
+	expected := "SELECT * FROM `accounts` WHERE `name`` = 1 OR ``1` = ? ORDER BY `balance; DROP TABLE accounts` ASC"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+
+	query, _ = orm.New(&orm.Config{Type: orm.SQLServer}).Table("accounts").
+		Select("name", "COUNT(*) AS cnt").
+		OrderBy("a.name", "desc").
+		OrderByRaw("LEN(name)").
+		ToSQL()
+	if query != "SELECT [name], COUNT(*) AS cnt FROM [accounts] ORDER BY [a].[name] DESC, LEN(name)" {
+		t.Errorf("SQL Server引号或原始排序不符合预期: %s", query)
+	}
+
+	// 条件和排序的列名始终加引号，表达式只能通过OrderByRaw等原始方法传入
+	query, _ = mysqlDB.Table("accounts").
+		WhereIn("LOWER(name)", "a", "b").
+		WhereBetween("ROUND(balance)", 10, 20).
+		WhereNotNull("TRIM(status)").
+		OrderBy("(SELECT password FROM admins LIMIT 1)", "DESC").
+		OrderByRaw("COUNT(*) DESC").
+		ToSQL()
+	expected = "SELECT * FROM `accounts` WHERE `LOWER(name)` IN (?, ?) AND `ROUND(balance)` BETWEEN ? AND ? AND `TRIM(status)` IS NOT NULL ORDER BY `(SELECT password FROM admins LIMIT 1)` DESC, COUNT(*) DESC"
+	if query != expected {
+		t.Errorf("期望SQL为 %q，实际为 %q", expected, query)
+	}
+
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+	)
+	var names []string
+	if err := db.Table("accounts").OrderBy("name) --").Pluck("name", &names); err == nil {
+		t.Error("不存在的排序列应导致查询报错")
+	}
+	if err := db.Table("accounts").OrderBy("balance", "sideways").Pluck("name", &names); err != nil || len(names) != 2 || names[0] != "a" {
+		t.Errorf("非法排序方向应按ASC处理: %v, err: %v", names, err)
+	}
+}
+
+// TestRawQueryScan 测试原始SQL查询结果的扫描
+func TestRawQueryScan(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	var summaries []struct {
+		Status string  `orm:"status"`
+		Total  float64 `orm:"total"`
+	}
+	err := db.RawQuery("SELECT status, SUM(balance) AS total FROM accounts GROUP BY status ORDER BY status").
+		Scan(&summaries)
+	if err != nil {
+		t.Fatalf("原始查询失败: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].Total != 30 || summaries[1].Total != 30 {
+		t.Errorf("汇总结果不符合预期: %+v", summaries)
+	}
+
+	var account Account
+	if err := db.RawQuery("SELECT * FROM accounts WHERE name = ?", "b").Scan(&account); err != nil {
+		t.Fatalf("扫描单行失败: %v", err)
+	}
+	if account.Name != "b" || account.Balance != 20 {
+		t.Errorf("单行结果不符合预期: %+v", account)
+	}
+
+	var total int64
+	if err := db.RawQuery("SELECT COUNT(*) FROM accounts").Scan(&total); err != nil {
+		t.Fatalf("扫描标量失败: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("期望数量为 3，实际为 %d", total)
+	}
+
+	// 基础类型切片和时间按位置扫描第一列
+	var ids []int64
+	if err := db.RawQuery("SELECT id, name FROM accounts ORDER BY id").Scan(&ids); err != nil {
+		t.Fatalf("扫描基础类型切片失败: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("基础类型切片结果不符合预期: %v", ids)
+	}
+	var latest time.Time
+	if err := db.RawQuery("SELECT MAX(ts) FROM (SELECT '2024-01-02 03:04:05' AS ts UNION ALL SELECT '2023-01-01 00:00:00')").Scan(&latest); err != nil {
+		t.Fatalf("扫描时间失败: %v", err)
+	}
+	if latest.Format("2006-01-02 15:04:05") != "2024-01-02 03:04:05" {
+		t.Errorf("时间结果不符合预期: %v", latest)
+	}
+	var times []*time.Time
+	if err := db.RawQuery("SELECT '2024-01-02 03:04:05'").Scan(&times); err != nil || len(times) != 1 || times[0].Year() != 2024 {
+		t.Errorf("时间切片结果不符合预期: %v, err: %v", times, err)
+	}
+
+	err = db.RawQuery("SELECT * FROM accounts WHERE name = ?", "missing").Scan(&account)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("期望返回 sql.ErrNoRows，实际为 %v", err)
+	}
+}
+
+// TestSelectRawAliases 测试SELECT表达式与别名映射
+func TestSelectRawAliases(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 50, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	var stats []struct {
+		Status     string
+		Cnt        int64   `orm:"cnt"`
+		MaxBalance float64 // 通过下划线命名匹配 max_balance
+	}
+	err := db.Table("accounts").
+		Select("status").
+		SelectRaw("COUNT(*) AS cnt, MAX(balance) AS max_balance").
+		GroupBy("status").
+		OrderBy("status").
+		Get(&stats)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Cnt != 2 || stats[0].MaxBalance != 50 {
+		t.Errorf("统计结果不符合预期: %+v", stats)
+	}
+
+	var rows []struct {
+		Account
+		Bonus float64 `orm:"bonus"`
+	}
+	err = db.Table("accounts").
+		Select("accounts.*").
+		SelectRaw("balance * ? AS bonus", 2).
+		Where("status = ?", "frozen").
+		Get(&rows)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Name != "c" || rows[0].Bonus != 60 {
+		t.Errorf("嵌入结构体映射不符合预期: %+v", rows)
+	}
+}
+
+// TestAggregatesAndPluck 测试聚合函数与单列提取
+func TestAggregatesAndPluck(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 50, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	active := func() orm.QueryBuilder { return db.Table("accounts").Where("status = ?", "active") }
+
+	if sum, err := active().Sum("balance"); err != nil || sum != 60 {
+		t.Errorf("Sum 期望 60，实际为 %v (%v)", sum, err)
+	}
+	if avg, err := active().Avg("balance"); err != nil || avg != 30 {
+		t.Errorf("Avg 期望 30，实际为 %v (%v)", avg, err)
+	}
+	if min, err := active().Min("balance"); err != nil || min != 10 {
+		t.Errorf("Min 期望 10，实际为 %v (%v)", min, err)
+	}
+	if max, err := active().Max("balance"); err != nil || max != 50 {
+		t.Errorf("Max 期望 50，实际为 %v (%v)", max, err)
+	}
+	if sum, err := db.Table("accounts").Where("status = ?", "missing").Sum("balance"); err != nil || sum != 0 {
+		t.Errorf("无记录时 Sum 期望 0，实际为 %v (%v)", sum, err)
+	}
+
+	var names []string
+	if err := active().OrderBy("name", "DESC").Pluck("name", &names); err != nil {
+		t.Fatalf("Pluck失败: %v", err)
+	}
+	if len(names) != 2 || names[0] != "b" || names[1] != "a" {
+		t.Errorf("Pluck结果不符合预期: %v", names)
+	}
+}
+
+// TestExistsAndCountColumn 测试EXISTS查询和按列计数
+func TestExistsAndCountColumn(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 10, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	var statements []string
+	db.AddQueryHook(func(ctx context.Context, event *orm.QueryEvent) {
+		statements = append(statements, event.SQL)
+	})
+
+	if exists, err := db.Table("accounts").Where("status = ?", "frozen").OrderBy("name").Exists(); err != nil || !exists {
+		t.Errorf("期望存在冻结账户: %v, err: %v", exists, err)
+	}
+	if len(statements) != 1 || !strings.HasPrefix(statements[0], "SELECT EXISTS (SELECT 1 FROM `accounts` WHERE status = ? LIMIT 1)") {
+		t.Errorf("Exists应使用EXISTS子查询: %v", statements)
+	}
+	if exists, err := db.Table("accounts").Where("status = ?", "closed").Exists(); err != nil || exists {
+		t.Errorf("不应存在关闭账户: %v, err: %v", exists, err)
+	}
+
+	if count, err := db.Table("accounts").Count("distinct balance"); err != nil || count != 2 {
+		t.Errorf("去重计数期望2，实际为 %d, err: %v", count, err)
+	}
+	if _, err := db.Exec("INSERT INTO accounts (name, balance, status) VALUES ('d', 0, NULL)"); err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+	if count, err := db.Table("accounts").Count("status"); err != nil || count != 3 {
+		t.Errorf("按列计数不应包含NULL，实际为 %d, err: %v", count, err)
+	}
+	if count, err := db.Table("accounts").Count(); err != nil || count != 4 {
+		t.Errorf("总数期望4，实际为 %d, err: %v", count, err)
+	}
+}
+
+// TestCountColumn 测试按列统计和去重统计
+func TestCountColumn(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 10, Status: "active"},
+		Account{Name: "c", Balance: 20, Status: "frozen"},
+	)
+	if _, err := db.Exec("INSERT INTO accounts (name, balance, status) VALUES ('d', 20, NULL)"); err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+
+	var statements []string
+	db.AddQueryHook(func(ctx context.Context, event *orm.QueryEvent) {
+		statements = append(statements, event.SQL)
+	})
+
+	if count, err := db.Table("accounts").CountColumn("status"); err != nil || count != 3 {
+		t.Errorf("按列统计不应包含NULL，期望3，实际为 %d, err: %v", count, err)
+	}
+	if count, err := db.Table("accounts").CountDistinct("status"); err != nil || count != 2 {
+		t.Errorf("去重统计期望2，实际为 %d, err: %v", count, err)
+	}
+	if count, err := db.Table("accounts").Where("balance > ?", 5).CountDistinct("balance"); err != nil || count != 2 {
+		t.Errorf("带条件的去重统计期望2，实际为 %d, err: %v", count, err)
+	}
+	if len(statements) < 2 || statements[0] != "SELECT COUNT(`status`) FROM `accounts`" ||
+		statements[1] != "SELECT COUNT(DISTINCT `status`) FROM `accounts`" {
+		t.Errorf("统计SQL不符合预期: %v", statements)
+	}
+
+	// 分组后统计分组数
+	if count, err := db.Table("accounts").Select("balance").GroupBy("balance").CountColumn("balance"); err != nil || count != 2 {
+		t.Errorf("分组后统计期望2组，实际为 %d, err: %v", count, err)
+	}
+}
+
+// TestGroupByHaving 测试分组、HAVING参数顺序及分组后的计数
+func TestGroupByHaving(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 50, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+		Account{Name: "d", Balance: 5, Status: "frozen"},
+		Account{Name: "e", Balance: 70, Status: "closed"},
+	)
+
+	grouped := func() orm.QueryBuilder {
+		return db.Table("accounts").
+			SelectRaw("status || ? AS label, SUM(balance) AS total", "-x").
+			Where("balance > ?", 1).
+			GroupByRaw("status || ?", "-x").
+			Having("SUM(balance) > ?", 20).
+			HavingRaw("COUNT(*) > ? OR MAX(balance) > ?", 1, 60)
+	}
+
+	query, args := grouped().OrderBy("label").Limit(10).ToSQL()
+	expected := "SELECT status || ? AS label, SUM(balance) AS total FROM `accounts` WHERE balance > ? GROUP BY status || ? HAVING SUM(balance) > ? AND (COUNT(*) > ? OR MAX(balance) > ?) ORDER BY `label` ASC LIMIT 10"
+	if query != expected {
+		t.Errorf("分组SQL不符合预期:\n%s\n%s", query, expected)
+	}
+	if len(args) != 6 || args[0] != "-x" || args[1] != 1 || args[2] != "-x" || args[3] != 20 || args[5] != 60 {
+		t.Errorf("参数顺序不符合预期: %v", args)
+	}
+
+	var rows []struct {
+		Label string  `orm:"label"`
+		Total float64 `orm:"total"`
+	}
+	if err := grouped().OrderBy("label").Get(&rows); err != nil {
+		t.Fatalf("分组查询失败: %v", err)
+	}
+	if len(rows) != 3 || rows[0].Label != "active-x" || rows[0].Total != 60 || rows[1].Label != "closed-x" {
+		t.Errorf("分组结果不符合预期: %+v", rows)
+	}
+
+	// 分组查询的计数为分组数
+	if count, err := grouped().Count(); err != nil || count != 3 {
+		t.Errorf("分组计数期望3，实际为 %d, err: %v", count, err)
+	}
+	var lastPage []struct {
+		Label string `orm:"label"`
+	}
+	page, err := grouped().OrderBy("label").Paginate(2, 2, &lastPage)
+	if err != nil || page.Total != 3 || len(lastPage) != 1 || lastPage[0].Label != "frozen-x" {
+		t.Errorf("分组分页不符合预期: %+v, %+v, err: %v", page, lastPage, err)
+	}
+}
+
+// TestJoinArgs 测试带参数的JOIN、表别名和CROSS JOIN
+func TestJoinArgs(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 50, Status: "active"},
+	)
+	for _, statement := range []string{
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, account_id INTEGER, status TEXT, amount REAL)`,
+		`INSERT INTO orders (account_id, status, amount) VALUES (1, 'paid', 5), (1, 'paid', 7), (1, 'pending', 100), (2, 'pending', 9)`,
+		`CREATE TABLE currencies (code TEXT)`,
+		`INSERT INTO currencies (code) VALUES ('CNY'), ('USD')`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化数据失败: %v", err)
+		}
+	}
+
+	paid := func() orm.QueryBuilder {
+		return db.Table("accounts").
+			Select("accounts.name").
+			SelectRaw("SUM(o.amount) AS total").
+			LeftJoin("orders o", "o.account_id = accounts.id AND o.status = ?", "paid").
+			Where("accounts.balance >= ?", 10).
+			GroupBy("accounts.name")
+	}
+
+	query, args := paid().ToSQL()
+	if !strings.Contains(query, "LEFT JOIN `orders` AS `o` ON o.account_id = accounts.id AND o.status = ? WHERE accounts.balance >= ?") {
+		t.Errorf("JOIN语句不符合预期: %s", query)
+	}
+	if len(args) != 2 || args[0] != "paid" || args[1] != 10 {
+		t.Errorf("JOIN参数应位于WHERE参数之前: %v", args)
+	}
+
+	var totals []struct {
+		Name  string          `orm:"name"`
+		Total sql.NullFloat64 `orm:"total"`
+	}
+	if err := paid().OrderBy("accounts.name").Get(&totals); err != nil {
+		t.Fatalf("JOIN查询失败: %v", err)
+	}
+	if len(totals) != 2 || totals[0].Total.Float64 != 12 || totals[1].Total.Valid {
+		t.Errorf("JOIN结果不符合预期: %+v", totals)
+	}
+	if count, err := paid().Count(); err != nil || count != 2 {
+		t.Errorf("JOIN计数期望2，实际为 %d, err: %v", count, err)
+	}
+
+	if count, err := db.Table("accounts").CrossJoin("currencies AS c").Count(); err != nil || count != 4 {
+		t.Errorf("CROSS JOIN计数期望4，实际为 %d, err: %v", count, err)
+	}
+	query, _ = orm.New(&orm.Config{Type: orm.SQLServer}).Table("accounts").CrossJoin("dbo.currencies").ToSQL()
+	if query != "SELECT * FROM [accounts] CROSS JOIN [dbo].[currencies]" {
+		t.Errorf("限定表名应逐段加引号: %s", query)
+	}
+}
+
+// TestPaginate 测试分页查询
+func TestPaginate(t *testing.T) {
+	db := newTestORM(t)
+	for i := 0; i < 7; i++ {
+		seedAccounts(t, db, Account{Name: string(rune('a' + i)), Balance: float64(i), Status: "active"})
+	}
+	seedAccounts(t, db, Account{Name: "z", Status: "frozen"})
+
+	var accounts []Account
+	page, err := db.Model(&Account{}).Where("status = ?", "active").OrderBy("name").Paginate(3, 3, &accounts)
+	if err != nil {
+		t.Fatalf("分页查询失败: %v", err)
+	}
+	if page.Total != 7 || page.Page != 3 || page.PerPage != 3 || page.LastPage != 3 {
+		t.Errorf("分页信息不符合预期: %+v", page)
+	}
+	if len(accounts) != 1 || accounts[0].Name != "g" {
+		t.Errorf("分页数据不符合预期: %+v", accounts)
+	}
+}
+
+// TestChunkAndCursor 测试分批处理与流式游标
+func TestChunkAndCursor(t *testing.T) {
+	db := newTestORM(t)
+	for i := 0; i < 7; i++ {
+		seedAccounts(t, db, Account{Name: string(rune('a' + i)), Balance: float64(i), Status: "active"})
+	}
+
+	var batch []Account
+	var sizes []int
+	err := db.Model(&Account{}).OrderBy("id").Chunk(3, &batch, func() error {
+		sizes = append(sizes, len(batch))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("分批处理失败: %v", err)
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[2] != 1 {
+		t.Errorf("批次大小不符合预期: %v", sizes)
+	}
+
+	cursor, err := db.Model(&Account{}).Where("balance >= ?", 5).OrderBy("id").Cursor()
+	if err != nil {
+		t.Fatalf("创建游标失败: %v", err)
+	}
+	defer cursor.Close()
+
+	var names []string
+	for cursor.Next() {
+		var account Account
+		if err := cursor.Scan(&account); err != nil {
+			t.Fatalf("游标扫描失败: %v", err)
+		}
+		names = append(names, account.Name)
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatalf("游标迭代失败: %v", err)
+	}
+	if len(names) != 2 || names[0] != "f" || names[1] != "g" {
+		t.Errorf("游标结果不符合预期: %v", names)
+	}
+
+	var total float64
+	var last *Account
+	err = db.Model(&Account{}).OrderBy("id").Iterate(func(dest interface{}) error {
+		account := dest.(*Account)
+		if last != nil && last != account {
+			t.Error("Iterate应复用同一个结构体")
+		}
+		last = account
+		total += account.Balance
+		if account.Name == "e" {
+			return errors.New("停止")
+		}
+		return nil
+	})
+	if err == nil || total != 10 {
+		t.Errorf("Iterate应在fn返回错误时停止: %v, 合计 %v", err, total)
+	}
+	if err := db.Table("accounts").Iterate(func(interface{}) error { return nil }); err == nil {
+		t.Error("未通过Model创建时Iterate应返回错误")
+	}
+
+	rows, err := db.Model(&Account{}).Select("name").Where("id = ?", 1).Rows()
+	if err != nil {
+		t.Fatalf("获取结果集失败: %v", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("结果集应有一行")
+	}
+	var name string
+	if err := rows.Scan(&name); err != nil || name != "a" {
+		t.Errorf("结果集扫描失败: %q, err: %v", name, err)
+	}
+}
+
+// TestFindInBatches 测试按主键分批处理
+func TestFindInBatches(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Status: "active"},
+		Account{Name: "b", Status: "frozen"},
+		Account{Name: "c", Status: "active"},
+		Account{Name: "d", Status: "active"},
+		Account{Name: "e", Status: "frozen"},
+		Account{Name: "f", Status: "active"},
+	)
+
+	var accounts []Account
+	var batches [][]string
+	err := db.Model(&Account{}).Where("status = ?", "active").OrWhere("name = ?", "e").OrderBy("name", "DESC").
+		FindInBatches(&accounts, 2, func(tx orm.Tx, batch int) error {
+			var names []string
+			for _, account := range accounts {
+				names = append(names, account.Name)
+			}
+			batches = append(batches, names)
+			return tx.Table("accounts").Where("id = ?", accounts[0].ID).UpdateColumns(map[string]interface{}{"balance": batch})
+		})
+	if err != nil {
+		t.Fatalf("分批处理失败: %v", err)
+	}
+	if fmt.Sprint(batches) != "[[a c] [d e] [f]]" {
+		t.Errorf("批次不符合预期: %v", batches)
+	}
+	if sum, _ := db.Model(&Account{}).Sum("balance"); sum != 6 {
+		t.Errorf("每批在事务中的写入应已提交，余额合计为 %v", sum)
+	}
+
+	// fn返回错误时回滚该批并停止
+	calls := 0
+	err = db.Model(&Account{}).FindInBatches(&accounts, 4, func(tx orm.Tx, batch int) error {
+		calls++
+		if err := tx.Table("accounts").UpdateColumns(map[string]interface{}{"balance": 0}); err != nil {
+			return err
+		}
+		return fmt.Errorf("停止")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("期望第一批返回错误后停止: %v, 调用 %d 次", err, calls)
+	}
+	if sum, _ := db.Model(&Account{}).Sum("balance"); sum != 6 {
+		t.Errorf("出错的批次应回滚，余额合计为 %v", sum)
+	}
+}
+
+// Setting 配置项模型（以key作为主键）
+type Setting struct {
+	Key   string `orm:"key,primary"`
+	Value string `orm:"value"`
+	Note  string `orm:"note"`
+}
+
+// TestUpsert 测试插入或更新
+func TestUpsert(t *testing.T) {
+	db := newTestORM(t)
+	if _, err := db.Exec("CREATE TABLE settings (key VARCHAR(50) PRIMARY KEY, value TEXT, note TEXT)"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+
+	if err := db.Table("settings").InsertOrUpdate(&Setting{Key: "theme", Value: "dark", Note: "初始"}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if err := db.Table("settings").InsertOrUpdate(&Setting{Key: "theme", Value: "light", Note: "更新"}); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if err := db.Table("settings").OnConflict("key").DoUpdate("value").
+		InsertBatch([]Setting{{Key: "theme", Value: "blue", Note: "忽略"}, {Key: "lang", Value: "zh"}}); err != nil {
+		t.Fatalf("批量upsert失败: %v", err)
+	}
+
+	var settings []Setting
+	if err := db.Table("settings").OrderBy("key").Get(&settings); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(settings) != 2 || settings[1].Value != "blue" || settings[1].Note != "更新" {
+		t.Errorf("upsert结果不符合预期: %+v", settings)
+	}
+
+	if err := db.Table("settings").Upsert(&Setting{Key: "lang", Value: "en", Note: "单条"}, "key"); err != nil {
+		t.Fatalf("Upsert失败: %v", err)
+	}
+	if err := db.Table("settings").Upsert([]*Setting{{Key: "lang", Value: "fr"}, {Key: "tz", Value: "utc"}}); err != nil {
+		t.Fatalf("批量Upsert失败: %v", err)
+	}
+	var lang Setting
+	if err := db.Table("settings").FindByID("lang", &lang); err != nil || lang.Value != "fr" {
+		t.Errorf("Upsert未更新已有记录: %+v, err: %v", lang, err)
+	}
+	if count, _ := db.Table("settings").Count(); count != 3 {
+		t.Errorf("Upsert后期望3条记录，实际为 %d", count)
+	}
+
+	expected := map[orm.DatabaseType]string{
+		orm.MySQL:      "INSERT INTO `settings` (`key`, `value`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`)",
+		orm.PostgreSQL: `INSERT INTO "settings" ("key", "value") VALUES (?, ?) ON CONFLICT ("key") DO UPDATE SET "value" = EXCLUDED."value"`,
+		orm.SQLServer:  "MERGE INTO [settings] AS target USING (VALUES (?, ?)) AS source ([key], [value]) ON target.[key] = source.[key] WHEN MATCHED THEN UPDATE SET target.[value] = source.[value] WHEN NOT MATCHED THEN INSERT ([key], [value]) VALUES (source.[key], source.[value]);",
+		orm.ClickHouse: "INSERT INTO `settings` (`key`, `value`) VALUES (?, ?)",
+		orm.Oracle:     `MERGE INTO "settings" target USING (SELECT ? AS "key", ? AS "value" FROM dual) source ON (target."key" = source."key") WHEN MATCHED THEN UPDATE SET target."value" = source."value" WHEN NOT MATCHED THEN INSERT ("key", "value") VALUES (source."key", source."value")`,
+	}
+	for dbType, want := range expected {
+		dialect := orm.NewDatabaseManager(orm.New(&orm.Config{Type: dbType})).GetDialect()
+		got := dialect.UpsertSQL("settings", []string{"key", "value"}, "(?, ?)", []string{"key"}, []string{"value"})
+		if got != want {
+			t.Errorf("%s: 期望SQL为 %q，实际为 %q", dbType, want, got)
+		}
+
+		// 带schema的表名按各部分分别加引号
+		qualified := dialect.UpsertSQL("app.settings", []string{"key", "value"}, "(?, ?)", []string{"key"}, []string{"value"})
+		if want := strings.Replace(want, dialect.Quote("settings"), dialect.Quote("app")+"."+dialect.Quote("settings"), 1); qualified != want {
+			t.Errorf("%s: 期望SQL为 %q，实际为 %q", dbType, want, qualified)
+		}
+	}
+}
+
+// TestInsertIgnore 测试插入时跳过冲突的记录
+func TestInsertIgnore(t *testing.T) {
+	db := newTestORM(t)
+	if _, err := db.Exec("CREATE UNIQUE INDEX idx_accounts_name ON accounts (name)"); err != nil {
+		t.Fatalf("创建索引失败: %v", err)
+	}
+	seedAccounts(t, db, Account{Name: "a", Balance: 1, Status: "active"})
+
+	// 未指定冲突列时跳过任意唯一约束上的冲突
+	query, _ := db.Model(&Account{}).OnConflictDoNothing().ToSQLInsert(&Account{Name: "a"})
+	if !strings.HasSuffix(query, "ON CONFLICT DO NOTHING") {
+		t.Errorf("期望生成不带冲突列的 ON CONFLICT DO NOTHING，实际为 %s", query)
+	}
+	if err := db.Model(&Account{}).InsertIgnore([]Account{
+		{Name: "a", Balance: 99, Status: "changed"},
+		{Name: "b", Balance: 2, Status: "active"},
+	}); err != nil {
+		t.Fatalf("批量插入失败: %v", err)
+	}
+	affected, err := db.Model(&Account{}).OnConflictDoNothing("name").InsertAffected(&Account{Name: "b"})
+	if err != nil || affected != 0 {
+		t.Errorf("冲突的记录应被跳过，实际插入 %d 行, err: %v", affected, err)
+	}
+
+	var accounts []Account
+	if err := db.Model(&Account{}).OrderBy("name").Get(&accounts); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Balance != 1 || accounts[0].Status != "active" {
+		t.Errorf("已有记录不应被更新: %+v", accounts)
+	}
+
+	dialect := orm.NewDatabaseManager(orm.New(&orm.Config{Type: orm.MySQL})).GetDialect()
+	want := "INSERT INTO `accounts` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name` = `name`"
+	if got := dialect.UpsertSQL("accounts", []string{"name"}, "(?)", nil, nil); got != want {
+		t.Errorf("MySQL期望SQL为 %q，实际为 %q", want, got)
+	}
+
+	query, _ = orm.New(&orm.Config{Type: orm.PostgreSQL}).Table("app.accounts").OnConflictDoNothing().ToSQLInsert(&Account{Name: "a"})
+	if want := `INSERT INTO "app"."accounts" ("name", "balance", "status") VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`; query != want {
+		t.Errorf("带schema的表名期望SQL为 %q，实际为 %q", want, query)
+	}
+}
+
+// TestInsertBatchChunks 测试批量插入分块与自增主键处理
+func TestInsertBatchChunks(t *testing.T) {
+	db := newTestORM(t)
+
+	accounts := []*Account{
+		{Name: "a", Status: "active"},
+		{Name: "b", Status: "active"},
+		{ID: 100, Name: "c", Status: "active"},
+		{Name: "d", Status: "active"},
+		{Name: "e", Status: "active"},
+	}
+	if err := db.Model(&Account{}).BatchSize(2).InsertBatch(accounts); err != nil {
+		t.Fatalf("批量插入失败: %v", err)
+	}
+
+	count, err := db.Model(&Account{}).Count()
+	if err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("期望数量为 5，实际为 %d", count)
+	}
+
+	var ids []int64
+	if err := db.Model(&Account{}).Where("name = ?", "c").Pluck("id", &ids); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != 100 {
+		t.Errorf("期望显式主键为 100，实际为 %v", ids)
+	}
+	if accounts[0].ID != 1 || accounts[1].ID != 2 || accounts[2].ID != 100 || accounts[3].ID != 101 || accounts[4].ID != 102 {
+		t.Errorf("批量插入应回填自增主键: %d %d %d %d %d",
+			accounts[0].ID, accounts[1].ID, accounts[2].ID, accounts[3].ID, accounts[4].ID)
+	}
+
+	values := []Account{{Name: "x"}, {Name: "y"}}
+	if err := db.Model(&Account{}).InsertBatch(values); err != nil || values[0].ID != 103 || values[1].ID != 104 {
+		t.Errorf("值切片应回填自增主键: %+v, err: %v", values, err)
+	}
+	single := &Account{Name: "z"}
+	if err := db.Model(&Account{}).Insert(single); err != nil || single.ID != 105 {
+		t.Errorf("Insert应回填自增主键: %+v, err: %v", single, err)
+	}
+	if _, err := db.Model(&Account{}).WhereIn("id", 103, 104, 105).DeleteAffected(); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+
+	// 分块中途失败时整批回滚
+	err = db.Model(&Account{}).BatchSize(1).InsertBatch([]Account{{Name: "f"}, {ID: 100, Name: "dup"}})
+	if err == nil {
+		t.Fatal("期望主键冲突导致插入失败")
+	}
+	if count, _ := db.Model(&Account{}).Count(); count != 5 {
+		t.Errorf("期望回滚后数量仍为 5，实际为 %d", count)
+	}
+}
+
+// TestTruncateAndDeleteByIDs 测试清空表和按主键分块删除
+func TestTruncateAndDeleteByIDs(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Status: "active"},
+		Account{Name: "b", Status: "active"},
+		Account{Name: "c", Status: "frozen"},
+		Account{Name: "d", Status: "active"},
+		Account{Name: "e", Status: "active"},
+	)
+
+	var statements []string
+	db.AddQueryHook(func(ctx context.Context, event *orm.QueryEvent) {
+		if event.Operation == "DELETE" {
+			statements = append(statements, event.SQL)
+		}
+	})
+
+	affected, err := db.Model(&Account{}).BatchSize(2).DeleteByIDs(1, 2, 3, 4, 99)
+	if err != nil {
+		t.Fatalf("按主键删除失败: %v", err)
+	}
+	if affected != 4 || len(statements) != 3 {
+		t.Errorf("期望分3块删除4条记录，实际删除 %d 条，语句 %v", affected, statements)
+	}
+
+	// 已有条件与主键条件同时生效
+	affected, err = db.Model(&Account{}).Where("status = ?", "frozen").DeleteByIDs(5)
+	if err != nil || affected != 0 {
+		t.Errorf("条件不满足时不应删除，实际删除 %d 条, err: %v", affected, err)
+	}
+
+	if err := db.Truncate(&Account{}); err != nil {
+		t.Fatalf("清空表失败: %v", err)
+	}
+	if count, _ := db.Model(&Account{}).Count(); count != 0 {
+		t.Errorf("清空后期望0条记录，实际为 %d", count)
+	}
+
+	// 自增序列被重置
+	seedAccounts(t, db, Account{Name: "f", Status: "active"})
+	var ids []int64
+	if err := db.Model(&Account{}).Pluck("id", &ids); err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("清空后自增主键应从1开始，实际为 %v, err: %v", ids, err)
+	}
+}
+
+// Note 带时间戳的笔记模型
+type Note struct {
+	ID        int64     `orm:"id,primary,auto_increment"`
+	Title     string    `orm:"title"`
+	CreatedAt time.Time `orm:"created_at"`
+	UpdatedAt time.Time `orm:"updated_at"`
+}
+
+// createNotesTable 创建笔记表
+func createNotesTable(t *testing.T, db *orm.ORM) {
+	t.Helper()
+	if _, err := db.Exec(`CREATE TABLE note (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT,
+		created_at DATETIME,
+		updated_at DATETIME
+	)`); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+}
+
+// TestTimestamps 测试创建时间与更新时间的自动填充
+func TestTimestamps(t *testing.T) {
+	db := newTestORM(t)
+	createNotesTable(t, db)
+
+	explicit := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	note := &Note{Title: "a"}
+	if err := db.Model(&Note{}).Insert(note); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if note.CreatedAt.IsZero() || note.UpdatedAt.IsZero() {
+		t.Errorf("期望插入时自动填充时间戳: %+v", note)
+	}
+
+	if err := db.Model(&Note{}).InsertBatch([]Note{{Title: "b", CreatedAt: explicit}}); err != nil {
+		t.Fatalf("批量插入失败: %v", err)
+	}
+
+	var notes []Note
+	if err := db.Model(&Note{}).Where("title = ?", "b").Get(&notes); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(notes) != 1 || !notes[0].CreatedAt.Equal(explicit) || notes[0].UpdatedAt.IsZero() {
+		t.Errorf("显式设置的创建时间不应被覆盖: %+v", notes)
+	}
+
+	if err := db.Model(&Note{}).Where("title = ?", "b").UpdateColumns(map[string]interface{}{"title": "c"}); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	notes = nil
+	if err := db.Model(&Note{}).Where("title = ?", "c").Get(&notes); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(notes) != 1 || !notes[0].UpdatedAt.After(explicit) {
+		t.Errorf("期望更新时刷新更新时间: %+v", notes)
+	}
+
+	// upsert更新已有记录时保留原创建时间
+	id := notes[0].ID
+	if err := db.Model(&Note{}).Upsert(&Note{ID: id, Title: "d"}); err != nil {
+		t.Fatalf("Upsert失败: %v", err)
+	}
+	if err := db.Model(&Note{}).InsertOrUpdate(&Note{ID: id, Title: "e"}); err != nil {
+		t.Fatalf("InsertOrUpdate失败: %v", err)
+	}
+	var upserted Note
+	if err := db.Model(&Note{}).FindByID(id, &upserted); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if upserted.Title != "e" || !upserted.CreatedAt.Equal(explicit) {
+		t.Errorf("upsert不应覆盖已有记录的创建时间: %+v", upserted)
+	}
+}
+
+// Document 带版本号的文档模型
+type Document struct {
+	ID      int64  `orm:"id,primary,auto_increment"`
+	Title   string `orm:"title"`
+	Version int    `orm:"version,version"`
+}
+
+// TestOptimisticLocking 测试基于版本号的乐观锁
+func TestOptimisticLocking(t *testing.T) {
+	db := newTestORM(t)
+	if _, err := db.Exec("CREATE TABLE document (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, version INTEGER)"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO document (id, title, version) VALUES (1, 'draft', 1)"); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+
+	first := &Document{ID: 1, Title: "first", Version: 1}
+	second := &Document{ID: 1, Title: "second", Version: 1}
+
+	if err := db.Model(&Document{}).Where("id = ?", 1).Update(first); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("期望版本号递增为 2，实际为 %d", first.Version)
+	}
+
+	err := db.Model(&Document{}).Where("id = ?", 1).Update(second)
+	if !errors.Is(err, orm.ErrStaleObject) {
+		t.Errorf("期望返回 ErrStaleObject，实际为 %v", err)
+	}
+
+	var titles []string
+	if err := db.Model(&Document{}).Pluck("title", &titles); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(titles) != 1 || titles[0] != "first" {
+		t.Errorf("过期的更新不应生效: %v", titles)
+	}
+}
+
+// TestSentinelErrors 测试驱动错误转换为哨兵错误
+func TestSentinelErrors(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Status: "active"})
+
+	var account Account
+	err := db.Model(&Account{}).FindByID(99, &account)
+	if !errors.Is(err, orm.ErrRecordNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("记录不存在时应同时满足ErrRecordNotFound和sql.ErrNoRows: %v", err)
+	}
+	err = db.Model(&Account{}).Where("name = ?", "none").First(&account)
+	if !errors.Is(err, orm.ErrRecordNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("First没有匹配记录时应返回ErrRecordNotFound: %v", err)
+	}
+	err = orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return tx.Model(&Account{}).Where("name = ?", "none").First(&account)
+	})
+	if !errors.Is(err, orm.ErrRecordNotFound) {
+		t.Errorf("事务中First没有匹配记录时应返回ErrRecordNotFound: %v", err)
+	}
+
+	err = db.Model(&Account{}).Insert(&Account{ID: 1, Name: "dup"})
+	if !errors.Is(err, orm.ErrDuplicateKey) {
+		t.Errorf("主键冲突应返回ErrDuplicateKey: %v", err)
+	}
+
+	for _, statement := range []string{
+		"PRAGMA foreign_keys = ON",
+		"CREATE TABLE ledger (id INTEGER PRIMARY KEY, account_id INTEGER REFERENCES accounts(id))",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("执行 %s 失败: %v", statement, err)
+		}
+	}
+	type Ledger struct {
+		ID        int64 `orm:"id,primary"`
+		AccountID int64 `orm:"account_id"`
+	}
+	if err := db.Table("ledger").Insert(&Ledger{ID: 1, AccountID: 42}); !errors.Is(err, orm.ErrForeignKeyViolation) {
+		t.Errorf("外键不存在时应返回ErrForeignKeyViolation: %v", err)
+	}
+}
+
+// TestRowLocking 测试行锁子句
+func TestRowLocking(t *testing.T) {
+	mysqlDB := orm.New(&orm.Config{Type: orm.MySQL})
+
+	query, _ := mysqlDB.Table("accounts").Where("id = ?", 1).ForUpdate().ToSQL()
+	if query != "SELECT * FROM `accounts` WHERE id = ? FOR UPDATE" {
+		t.Errorf("FOR UPDATE SQL不符合预期: %s", query)
+	}
+
+	query, _ = mysqlDB.Table("accounts").Where("status = ?", "queued").Limit(10).ForUpdate(orm.SkipLocked).ToSQL()
+	if query != "SELECT * FROM `accounts` WHERE status = ? LIMIT 10 FOR UPDATE SKIP LOCKED" {
+		t.Errorf("SKIP LOCKED SQL不符合预期: %s", query)
+	}
+
+	query, _ = mysqlDB.Table("accounts").ForShare(orm.NoWait).ToSQL()
+	if query != "SELECT * FROM `accounts` FOR SHARE NOWAIT" {
+		t.Errorf("FOR SHARE SQL不符合预期: %s", query)
+	}
+
+	// SQL Server使用表提示
+	sqlServer := orm.New(&orm.Config{Type: orm.SQLServer})
+	query, _ = sqlServer.Table("accounts a").Where("a.id = ?", 1).ForUpdate(orm.SkipLocked).ToSQL()
+	if query != "SELECT * FROM [accounts] AS [a] WITH (UPDLOCK, ROWLOCK, READPAST) WHERE a.id = @p1" {
+		t.Errorf("SQL Server行锁SQL不符合预期: %s", query)
+	}
+	query, _ = sqlServer.Table("accounts").ForShare(orm.NoWait).ToSQL()
+	if query != "SELECT * FROM [accounts] WITH (HOLDLOCK, ROWLOCK, NOWAIT)" {
+		t.Errorf("SQL Server共享锁SQL不符合预期: %s", query)
+	}
+
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Status: "active"})
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		var accounts []Account
+		return tx.Model(&Account{}).Where("name = ?", "a").ForUpdate().Get(&accounts)
+	})
+	if err != nil {
+		t.Errorf("SQLite应忽略行锁子句: %v", err)
+	}
+}
+
+// ActiveAccount 带默认作用域的账户模型，只查询有效账户
+type ActiveAccount struct {
+	Account
+}
+
+// TableName 自定义表名
+func (ActiveAccount) TableName() string {
+	return "accounts"
+}
+
+// DefaultScope 默认只查询有效账户
+func (ActiveAccount) DefaultScope(qb orm.QueryBuilder) orm.QueryBuilder {
+	return qb.Where("status = ?", "active")
+}
+
+// TestTransactionOptions 测试事务隔离级别、只读选项和序列化失败重试
+func TestTransactionOptions(t *testing.T) {
+	db := orm.New(&orm.Config{
+		Type:         orm.SQLite,
+		Database:     ":memory:",
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+		TxRetries:    2,
+		RetryBackoff: time.Millisecond,
+	})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, balance REAL, status TEXT)"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+
+	tm := orm.NewTransactionManager(db)
+	err := tm.WithTransactionOpts(orm.LevelSerializable, false, func(tx orm.Tx) error {
+		return tx.Model(&Account{}).Insert(&Account{Name: "a", Status: "active"})
+	})
+	if err != nil {
+		t.Fatalf("可串行化事务执行失败: %v", err)
+	}
+
+	// 序列化失败时重新执行整个事务
+	attempts := 0
+	err = tm.WithTransaction(func(tx orm.Tx) error {
+		attempts++
+		if _, err := tx.Exec("UPDATE accounts SET balance = balance + 10"); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errors.New("pq: could not serialize access due to concurrent update")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("期望重试后成功且执行3次，实际执行 %d 次, err: %v", attempts, err)
+	}
+	var balance float64
+	if err := db.QueryRow("SELECT balance FROM accounts").Scan(&balance); err != nil || balance != 10 {
+		t.Errorf("失败的尝试应被回滚，余额期望为10，实际为 %v, err: %v", balance, err)
+	}
+
+	// 超过重试次数或非并发冲突错误时直接返回
+	attempts = 0
+	err = tm.WithTransaction(func(tx orm.Tx) error {
+		attempts++
+		return errors.New("Error 1213: Deadlock found when trying to get lock")
+	})
+	if err == nil || attempts != 3 {
+		t.Errorf("期望重试2次后返回错误，实际执行 %d 次, err: %v", attempts, err)
+	}
+
+	// 按驱动错误码识别，不依赖错误信息
+	for _, driverErr := range []error{
+		&pq.Error{Code: "40P01"},
+		&mysql.MySQLError{Number: 1213},
+		fmt.Errorf("更新库存: %w", mssql.Error{Number: 1205}),
+	} {
+		attempts = 0
+		tm.WithTransaction(func(tx orm.Tx) error {
+			attempts++
+			return driverErr
+		})
+		if attempts != 3 {
+			t.Errorf("%T 应按错误码重试，实际执行 %d 次", driverErr, attempts)
+		}
+	}
+
+	attempts = 0
+	tm.WithTransaction(func(tx orm.Tx) error {
+		attempts++
+		return errors.New("业务错误")
+	})
+	if attempts != 1 {
+		t.Errorf("业务错误不应重试，实际执行 %d 次", attempts)
+	}
+}
+
+// TestNestedTransactions 测试以保存点实现的嵌套事务
+func TestNestedTransactions(t *testing.T) {
+	db := newTestORM(t)
+
+	names := func() []string {
+		var result []string
+		if err := db.Table("accounts").OrderBy("id").Pluck("name", &result); err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		return result
+	}
+
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		if err := tx.Model(&Account{}).Insert(&Account{Name: "outer"}); err != nil {
+			return err
+		}
+		// 嵌套事务失败只回滚到保存点
+		err := tx.WithTransaction(func(nested orm.Tx) error {
+			if err := nested.Model(&Account{}).Insert(&Account{Name: "failed"}); err != nil {
+				return err
+			}
+			return errors.New("嵌套失败")
+		})
+		if err == nil {
+			t.Error("嵌套事务应返回fn的错误")
+		}
+		// 嵌套事务可以再嵌套，成功时随外层事务提交
+		return tx.WithTransaction(func(nested orm.Tx) error {
+			if err := nested.Commit(); err == nil {
+				t.Error("嵌套事务不应允许单独提交")
+			}
+			return nested.WithTransaction(func(inner orm.Tx) error {
+				return inner.Model(&Account{}).Insert(&Account{Name: "inner"})
+			})
+		})
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+	if got := names(); strings.Join(got, ",") != "outer,inner" {
+		t.Errorf("期望保留outer和inner，实际为 %v", got)
+	}
+
+	// 外层事务回滚时嵌套事务的修改一并回滚
+	orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		tx.WithTransaction(func(nested orm.Tx) error {
+			return nested.Model(&Account{}).Insert(&Account{Name: "discarded"})
+		})
+		return errors.New("外层失败")
+	})
+	if got := names(); len(got) != 2 {
+		t.Errorf("外层回滚后不应保留嵌套事务的修改，实际为 %v", got)
+	}
+}
+
+// TestUpdateBatch 测试单条语句批量更新
+func TestUpdateBatch(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 1, Status: "active"},
+		Account{Name: "b", Balance: 2, Status: "active"},
+		Account{Name: "c", Balance: 3, Status: "active"},
+	)
+
+	updates := []Account{
+		{ID: 1, Name: "a2", Balance: 10, Status: "vip"},
+		{ID: 3, Name: "c2", Balance: 30, Status: "active"},
+	}
+	dry := db.Model(&Account{}).DryRun()
+	if _, err := dry.UpdateBatch(updates, ""); err != nil {
+		t.Fatalf("预览批量更新失败: %v", err)
+	}
+	if recorded := dry.DryRunStatements(); len(recorded) != 1 || !strings.Contains(recorded[0].SQL, "CASE") {
+		t.Fatalf("期望生成一条CASE更新语句，实际为 %+v", recorded)
+	}
+
+	affected, err := db.Model(&Account{}).UpdateBatch(updates, "id")
+	if err != nil || affected != 2 {
+		t.Fatalf("期望更新2条记录，实际 %d 条, err: %v", affected, err)
+	}
+	var accounts []Account
+	if err := db.Model(&Account{}).OrderBy("id").Get(&accounts); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if accounts[0].Name != "a2" || accounts[0].Status != "vip" || accounts[1].Name != "b" || accounts[2].Balance != 30 {
+		t.Errorf("批量更新结果不符合预期: %+v", accounts)
+	}
+
+	// 超过批次大小时分块执行
+	for i := range accounts {
+		accounts[i].Balance = 100
+	}
+	affected, err = db.Model(&Account{}).BatchSize(2).UpdateBatch(accounts, "")
+	if err != nil || affected != 3 {
+		t.Errorf("分块更新期望3条记录，实际 %d 条, err: %v", affected, err)
+	}
+
+	if _, err := db.Model(&Account{}).UpdateBatch([]Account{{Name: "x"}}, ""); err == nil {
+		t.Error("缺少键值时应返回错误")
+	}
+}
+
+// TestScopes 测试可复用作用域与默认作用域
+func TestScopes(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 200, Status: "active"},
+		Account{Name: "c", Balance: 300, Status: "frozen"},
+	)
+
+	rich := func(q orm.QueryBuilder) orm.QueryBuilder {
+		return q.Where("balance > ?", 100)
+	}
+
+	count, err := db.Table("accounts").Scope(rich).Count()
+	if err != nil || count != 2 {
+		t.Errorf("期望作用域查询到 2 条，实际为 %d, err: %v", count, err)
+	}
+
+	count, err = db.Model(&ActiveAccount{}).Count()
+	if err != nil || count != 2 {
+		t.Errorf("期望默认作用域查询到 2 条，实际为 %d, err: %v", count, err)
+	}
+
+	// OR条件不能绕过默认作用域
+	query, _ := db.Model(&ActiveAccount{}).Where("name = ?", "a").OrWhere("name = ?", "c").ToSQL()
+	if query != "SELECT * FROM `accounts` WHERE (name = ? OR name = ?) AND status = ?" {
+		t.Errorf("默认作用域SQL不符合预期: %s", query)
+	}
+	// 原始条件中的OR同样不能绕过默认作用域
+	query, _ = db.Model(&ActiveAccount{}).Where("name = ? OR name = ?", "a", "c").ToSQL()
+	if query != "SELECT * FROM `accounts` WHERE (name = ? OR name = ?) AND status = ?" {
+		t.Errorf("原始OR条件的默认作用域SQL不符合预期: %s", query)
+	}
+	if count, err := db.Model(&ActiveAccount{}).Where("name = ? OR name = ?", "a", "c").Count(); err != nil || count != 1 {
+		t.Errorf("期望只统计到有效账户a，实际为 %d, err: %v", count, err)
+	}
+
+	var accounts []ActiveAccount
+	if err := db.Model(&ActiveAccount{}).Scope(rich).Get(&accounts); err != nil {
+		t.Fatalf("组合作用域查询失败: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Name != "b" {
+		t.Errorf("期望只查询到b，实际为 %+v", accounts)
+	}
+
+	count, err = db.Model(&ActiveAccount{}).Unscoped().Count()
+	if err != nil || count != 3 {
+		t.Errorf("期望忽略默认作用域后为 3 条，实际为 %d, err: %v", count, err)
+	}
+
+	// 默认作用域同样作用于删除
+	affected, err := db.Model(&ActiveAccount{}).Where("name = ?", "c").DeleteAffected()
+	if err != nil || affected != 0 {
+		t.Errorf("默认作用域外的记录不应被删除，affected=%d, err: %v", affected, err)
+	}
+}
+
+// TestSubQueries 测试IN与EXISTS子查询
+func TestSubQueries(t *testing.T) {
+	pg := orm.New(&orm.Config{Type: orm.PostgreSQL})
+	query, args := pg.Table("author").
+		Where("name <> ?", "x").
+		WhereIn("id", pg.Table("post").Select("author_id").Where("title = ?", "b1")).
+		ToSQL()
+	expected := `SELECT * FROM "author" WHERE name <> $1 AND "id" IN (SELECT "author_id" FROM "post" WHERE title = $2)`
+	if query != expected || len(args) != 2 {
+		t.Errorf("期望SQL为 %q，实际为 %q %v", expected, query, args)
+	}
+
+	db := newTestORM(t)
+	createRelationTables(t, db)
+
+	var names []string
+	err := db.Table("author").
+		WhereIn("id", db.Table("post").Select("author_id").Where("title LIKE ?", "a%")).
+		Pluck("name", &names)
+	if err != nil || len(names) != 1 || names[0] != "alice" {
+		t.Errorf("IN子查询结果不符合预期: %v, err: %v", names, err)
+	}
+
+	count, err := db.Table("author").
+		WhereExists(db.Table("post").Select("1").Where("post.author_id = author.id")).
+		Count()
+	if err != nil || count != 2 {
+		t.Errorf("期望有文章的作者为 2 个，实际为 %d, err: %v", count, err)
+	}
+
+	count, err = db.Table("author").
+		WhereNotExists(db.Table("post").Select("1").Where("post.author_id = author.id")).
+		Count()
+	if err != nil || count != 1 {
+		t.Errorf("期望无文章的作者为 1 个，实际为 %d, err: %v", count, err)
+	}
+
+	count, err = db.Table("author").
+		WhereNotIn("id", db.Table("profile").Select("author_id")).
+		Count()
+	if err != nil || count != 1 {
+		t.Errorf("期望无资料的作者为 1 个，实际为 %d, err: %v", count, err)
+	}
+}
+
+// TestUnion 测试UNION与UNION ALL
+func TestUnion(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 200, Status: "active"},
+		Account{Name: "c", Balance: 300, Status: "frozen"},
+	)
+
+	rich := db.Table("accounts").Select("name", "balance").Where("balance > ?", 100)
+	query, args := db.Table("accounts").Select("name", "balance").Where("status = ?", "active").
+		Union(rich).
+		OrderBy("balance", "DESC").
+		Limit(2).
+		ToSQL()
+	expected := "SELECT `name`, `balance` FROM `accounts` WHERE status = ? UNION SELECT `name`, `balance` FROM `accounts` WHERE balance > ? ORDER BY `balance` DESC LIMIT 2"
+	if query != expected || len(args) != 2 {
+		t.Errorf("期望SQL为 %q，实际为 %q %v", expected, query, args)
+	}
+
+	var accounts []Account
+	err := db.Table("accounts").Select("name", "balance").Where("status = ?", "active").
+		Union(db.Table("accounts").Select("name", "balance").Where("balance > ?", 100)).
+		OrderBy("balance", "DESC").
+		Limit(2).
+		Get(&accounts)
+	if err != nil {
+		t.Fatalf("UNION查询失败: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Name != "c" || accounts[1].Name != "b" {
+		t.Errorf("UNION结果不符合预期: %+v", accounts)
+	}
+
+	count, err := db.Table("accounts").Select("name").Where("status = ?", "active").
+		Union(db.Table("accounts").Select("name").Where("balance > ?", 100)).
+		Count()
+	if err != nil || count != 3 {
+		t.Errorf("期望UNION去重后为 3 条，实际为 %d, err: %v", count, err)
+	}
+
+	count, err = db.Table("accounts").Select("name").Where("status = ?", "active").
+		UnionAll(db.Table("accounts").Select("name").Where("balance > ?", 100)).
+		Count()
+	if err != nil || count != 4 {
+		t.Errorf("期望UNION ALL为 4 条，实际为 %d, err: %v", count, err)
+	}
+}
+
+// TestCommonTableExpressions 测试WITH与WITH RECURSIVE
+func TestCommonTableExpressions(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 200, Status: "active"},
+		Account{Name: "c", Balance: 300, Status: "frozen"},
+	)
+
+	rich := db.Table("accounts").Where("balance > ?", 100)
+	query, args := db.Table("rich").With("rich", rich).Where("status = ?", "active").ToSQL()
+	expected := "WITH `rich` AS (SELECT * FROM `accounts` WHERE balance > ?) SELECT * FROM `rich` WHERE status = ?"
+	if query != expected || len(args) != 2 || args[0] != 100 {
+		t.Errorf("期望SQL为 %q，实际为 %q %v", expected, query, args)
+	}
+
+	count, err := db.Table("rich").With("rich", rich).Where("status = ?", "active").Count()
+	if err != nil || count != 1 {
+		t.Errorf("期望CTE统计为 1，实际为 %d, err: %v", count, err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE category (id INTEGER PRIMARY KEY, parent_id INTEGER, name VARCHAR(50))`); err != nil {
+		t.Fatalf("创建分类表失败: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO category (id, parent_id, name) VALUES
+		(1, NULL, 'root'), (2, 1, 'child'), (3, 2, 'grandchild'), (4, NULL, 'other')`); err != nil {
+		t.Fatalf("写入分类数据失败: %v", err)
+	}
+
+	tree := db.Table("category").Select("id", "name").Where("id = ?", 1).
+		UnionAll(db.Table("category").Select("category.id", "category.name").
+			Join("tree", "category.parent_id = tree.id"))
+
+	var names []string
+	if err := db.Table("tree").WithRecursive("tree(id, name)", tree).OrderBy("id").Pluck("name", &names); err != nil {
+		t.Fatalf("递归CTE查询失败: %v", err)
+	}
+	if len(names) != 3 || names[2] != "grandchild" {
+		t.Errorf("递归CTE结果不符合预期: %v", names)
+	}
+
+	sqlServer := orm.New(&orm.Config{Type: orm.SQLServer})
+	query, _ = sqlServer.Table("tree").WithRecursive("tree", sqlServer.Table("category")).ToSQL()
+	if query != "WITH [tree] AS (SELECT * FROM [category]) SELECT * FROM [tree]" {
+		t.Errorf("SQL Server不应使用RECURSIVE关键字: %s", query)
+	}
+}
+
+// TestWindowFunctions 测试窗口函数
+func TestWindowFunctions(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 200, Status: "active"},
+		Account{Name: "c", Balance: 300, Status: "frozen"},
+		Account{Name: "d", Balance: 50, Status: "frozen"},
+	)
+
+	byBalance := orm.Over().PartitionBy("status").OrderBy("balance", "desc")
+	query, _ := db.Table("accounts").Select("name").RankOver(byBalance, "rnk").ToSQL()
+	if query != "SELECT `name`, RANK() OVER (PARTITION BY status ORDER BY balance DESC) AS rnk FROM `accounts`" {
+		t.Errorf("窗口函数SQL不符合预期: %s", query)
+	}
+
+	// 每个状态余额最高的账户
+	ranked := db.Table("accounts").Select("name", "status").RowNumberOver(byBalance, "rn")
+	var names []string
+	err := db.Table("ranked").With("ranked", ranked).Where("rn = ?", 1).OrderBy("name").Pluck("name", &names)
+	if err != nil {
+		t.Fatalf("窗口函数查询失败: %v", err)
+	}
+	if len(names) != 2 || names[0] != "b" || names[1] != "c" {
+		t.Errorf("分组Top1结果不符合预期: %v", names)
+	}
+
+	var totals []struct {
+		Name  string  `orm:"name"`
+		Total float64 `orm:"total"`
+	}
+	err = db.Table("accounts").Select("name").
+		SelectWindow("SUM(balance)", orm.Over().PartitionBy("status"), "total").
+		OrderBy("name").
+		Get(&totals)
+	if err != nil || len(totals) != 4 || totals[0].Total != 210 || totals[2].Total != 350 {
+		t.Errorf("窗口聚合结果不符合预期: %+v, err: %v", totals, err)
+	}
+}
+
+// TestFinders 测试FindByID、FirstOrCreate与UpdateOrCreate
+func TestFinders(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Balance: 10, Status: "active"})
+
+	var account Account
+	if err := db.Model(&Account{}).FindByID(1, &account); err != nil || account.Name != "a" {
+		t.Errorf("FindByID结果不符合预期: %+v, err: %v", account, err)
+	}
+	if err := db.Model(&Account{}).FindByID(99, &account); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("期望记录不存在时返回sql.ErrNoRows，实际为 %v", err)
+	}
+
+	var existing Account
+	if err := db.Model(&Account{}).FirstOrCreate(&existing, map[string]interface{}{"name": "a"}); err != nil {
+		t.Fatalf("FirstOrCreate查询失败: %v", err)
+	}
+	if existing.ID != 1 {
+		t.Errorf("期望返回已有记录，实际为 %+v", existing)
+	}
+
+	created := Account{Status: "new"}
+	if err := db.Model(&Account{}).FirstOrCreate(&created, map[string]interface{}{"name": "b"}); err != nil {
+		t.Fatalf("FirstOrCreate创建失败: %v", err)
+	}
+	if created.ID != 2 || created.Name != "b" || created.Status != "new" {
+		t.Errorf("FirstOrCreate创建的记录不符合预期: %+v", created)
+	}
+
+	if err := db.Model(&Account{}).UpdateOrCreate(
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"balance": 99.5},
+	); err != nil {
+		t.Fatalf("UpdateOrCreate更新失败: %v", err)
+	}
+	if err := db.Model(&Account{}).UpdateOrCreate(
+		map[string]interface{}{"name": "c"},
+		map[string]interface{}{"balance": 5, "status": "frozen"},
+	); err != nil {
+		t.Fatalf("UpdateOrCreate创建失败: %v", err)
+	}
+
+	var accounts []Account
+	if err := db.Model(&Account{}).OrderBy("id").Get(&accounts); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(accounts) != 3 || accounts[0].Balance != 99.5 || accounts[2].Name != "c" || accounts[2].Balance != 5 {
+		t.Errorf("UpdateOrCreate结果不符合预期: %+v", accounts)
+	}
+}
+
+// TestDryRun 测试DryRun模式与写操作的SQL构建
+func TestDryRun(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Balance: 10, Status: "active"})
+
+	qb := db.Model(&Account{}).DryRun()
+	account := &Account{Name: "b", Balance: 20}
+	if err := qb.Insert(account); err != nil {
+		t.Fatalf("DryRun插入失败: %v", err)
+	}
+	if account.ID != 0 {
+		t.Errorf("DryRun不应回填主键: %d", account.ID)
+	}
+	if err := qb.InsertBatch([]Account{{Name: "c"}, {Name: "d"}}); err != nil {
+		t.Fatalf("DryRun批量插入失败: %v", err)
+	}
+
+	statements := qb.DryRunStatements()
+	if len(statements) != 2 {
+		t.Fatalf("期望记录2条语句，实际为 %d: %+v", len(statements), statements)
+	}
+	if !strings.HasPrefix(statements[0].SQL, "INSERT INTO `accounts`") || len(statements[0].Args) != 3 {
+		t.Errorf("插入语句不符合预期: %+v", statements[0])
+	}
+
+	deleted, err := db.Model(&Account{}).DryRun().Where("id = ?", 1).DeleteAffected()
+	if err != nil || deleted != 0 {
+		t.Errorf("DryRun删除应返回0行: %d, %v", deleted, err)
+	}
+	if count, _ := db.Model(&Account{}).Count(); count != 1 {
+		t.Errorf("DryRun不应写入数据库，实际记录数为 %d", count)
+	}
+
+	// 乐观锁更新在DryRun下不报冲突也不递增版本号
+	doc := &Document{ID: 1, Title: "t", Version: 3}
+	versioned := db.Model(&Document{}).DryRun().Where("id = ?", 1)
+	if err := versioned.Update(doc); err != nil || doc.Version != 3 {
+		t.Errorf("DryRun乐观锁更新不符合预期: %v, version=%d", err, doc.Version)
+	}
+	if statements := versioned.DryRunStatements(); len(statements) != 1 || !strings.Contains(statements[0].SQL, "`version` = ?") {
+		t.Errorf("乐观锁语句不符合预期: %+v", statements)
+	}
+
+	query, args := db.Model(&Account{}).Where("id = ?", 1).ToSQLUpdate(&Account{Name: "x", Balance: 1})
+	if !strings.HasPrefix(query, "UPDATE `accounts` SET") || !strings.HasSuffix(query, "WHERE id = ?") || args[len(args)-1] != 1 {
+		t.Errorf("UPDATE语句不符合预期: %s %v", query, args)
+	}
+	query, args = db.Model(&Account{}).Where("status = ?", "frozen").ToSQLDelete()
+	if query != "DELETE FROM `accounts` WHERE status = ?" || len(args) != 1 {
+		t.Errorf("DELETE语句不符合预期: %s %v", query, args)
+	}
+	query, _ = db.Model(&Account{}).ToSQLInsert(account)
+	if !strings.HasPrefix(query, "INSERT INTO `accounts` (") {
+		t.Errorf("INSERT语句不符合预期: %s", query)
+	}
+}
+
+// TestRepository 测试泛型仓储的增删改查
+func TestRepository(t *testing.T) {
+	db := newTestORM(t)
+	ctx := context.Background()
+	repo := orm.NewRepository[Account](db)
+
+	account := &Account{Name: "alice", Balance: 10, Status: "active"}
+	if err := repo.Create(ctx, account); err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+	if account.ID == 0 {
+		t.Fatal("创建后应回填自增主键")
+	}
+	if err := repo.Create(ctx, &Account{Name: "bob", Status: "inactive"}); err != nil {
+		t.Fatalf("创建记录失败: %v", err)
+	}
+
+	found, err := repo.Find(ctx, account.ID)
+	if err != nil || found.Name != "alice" {
+		t.Errorf("按主键查询失败: %+v, err: %v", found, err)
+	}
+	if _, err := repo.Find(ctx, 999); !errors.Is(err, orm.ErrRecordNotFound) {
+		t.Errorf("记录不存在时应返回ErrRecordNotFound，实际为 %v", err)
+	}
+
+	active, err := repo.List(ctx, map[string]interface{}{"status": "active"})
+	if err != nil || len(active) != 1 || active[0].ID != account.ID {
+		t.Errorf("按条件查询列表失败: %+v, err: %v", active, err)
+	}
+
+	account.Balance = 20
+	if err := repo.Update(ctx, account); err != nil {
+		t.Fatalf("更新记录失败: %v", err)
+	}
+	if found, _ := repo.Find(ctx, account.ID); found.Balance != 20 {
+		t.Errorf("更新未生效: %+v", found)
+	}
+	if err := repo.Update(ctx, &Account{Name: "nobody"}); err == nil {
+		t.Error("没有主键值时更新应返回错误")
+	}
+
+	err = orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return repo.WithTx(tx).Delete(ctx, account)
+	})
+	if err != nil {
+		t.Fatalf("事务中删除记录失败: %v", err)
+	}
+	if count, _ := repo.Count(ctx, nil); count != 1 {
+		t.Errorf("删除后期望剩余1条记录，实际为 %d", count)
+	}
+}
+
+// Memo 带软删除字段的模型
+type Memo struct {
+	ID        int64      `orm:"id,primary,auto_increment"`
+	Title     string     `orm:"title"`
+	DeletedAt *time.Time `orm:"deleted_at"`
+}
+
+// TestSoftDelete 测试软删除、查询过滤、Unscoped和Restore
+func TestSoftDelete(t *testing.T) {
+	db := newTestORM(t)
+	if err := orm.NewModelManager(db).CreateTable(&Memo{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	for _, title := range []string{"a", "b", "c"} {
+		if err := db.Model(&Memo{}).Insert(&Memo{Title: title}); err != nil {
+			t.Fatalf("插入失败: %v", err)
+		}
+	}
+
+	query, _ := db.Model(&Memo{}).Where("title = ?", "a").ToSQLDelete()
+	if query != "UPDATE `memo` SET `deleted_at` = ? WHERE (title = ?) AND `deleted_at` IS NULL" {
+		t.Errorf("软删除SQL不符合预期: %s", query)
+	}
+
+	if err := db.Model(&Memo{ID: 1}).Delete(); err != nil {
+		t.Fatalf("软删除失败: %v", err)
+	}
+	if affected, err := db.Model(&Memo{}).DeleteByIDs(1, 2); err != nil || affected != 1 {
+		t.Errorf("已删除的记录不应重复删除，受影响 %d 行, err: %v", affected, err)
+	}
+
+	if count, _ := db.Model(&Memo{}).Count(); count != 1 {
+		t.Errorf("查询应排除已删除的记录，实际数量为 %d", count)
+	}
+
+	// 原始条件中的OR不能绕过软删除过滤
+	var memos []Memo
+	if err := db.Model(&Memo{}).Where("title = ? OR title = ?", "a", "c").Get(&memos); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(memos) != 1 || memos[0].Title != "c" {
+		t.Errorf("原始OR条件不应查到已删除的记录: %+v", memos)
+	}
+	var before Memo
+	db.Model(&Memo{}).Unscoped().FindByID(1, &before)
+	if affected, err := db.Model(&Memo{}).Where("title = ? OR title = ?", "a", "b").DeleteAffected(); err != nil || affected != 0 {
+		t.Errorf("已删除的记录不应被重复软删除，受影响 %d 行, err: %v", affected, err)
+	}
+	var after Memo
+	db.Model(&Memo{}).Unscoped().FindByID(1, &after)
+	if before.DeletedAt == nil || after.DeletedAt == nil || !after.DeletedAt.Equal(*before.DeletedAt) {
+		t.Errorf("已删除记录的删除时间不应改变: %v -> %v", before.DeletedAt, after.DeletedAt)
+	}
+	var deleted Memo
+	if err := db.Model(&Memo{}).Unscoped().FindByID(1, &deleted); err != nil || deleted.DeletedAt == nil {
+		t.Errorf("Unscoped应能查到已删除的记录: %+v, err: %v", deleted, err)
+	}
+	if err := db.Model(&Memo{}).FindByID(1, &Memo{}); !errors.Is(err, orm.ErrRecordNotFound) {
+		t.Errorf("已删除的记录应返回ErrRecordNotFound，实际为 %v", err)
+	}
+
+	if err := db.Model(&Memo{ID: 1}).Restore(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if count, _ := db.Model(&Memo{}).Count(); count != 2 {
+		t.Errorf("恢复后期望2条记录，实际为 %d", count)
+	}
+
+	if err := db.Model(&Memo{}).Unscoped().Where("id = ?", 2).Delete(); err != nil {
+		t.Fatalf("物理删除失败: %v", err)
+	}
+	if count, _ := db.Model(&Memo{}).Unscoped().Count(); count != 2 {
+		t.Errorf("Unscoped删除应直接删除记录，剩余 %d", count)
+	}
+	if err := db.Model(&Account{}).Restore(); err == nil {
+		t.Error("没有软删除字段的模型恢复时应返回错误")
+	}
+}
+
+// TestNamedParameters 测试命名参数绑定
+func TestNamedParameters(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	var names []string
+	err := db.Table("accounts").
+		Where("status = :status AND balance >= :min", map[string]interface{}{"status": "active", "min": 15}).
+		OrWhere("name IN (:names)", map[string]interface{}{"names": []string{"c", "x"}}).
+		OrderBy("id").Pluck("name", &names)
+	if err != nil {
+		t.Fatalf("命名参数查询失败: %v", err)
+	}
+	if len(names) != 2 || names[0] != "b" || names[1] != "c" {
+		t.Errorf("命名参数查询结果不符合预期: %v", names)
+	}
+
+	query, args := db.Table("accounts").Where("name = ':literal' AND status = :status", map[string]interface{}{"status": "active"}).ToSQL()
+	if !strings.Contains(query, "name = ':literal' AND status = ?") || len(args) != 1 {
+		t.Errorf("引号内的内容不应替换: %s %v", query, args)
+	}
+	pg := orm.New(&orm.Config{Type: orm.PostgreSQL})
+	query, _ = pg.Table("accounts").Where("created_at::date = :day", map[string]interface{}{"day": "2024-01-01"}).ToSQL()
+	if !strings.Contains(query, "created_at::date = $1") {
+		t.Errorf("类型转换不应替换: %s", query)
+	}
+
+	if _, err := db.Exec("UPDATE accounts SET status = :status WHERE name = :name", map[string]interface{}{"status": "closed", "name": "a"}); err != nil {
+		t.Fatalf("命名参数执行失败: %v", err)
+	}
+	var status string
+	if err := db.QueryRow("SELECT status FROM accounts WHERE name = :name", map[string]interface{}{"name": "a"}).Scan(&status); err != nil || status != "closed" {
+		t.Errorf("命名参数原始查询失败: %q, err: %v", status, err)
+	}
+
+	if _, err := db.Exec("UPDATE accounts SET status = :status", map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "缺少命名参数") {
+		t.Errorf("缺少命名参数时应返回错误: %v", err)
+	}
+}
+
+// TestUpdateNonZero 测试只更新非零值字段
+func TestUpdateNonZero(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+	)
+
+	if err := db.Model(&Account{}).UpdateNonZero(&Account{ID: 1, Name: "renamed"}); err != nil {
+		t.Fatalf("部分更新失败: %v", err)
+	}
+	var account Account
+	if err := db.Model(&Account{}).FindByID(1, &account); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if account.Name != "renamed" || account.Balance != 10 || account.Status != "active" {
+		t.Errorf("零值字段不应被更新: %+v", account)
+	}
+
+	dryRun := db.Model(&Account{}).DryRun()
+	if err := dryRun.Where("status = ?", "active").UpdateNonZero(Account{Status: "frozen"}); err != nil {
+		t.Fatalf("DryRun部分更新失败: %v", err)
+	}
+	if recorded := dryRun.DryRunStatements(); len(recorded) != 1 || recorded[0].SQL != "UPDATE `accounts` SET `status` = ? WHERE status = ?" {
+		t.Errorf("部分更新SQL不符合预期: %+v", recorded)
+	}
+
+	if err := db.Model(&Account{}).UpdateNonZero(&Account{ID: 2}); err == nil {
+		t.Error("没有非零值字段时应返回错误")
+	}
+}
+
+// TestFirst 测试查询第一条记录
+func TestFirst(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+	)
+
+	var account Account
+	if err := db.Model(&Account{}).Where("status = ?", "active").OrderBy("balance", "DESC").First(&account); err != nil {
+		t.Fatalf("查询第一条记录失败: %v", err)
+	}
+	if account.Name != "b" || account.Balance != 20 {
+		t.Errorf("第一条记录不符合预期: %+v", account)
+	}
+
+	if err := db.Model(&Account{}).Where("status = ?", "none").First(&Account{}); !errors.Is(err, orm.ErrRecordNotFound) {
+		t.Errorf("没有记录时应返回ErrRecordNotFound: %v", err)
+	}
+
+	// 结果集已关闭，单连接下后续查询不会阻塞
+	if count, err := db.Model(&Account{}).Count(); err != nil || count != 2 {
+		t.Errorf("后续查询失败: %d, err: %v", count, err)
+	}
+}
+
+// TestDistinct 测试去重查询及去重后的统计
+func TestDistinct(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 10, Status: "active"},
+		Account{Name: "c", Balance: 30, Status: "frozen"},
+	)
+
+	var statuses []string
+	if err := db.Table("accounts").Distinct().OrderBy("status").Pluck("status", &statuses); err != nil {
+		t.Fatalf("去重查询失败: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0] != "active" || statuses[1] != "frozen" {
+		t.Errorf("去重结果不符合预期: %v", statuses)
+	}
+
+	query, _ := db.Table("accounts").Distinct("status", "balance").ToSQL()
+	if query != "SELECT DISTINCT `status`, `balance` FROM `accounts`" {
+		t.Errorf("去重SQL不符合预期: %s", query)
+	}
+
+	if count, err := db.Table("accounts").Distinct("status", "balance").Count(); err != nil || count != 2 {
+		t.Errorf("去重统计应为2，实际为 %d, err: %v", count, err)
+	}
+	if sum, err := db.Table("accounts").Distinct("balance").Sum("balance"); err != nil || sum != 40 {
+		t.Errorf("去重求和应为40，实际为 %v, err: %v", sum, err)
+	}
+}
+
+// TestSubQueryValues 测试在原始条件和FROM中使用子查询
+func TestSubQueryValues(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "active"},
+		Account{Name: "c", Balance: 60, Status: "frozen"},
+	)
+
+	var names []string
+	avg := db.Table("accounts").SelectRaw("AVG(balance)").Where("status = ?", "active")
+	if err := db.Table("accounts").Where("balance > (?) AND status = ?", avg, "frozen").Pluck("name", &names); err != nil {
+		t.Fatalf("条件子查询失败: %v", err)
+	}
+	if len(names) != 1 || names[0] != "c" {
+		t.Errorf("条件子查询结果不符合预期: %v", names)
+	}
+
+	totals := db.Table("accounts").Select("status").SelectRaw("SUM(balance) AS total").Where("balance > ?", 5).GroupBy("status")
+	derived := db.Table("accounts").FromSub(totals, "t").Where("total > ?", 50)
+	query, args := derived.ToSQL()
+	want := "SELECT * FROM (SELECT `status`, SUM(balance) AS total FROM `accounts` WHERE balance > ? GROUP BY `status`) AS `t` WHERE total > ?"
+	if query != want || len(args) != 2 {
+		t.Errorf("派生表SQL不符合预期: %s %v", query, args)
+	}
+	var statuses []string
+	if err := derived.Pluck("status", &statuses); err != nil || len(statuses) != 1 || statuses[0] != "frozen" {
+		t.Errorf("派生表查询结果不符合预期: %v, err: %v", statuses, err)
+	}
+	if count, err := db.Table("accounts").FromSub(totals, "t").Count(); err != nil || count != 2 {
+		t.Errorf("派生表统计应为2，实际为 %d, err: %v", count, err)
+	}
+}
+
+// countingCache 记录写入次数的查询缓存
+type countingCache struct {
+	orm.QueryCache
+	sets int
+}
+
+// Set 写入缓存并计数
+func (c *countingCache) Set(key string, value []byte, ttl time.Duration, tags []string) {
+	c.sets++
+	c.QueryCache.Set(key, value, ttl, tags)
+}
+
+// TestQueryCache 测试查询结果缓存的命中、过期和写操作失效
+func TestQueryCache(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db, Account{Name: "a", Balance: 10, Status: "active"})
+
+	cachedAccounts := func() []Account {
+		var accounts []Account
+		if err := db.Table("accounts").Where("status = ?", "active").OrderBy("id").Cache(time.Minute).Get(&accounts); err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		return accounts
+	}
+	if got := cachedAccounts(); len(got) != 1 || got[0].Balance != 10 {
+		t.Fatalf("首次查询结果不符合预期: %+v", got)
+	}
+
+	// 原始SQL写入不会使缓存失效
+	seedAccounts(t, db, Account{Name: "b", Status: "active"})
+	if got := cachedAccounts(); len(got) != 1 {
+		t.Errorf("缓存有效期内应返回缓存结果: %+v", got)
+	}
+	if count, _ := db.Table("accounts").Cache(time.Minute).Count(); count != 2 {
+		t.Errorf("不同SQL不应命中同一缓存，实际为 %d", count)
+	}
+
+	// 通过查询构建器写入同一表后缓存失效
+	if err := db.Model(&Account{}).Insert(&Account{Name: "c", Status: "active"}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if got := cachedAccounts(); len(got) != 3 {
+		t.Errorf("写入后应重新查询: %+v", got)
+	}
+	if count, _ := db.Table("accounts").Cache(time.Minute).Count(); count != 3 {
+		t.Errorf("写入后计数缓存应失效，实际为 %d", count)
+	}
+
+	// First 命中缓存时清空旧值，原始SQL修改后需要手动失效
+	var first Account
+	db.Table("accounts").Where("name = ?", "a").Cache(time.Minute).First(&first)
+	db.Exec("UPDATE accounts SET balance = 0 WHERE name = 'a'")
+	first = Account{Balance: 99, Status: "stale"}
+	if err := db.Table("accounts").Where("name = ?", "a").Cache(time.Minute).First(&first); err != nil || first.Balance != 10 || first.Status != "active" {
+		t.Errorf("First应返回缓存的记录: %+v, err: %v", first, err)
+	}
+	db.InvalidateCache("accounts")
+	if err := db.Table("accounts").Where("name = ?", "a").Cache(time.Minute).First(&first); err != nil || first.Balance != 0 {
+		t.Errorf("手动失效后应重新查询: %+v, err: %v", first, err)
+	}
+
+	// 事务中的写操作在提交后使缓存失效
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return tx.Table("accounts").Where("name = ?", "c").Delete()
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+	if got := cachedAccounts(); len(got) != 2 {
+		t.Errorf("事务提交后缓存应失效: %+v", got)
+	}
+
+	// 自定义存储与过期
+	store := &countingCache{QueryCache: orm.NewMemoryQueryCache()}
+	db.SetQueryCache(store)
+	for i := 0; i < 2; i++ {
+		db.Table("accounts").Cache(20 * time.Millisecond).Count()
+	}
+	time.Sleep(30 * time.Millisecond)
+	db.Table("accounts").Cache(20 * time.Millisecond).Count()
+	if store.sets != 2 {
+		t.Errorf("期望写入缓存2次（首次和过期后），实际为 %d", store.sets)
+	}
+}
+
+// TestQueryCacheTags 测试子查询、CTE和预加载涉及的表写入后缓存失效
+func TestQueryCacheTags(t *testing.T) {
+	db := newTestORM(t)
+	createRelationTables(t, db)
+
+	withPosts := func() int64 {
+		count, err := db.Table("author").WhereIn("id", db.Table("post").Select("author_id")).Cache(time.Minute).Count()
+		if err != nil {
+			t.Fatalf("子查询统计失败: %v", err)
+		}
+		return count
+	}
+	fromCTE := func() int64 {
+		count, err := db.Table("recent").With("recent", db.Table("post").Where("id > ?", 1)).Cache(time.Minute).Count()
+		if err != nil {
+			t.Fatalf("CTE统计失败: %v", err)
+		}
+		return count
+	}
+	preloaded := func() []Author {
+		var authors []Author
+		if err := db.Model(&Author{}).Preload("Posts").OrderBy("id").Cache(time.Minute).Get(&authors); err != nil {
+			t.Fatalf("预加载查询失败: %v", err)
+		}
+		return authors
+	}
+	if withPosts() != 2 || fromCTE() != 2 || len(preloaded()[2].Posts) != 0 {
+		t.Fatal("首次查询结果不符合预期")
+	}
+
+	if err := db.Model(&Post{}).Insert(&Post{AuthorID: 3, Title: "c1"}); err != nil {
+		t.Fatalf("插入文章失败: %v", err)
+	}
+	if count := withPosts(); count != 3 {
+		t.Errorf("子查询的表写入后缓存应失效，实际为 %d", count)
+	}
+	if count := fromCTE(); count != 3 {
+		t.Errorf("CTE的表写入后缓存应失效，实际为 %d", count)
+	}
+	if authors := preloaded(); len(authors[2].Posts) != 1 {
+		t.Errorf("预加载的关联表写入后缓存应失效: %+v", authors[2].Posts)
+	}
+}
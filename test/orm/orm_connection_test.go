@@ -0,0 +1,307 @@
+package orm_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fastgox/utils/orm"
+)
+
+// newFileORM 创建基于SQLite文件的ORM实例，并在文件中创建带一条记录的accounts表
+func newFileORM(t *testing.T, config *orm.Config, name string) *orm.ORM {
+	t.Helper()
+
+	db := orm.New(config)
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	seed := orm.New(&orm.Config{Type: orm.SQLite, Database: config.Database})
+	if err := seed.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer seed.Close()
+	if _, err := seed.Exec("CREATE TABLE IF NOT EXISTS accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(100), balance REAL, status VARCHAR(20))"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if _, err := seed.Exec("INSERT INTO accounts (name) VALUES (?)", name); err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+	return db
+}
+
+// TestReplicas 测试读写分离：读操作路由到副本，写操作、事务和行锁使用主库
+func TestReplicas(t *testing.T) {
+	dir := t.TempDir()
+	replicaPath := filepath.Join(dir, "replica.db")
+	newFileORM(t, &orm.Config{Type: orm.SQLite, Database: replicaPath}, "replica")
+
+	db := newFileORM(t, &orm.Config{
+		Type:     orm.SQLite,
+		Database: filepath.Join(dir, "primary.db"),
+		Replicas: []orm.Config{
+			{Database: replicaPath},
+			{Database: filepath.Join(dir, "missing", "replica.db")}, // 不可用的副本会被跳过
+		},
+	}, "primary")
+
+	readName := func(qb orm.QueryBuilder) string {
+		t.Helper()
+		var names []string
+		if err := qb.Pluck("name", &names); err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if len(names) == 0 {
+			return ""
+		}
+		return names[0]
+	}
+
+	for i := 0; i < 3; i++ {
+		if name := readName(db.Table("accounts")); name != "replica" {
+			t.Fatalf("读操作应路由到副本，实际读取到 %q", name)
+		}
+	}
+	if name := readName(db.Table("accounts").UsePrimary()); name != "primary" {
+		t.Errorf("UsePrimary应读取主库，实际读取到 %q", name)
+	}
+	if name := readName(db.Table("accounts").ForUpdate()); name != "primary" {
+		t.Errorf("行锁查询应使用主库，实际读取到 %q", name)
+	}
+
+	if err := db.Model(&Account{}).Insert(&Account{Name: "written"}); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	count, _ := db.Table("accounts").Count()
+	primaryCount, _ := db.Table("accounts").UsePrimary().Count()
+	if count != 1 || primaryCount != 2 {
+		t.Errorf("写操作应写入主库: 副本 %d 条，主库 %d 条", count, primaryCount)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("开启事务失败: %v", err)
+	}
+	defer tx.Rollback()
+	if name := readName(tx.Table("accounts").OrderBy("id")); name != "primary" {
+		t.Errorf("事务中的读操作应使用主库，实际读取到 %q", name)
+	}
+	if name := readName(tx.Table("accounts").OrderBy("id").UseReplica()); name != "replica" {
+		t.Errorf("UseReplica应在事务中读取副本，实际读取到 %q", name)
+	}
+	if name := readName(db.Table("accounts").UsePrimary().UseReplica()); name != "replica" {
+		t.Errorf("UseReplica应覆盖UsePrimary，实际读取到 %q", name)
+	}
+	if name := readName(db.Table("accounts").UseReplica().ForUpdate()); name != "primary" {
+		t.Errorf("行锁查询应始终使用主库，实际读取到 %q", name)
+	}
+
+	// 关闭后副本不再被选择，读操作返回错误而不是使用已释放的连接
+	closed := orm.New(&orm.Config{Type: orm.SQLite, Database: filepath.Join(dir, "primary.db"), Replicas: []orm.Config{{Database: replicaPath}}})
+	if err := closed.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	closed.Close()
+	var names []string
+	if err := closed.Table("accounts").Pluck("name", &names); err == nil {
+		t.Error("关闭后的读操作应返回错误")
+	}
+}
+
+// TestConnectRetryAndHealthCheck 测试连接重试与后台健康检查
+func TestConnectRetryAndHealthCheck(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "later")
+
+	failing := orm.New(&orm.Config{Type: orm.SQLite, Database: filepath.Join(dir, "app.db"), RetryBackoff: time.Millisecond})
+	if err := failing.Connect(); err == nil {
+		t.Fatal("目录不存在时连接应失败")
+	}
+
+	// 目录稍后创建，重试期间连接成功
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.MkdirAll(dir, 0755)
+	}()
+	db := orm.New(&orm.Config{
+		Type:                orm.SQLite,
+		Database:            filepath.Join(dir, "app.db"),
+		ConnectRetries:      8,
+		RetryBackoff:        5 * time.Millisecond,
+		QueryRetries:        2,
+		HealthCheckInterval: 5 * time.Millisecond,
+	})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("重试后连接应成功: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Query("SELECT 1"); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if !db.Healthy() {
+		t.Errorf("连接正常时应为健康状态: %v", db.HealthError())
+	}
+	if err := db.HealthCheck(context.Background()); err != nil {
+		t.Errorf("连接正常时健康检查应成功: %v", err)
+	}
+	if stats := db.Stats(); stats.OpenConnections == 0 {
+		t.Errorf("查询后连接池应有打开的连接: %+v", stats)
+	}
+
+	// 底层连接被关闭后，健康检查应报告错误
+	db.Raw().Close()
+	deadline := time.Now().Add(time.Second)
+	for db.Healthy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if db.Healthy() || db.HealthError() == nil {
+		t.Error("连接关闭后健康检查应失败")
+	}
+	if err := db.HealthCheck(context.Background()); err == nil {
+		t.Error("连接关闭后HealthCheck应返回错误")
+	}
+}
+
+// TestTransientErrorRetry 测试写操作遇到数据库锁定时重试
+func TestTransientErrorRetry(t *testing.T) {
+	path := "file:" + filepath.Join(t.TempDir(), "locked.db") + "?_busy_timeout=0"
+	db := newFileORM(t, &orm.Config{Type: orm.SQLite, Database: path, QueryRetries: 6, RetryBackoff: 5 * time.Millisecond}, "a")
+	noRetry := newFileORM(t, &orm.Config{Type: orm.SQLite, Database: path}, "b")
+
+	// 另一个连接持有写锁一段时间
+	locker := orm.New(&orm.Config{Type: orm.SQLite, Database: path})
+	if err := locker.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer locker.Close()
+	tx, err := locker.Begin()
+	if err != nil {
+		t.Fatalf("开启事务失败: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE accounts SET balance = 1"); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+
+	if _, err := noRetry.Exec("UPDATE accounts SET status = 'x'"); err == nil {
+		t.Fatal("数据库锁定且未配置重试时应返回错误")
+	}
+
+	// 重试等待期间不持有连接的锁，Close无需等待重试结束
+	slow := orm.New(&orm.Config{Type: orm.SQLite, Database: path, QueryRetries: 3, RetryBackoff: 200 * time.Millisecond})
+	if err := slow.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := slow.Exec("UPDATE accounts SET status = 'slow'")
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	slow.Close()
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Close不应等待重试结束，耗时 %v", elapsed)
+	}
+	if err := <-done; err == nil {
+		t.Error("连接关闭后重试的语句应返回错误")
+	}
+
+	time.AfterFunc(30*time.Millisecond, func() { tx.Commit() })
+	if _, err := db.Exec("UPDATE accounts SET status = 'retried'"); err != nil {
+		t.Fatalf("锁释放后重试应成功: %v", err)
+	}
+	count, err := db.Table("accounts").Where("status = ?", "retried").Count()
+	if err != nil || count != 2 {
+		t.Errorf("期望更新2条记录，实际 %d 条, err: %v", count, err)
+	}
+}
+
+// TestNamedConnections 测试命名连接的注册与使用
+func TestNamedConnections(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"analytics", "shard_1"} {
+		config := &orm.Config{Type: orm.SQLite, Database: filepath.Join(dir, name+".db")}
+		if err := orm.Register(name, config); err != nil {
+			t.Fatalf("注册连接 %s 失败: %v", name, err)
+		}
+		defer orm.Unregister(name)
+
+		if _, err := orm.Use(name).Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(100), balance REAL, status VARCHAR(20))"); err != nil {
+			t.Fatalf("创建表失败: %v", err)
+		}
+		if err := orm.Use(name).Model(&Account{}).Insert(&Account{Name: name}); err != nil {
+			t.Fatalf("写入连接 %s 失败: %v", name, err)
+		}
+	}
+
+	var names []string
+	if err := orm.Use("shard_1").Model(&Account{}).Pluck("name", &names); err != nil || len(names) != 1 || names[0] != "shard_1" {
+		t.Errorf("命名连接应访问各自的数据库: %v, %v", names, err)
+	}
+
+	if got := orm.Connections(); len(got) != 2 || got[0] != "analytics" || got[1] != "shard_1" {
+		t.Errorf("已注册连接不符合预期: %v", got)
+	}
+	if err := orm.Register("analytics", &orm.Config{Type: orm.SQLite, Database: ":memory:"}); err == nil {
+		t.Error("重复注册应返回错误")
+	}
+	if _, err := orm.Connection("missing"); err == nil {
+		t.Error("未注册的连接应返回错误")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Use未注册的连接应panic")
+		}
+	}()
+	orm.Use("missing")
+}
+
+// TestStmtCache 测试预处理语句缓存的命中、淘汰和统计
+func TestStmtCache(t *testing.T) {
+	db := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, StmtCacheSize: 2})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	// DDL不缓存
+	if _, err := db.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, balance REAL, status TEXT)"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if stats := db.StmtCacheStats(); stats.Size != 0 || stats.Misses != 0 {
+		t.Errorf("DDL不应进入缓存: %+v", stats)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if _, err := db.Exec("INSERT INTO accounts (id, name) VALUES (?, ?)", i, "user"); err != nil {
+			t.Fatalf("插入失败: %v", err)
+		}
+	}
+	if count, err := db.Table("accounts").Count(); err != nil || count != 3 {
+		t.Fatalf("期望3条记录，实际为 %d, err: %v", count, err)
+	}
+	if stats := db.StmtCacheStats(); stats.Hits != 2 || stats.Misses != 2 || stats.Size != 2 || stats.Capacity != 2 {
+		t.Errorf("缓存统计不符合预期: %+v", stats)
+	}
+
+	// 第三条语句淘汰最久未使用的INSERT
+	var name string
+	if err := db.QueryRow("SELECT name FROM accounts WHERE id = ?", 2).Scan(&name); err != nil || name != "user" {
+		t.Fatalf("查询失败: %q, err: %v", name, err)
+	}
+	if _, err := db.Exec("INSERT INTO accounts (id, name) VALUES (?, ?)", 4, "user"); err != nil {
+		t.Fatalf("淘汰后重新预处理失败: %v", err)
+	}
+	if stats := db.StmtCacheStats(); stats.Evictions != 2 || stats.Misses != 4 || stats.Size != 2 {
+		t.Errorf("淘汰统计不符合预期: %+v", stats)
+	}
+
+	if stats := orm.New(&orm.Config{Type: orm.SQLite}).StmtCacheStats(); stats != (orm.StmtCacheStats{}) {
+		t.Errorf("未开启缓存时应返回零值: %+v", stats)
+	}
+}
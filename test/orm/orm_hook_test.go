@@ -0,0 +1,275 @@
+package orm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fastgox/utils/orm"
+)
+
+// recordingWriter 记录日志输出的测试写入器
+type recordingWriter struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (w *recordingWriter) record(level, format string, v ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = append(w.entries, level+" "+fmt.Sprintf(format, v...))
+}
+
+func (w *recordingWriter) Info(format string, v ...interface{})  { w.record("INFO", format, v...) }
+func (w *recordingWriter) Warn(format string, v ...interface{})  { w.record("WARN", format, v...) }
+func (w *recordingWriter) Error(format string, v ...interface{}) { w.record("ERROR", format, v...) }
+
+// last 获取最后一条日志
+func (w *recordingWriter) last() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.entries) == 0 {
+		return ""
+	}
+	return w.entries[len(w.entries)-1]
+}
+
+// TestQueryHooks 测试SQL执行钩子收到的事件
+func TestQueryHooks(t *testing.T) {
+	db := newTestORM(t)
+
+	var events []orm.QueryEvent
+	db.AddQueryHook(func(ctx context.Context, event *orm.QueryEvent) {
+		events = append(events, *event)
+	})
+
+	seedAccounts(t, db, Account{Name: "a", Balance: 10, Status: "active"})
+	if len(events) != 1 || events[0].Operation != "INSERT" || events[0].RowsAffected != 1 || len(events[0].Args) != 3 {
+		t.Fatalf("插入事件不符合预期: %+v", events)
+	}
+
+	var accounts []Account
+	if err := db.Model(&Account{}).With("rich", db.Table("accounts").Where("balance > ?", 5)).From("rich").Get(&accounts); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if last := events[len(events)-1]; last.Operation != "SELECT" || last.RowsAffected != -1 || last.InTransaction {
+		t.Errorf("查询事件不符合预期: %+v", last)
+	}
+
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return tx.Table("accounts").Where("id = ?", 1).UpdateColumns(map[string]interface{}{"status": "frozen"})
+	})
+	if err != nil {
+		t.Fatalf("事务执行失败: %v", err)
+	}
+	if last := events[len(events)-1]; last.Operation != "UPDATE" || !last.InTransaction || last.RowsAffected != 1 {
+		t.Errorf("事务中的事件不符合预期: %+v", last)
+	}
+
+	db.Exec("SELECT * FROM missing_table")
+	if last := events[len(events)-1]; last.Err == nil {
+		t.Error("执行失败的事件应包含错误")
+	}
+}
+
+// TestQueryLogger 测试SQL日志、慢查询阈值与参数脱敏
+func TestQueryLogger(t *testing.T) {
+	db := newTestORM(t)
+	writer := &recordingWriter{}
+	db.AddQueryHook(orm.NewQueryLogger(orm.QueryLoggerConfig{Writer: writer, SlowThreshold: time.Hour}))
+
+	seedAccounts(t, db, Account{Name: "alice", Balance: 10, Status: "active"})
+	if entry := writer.last(); !strings.HasPrefix(entry, "INFO") || !strings.Contains(entry, `"alice"`) || !strings.Contains(entry, "影响行数: 1") {
+		t.Errorf("普通SQL日志不符合预期: %s", entry)
+	}
+
+	db.Exec("SELECT * FROM missing_table")
+	if entry := writer.last(); !strings.HasPrefix(entry, "ERROR") || !strings.Contains(entry, "missing_table") {
+		t.Errorf("失败SQL日志不符合预期: %s", entry)
+	}
+
+	slowWriter := &recordingWriter{}
+	db.AddQueryHook(orm.NewQueryLogger(orm.QueryLoggerConfig{
+		Writer:        slowWriter,
+		SlowThreshold: time.Nanosecond,
+		SlowOnly:      true,
+		MaskArgs:      true,
+	}))
+	if _, err := db.Model(&Account{}).Where("name = ?", "alice").Count(); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	entry := slowWriter.last()
+	if !strings.HasPrefix(entry, "WARN") || !strings.Contains(entry, "慢查询") {
+		t.Errorf("慢查询日志不符合预期: %s", entry)
+	}
+	if strings.Contains(entry, "alice") || !strings.Contains(entry, "***") {
+		t.Errorf("参数未脱敏: %s", entry)
+	}
+
+	quietWriter := &recordingWriter{}
+	db.AddQueryHook(orm.NewQueryLogger(orm.QueryLoggerConfig{Writer: quietWriter, SlowThreshold: time.Hour, SlowOnly: true}))
+	db.Model(&Account{}).Count()
+	if len(quietWriter.entries) != 0 {
+		t.Errorf("只记录慢查询时不应记录普通SQL: %v", quietWriter.entries)
+	}
+}
+
+// TestSetLogger 测试替换和关闭SQL日志输出
+func TestSetLogger(t *testing.T) {
+	db := newTestORM(t)
+
+	first, second := &recordingWriter{}, &recordingWriter{}
+	db.SetLogger(first)
+	db.SetLogger(second)
+	seedAccounts(t, db, Account{Name: "alice", Balance: 10, Status: "active"})
+	if len(first.entries) != 0 || !strings.Contains(second.last(), "INSERT") {
+		t.Errorf("SetLogger应替换之前的日志输出: %v, %v", first.entries, second.entries)
+	}
+
+	db.SetLogger(nil)
+	db.Model(&Account{}).Count()
+	if len(second.entries) != 1 {
+		t.Errorf("关闭日志后不应再记录: %v", second.entries)
+	}
+}
+
+// TestMetrics 测试查询指标的收集与导出
+func TestMetrics(t *testing.T) {
+	db := newTestORM(t)
+	metrics := orm.NewMetrics(db, "app_db", 0.5, 0.001)
+
+	seedAccounts(t, db, Account{Name: "a", Balance: 10}, Account{Name: "b", Balance: 20})
+	db.Model(&Account{}).Count()
+	db.Exec("SELECT * FROM missing_table")
+
+	var buf strings.Builder
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatalf("导出指标失败: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		`app_db_queries_total{operation="INSERT"} 2`,
+		`app_db_queries_total{operation="SELECT"} 2`,
+		`app_db_query_errors_total{operation="SELECT"} 1`,
+		`app_db_query_errors_total{operation="INSERT"} 0`,
+		`app_db_query_duration_seconds_bucket{operation="INSERT",le="+Inf"} 2`,
+		`app_db_query_duration_seconds_count{operation="SELECT"} 2`,
+		"# TYPE app_db_query_duration_seconds histogram",
+		"app_db_connections_open ",
+		"app_db_connections_max_open 1",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("指标输出缺少 %q:\n%s", expected, output)
+		}
+	}
+
+	// 分桶按升序输出
+	if strings.Index(output, `le="0.001"`) > strings.Index(output, `le="0.5"`) {
+		t.Errorf("分桶未按升序输出:\n%s", output)
+	}
+}
+
+// HookedAccount 实现生命周期钩子的账户模型
+type HookedAccount struct {
+	ID      int64   `orm:"id,primary,auto_increment"`
+	Name    string  `orm:"name"`
+	Balance float64 `orm:"balance"`
+	Status  string  `orm:"status"`
+	Label   string  `orm:"-"`
+
+	events []string
+}
+
+// TableName 与Account共用accounts表
+func (a *HookedAccount) TableName() string {
+	return "accounts"
+}
+
+func (a *HookedAccount) BeforeInsert(tx orm.Tx) error {
+	if a.Status == "" {
+		a.Status = "pending"
+	}
+	a.events = append(a.events, "before_insert")
+	return nil
+}
+
+func (a *HookedAccount) AfterInsert(tx orm.Tx) error {
+	a.events = append(a.events, "after_insert")
+	return nil
+}
+
+func (a *HookedAccount) BeforeUpdate(tx orm.Tx) error {
+	if a.Balance < 0 {
+		return errors.New("余额不能为负")
+	}
+	return nil
+}
+
+func (a *HookedAccount) BeforeDelete(tx orm.Tx) error {
+	if tx == nil {
+		return errors.New("必须在事务中删除")
+	}
+	return nil
+}
+
+func (a *HookedAccount) AfterFind(tx orm.Tx) error {
+	a.Label = a.Name + ":" + a.Status
+	return nil
+}
+
+// TestModelLifecycleHooks 测试模型生命周期钩子
+func TestModelLifecycleHooks(t *testing.T) {
+	db := newTestORM(t)
+
+	account := &HookedAccount{Name: "alice"}
+	if err := db.Model(&HookedAccount{}).Insert(account); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if account.Status != "pending" || strings.Join(account.events, ",") != "before_insert,after_insert" {
+		t.Errorf("插入钩子未按顺序调用: %+v", account)
+	}
+
+	batch := []HookedAccount{{Name: "bob"}, {Name: "carol", Status: "active"}}
+	if err := db.Model(&HookedAccount{}).InsertBatch(batch); err != nil {
+		t.Fatalf("批量插入失败: %v", err)
+	}
+	if batch[0].Status != "pending" || batch[1].Status != "active" {
+		t.Errorf("批量插入应对每条记录调用钩子: %+v", batch)
+	}
+
+	var accounts []HookedAccount
+	if err := db.Model(&HookedAccount{}).OrderBy("id").Get(&accounts); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(accounts) != 3 || accounts[0].Label != "alice:pending" || accounts[2].Label != "carol:active" {
+		t.Errorf("AfterFind钩子未调用: %+v", accounts)
+	}
+
+	var found HookedAccount
+	if err := db.Model(&HookedAccount{}).FindByID(1, &found); err != nil || found.Label != "alice:pending" {
+		t.Errorf("FindByID应调用AfterFind钩子: %+v, err: %v", found, err)
+	}
+
+	found.Balance = -1
+	if err := db.Model(&HookedAccount{}).Update(&found); err == nil || !strings.Contains(err.Error(), "余额不能为负") {
+		t.Errorf("BeforeUpdate返回错误时应中止更新，实际为 %v", err)
+	}
+
+	if err := db.Model(&found).Delete(); err == nil {
+		t.Error("BeforeDelete返回错误时应中止删除")
+	}
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return tx.Model(&found).Delete()
+	})
+	if err != nil {
+		t.Fatalf("事务中删除失败: %v", err)
+	}
+	if count, _ := db.Table("accounts").Count(); count != 2 {
+		t.Errorf("删除后期望剩余2条记录，实际为 %d", count)
+	}
+}
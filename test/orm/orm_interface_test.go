@@ -188,7 +188,7 @@ func TestORMQueryBuilderInterface(t *testing.T) {
 	t.Logf("查询参数: %v", args)
 
 	// 验证SQL包含期望的部分
-	expectedParts := []string{"SELECT", "FROM users", "WHERE", "ORDER BY", "LIMIT", "OFFSET"}
+	expectedParts := []string{"SELECT", "FROM `users`", "WHERE", "ORDER BY", "LIMIT", "OFFSET"}
 	for _, part := range expectedParts {
 		if !contains(sql, part) {
 			t.Errorf("生成的SQL不包含期望的部分: %s", part)
@@ -210,6 +210,8 @@ func TestORMDifferentDialects(t *testing.T) {
 		{"PostgreSQL", orm.PostgreSQL},
 		{"SQLite", orm.SQLite},
 		{"SQL Server", orm.SQLServer},
+		{"ClickHouse", orm.ClickHouse},
+		{"Oracle", orm.Oracle},
 	}
 
 	for _, db := range databases {
@@ -0,0 +1,461 @@
+package orm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fastgox/utils/orm"
+)
+
+// Author 作者模型（一对一、一对多）
+type Author struct {
+	ID      int64    `orm:"id,primary,auto_increment"`
+	Name    string   `orm:"name"`
+	Profile *Profile `relation:"has_one"`
+	Posts   []Post   `relation:"has_many,foreign_key:author_id"`
+	Tags    []*Tag   `relation:"many2many,join_table:author_tags"`
+}
+
+// Tag 标签模型（多对多）
+type Tag struct {
+	ID   int64  `orm:"id,primary,auto_increment"`
+	Name string `orm:"name"`
+}
+
+// Profile 作者资料
+type Profile struct {
+	ID       int64  `orm:"id,primary,auto_increment"`
+	AuthorID int64  `orm:"author_id"`
+	Bio      string `orm:"bio"`
+}
+
+// Post 文章模型（属于作者）
+type Post struct {
+	ID       int64      `orm:"id,primary,auto_increment"`
+	AuthorID int64      `orm:"author_id"`
+	Title    string     `orm:"title"`
+	Author   *Author    `relation:"belongs_to"`
+	Comments []*Comment `relation:"has_many"`
+}
+
+// Comment 评论模型（嵌套预加载）
+type Comment struct {
+	ID       int64  `orm:"id,primary,auto_increment"`
+	PostID   int64  `orm:"post_id"`
+	Body     string `orm:"body"`
+	Approved bool   `orm:"approved"`
+}
+
+// createRelationTables 创建关联测试表并写入数据
+func createRelationTables(t *testing.T, db *orm.ORM) {
+	t.Helper()
+
+	statements := []string{
+		`CREATE TABLE author (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(100))`,
+		`CREATE TABLE profile (id INTEGER PRIMARY KEY AUTOINCREMENT, author_id INTEGER, bio TEXT)`,
+		`CREATE TABLE post (id INTEGER PRIMARY KEY AUTOINCREMENT, author_id INTEGER, title VARCHAR(100))`,
+		`INSERT INTO author (name) VALUES ('alice'), ('bob'), ('carol')`,
+		`INSERT INTO profile (author_id, bio) VALUES (1, 'alice bio'), (2, 'bob bio')`,
+		`INSERT INTO post (author_id, title) VALUES (1, 'a1'), (1, 'a2'), (2, 'b1')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化关联数据失败: %v", err)
+		}
+	}
+}
+
+// TestPreload 测试一对一、一对多和属于关联的预加载
+func TestPreload(t *testing.T) {
+	db := newTestORM(t)
+	createRelationTables(t, db)
+
+	var authors []Author
+	if err := db.Model(&Author{}).Preload("Profile").Preload("Posts").OrderBy("id").Get(&authors); err != nil {
+		t.Fatalf("预加载失败: %v", err)
+	}
+	if len(authors) != 3 {
+		t.Fatalf("期望3个作者，实际为 %d", len(authors))
+	}
+
+	if authors[0].Profile == nil || authors[0].Profile.Bio != "alice bio" {
+		t.Errorf("alice的资料加载错误: %+v", authors[0].Profile)
+	}
+	if authors[2].Profile != nil {
+		t.Errorf("carol不应有资料: %+v", authors[2].Profile)
+	}
+	if len(authors[0].Posts) != 2 || len(authors[1].Posts) != 1 || len(authors[2].Posts) != 0 {
+		t.Errorf("文章数量不符合预期: %d %d %d", len(authors[0].Posts), len(authors[1].Posts), len(authors[2].Posts))
+	}
+
+	var posts []*Post
+	if err := db.Model(&Post{}).Preload("Author").OrderBy("id").Get(&posts); err != nil {
+		t.Fatalf("预加载所属作者失败: %v", err)
+	}
+	for _, post := range posts {
+		if post.Author == nil || post.Author.ID != post.AuthorID {
+			t.Errorf("文章 %s 的作者加载错误: %+v", post.Title, post.Author)
+		}
+	}
+
+	if err := db.Model(&Author{}).Preload("Missing").Get(&authors); err == nil {
+		t.Error("未知关联应返回错误")
+	}
+
+	// 外键数量超过批次大小时分批查询并合并结果
+	var postQueries int
+	db.AddQueryHook(func(ctx context.Context, event *orm.QueryEvent) {
+		if strings.Contains(event.SQL, "FROM `post`") {
+			postQueries++
+		}
+	})
+	var batched []Author
+	if err := db.Model(&Author{}).BatchSize(2).Preload("Posts").OrderBy("id").Get(&batched); err != nil {
+		t.Fatalf("分批预加载失败: %v", err)
+	}
+	if postQueries != 2 {
+		t.Errorf("3个作者按每批2个应查询2次文章，实际为 %d", postQueries)
+	}
+	if len(batched[0].Posts) != 2 || len(batched[1].Posts) != 1 || len(batched[2].Posts) != 0 {
+		t.Errorf("分批预加载的文章数量不符合预期: %d %d %d", len(batched[0].Posts), len(batched[1].Posts), len(batched[2].Posts))
+	}
+
+	// 关联字段不参与写入
+	if err := db.Model(&Author{}).Insert(&Author{Name: "dave", Posts: []Post{{Title: "x"}}}); err != nil {
+		t.Errorf("插入带关联字段的模型失败: %v", err)
+	}
+}
+
+// TestMany2Many 测试多对多关联的预加载与中间表维护
+func TestMany2Many(t *testing.T) {
+	db := newTestORM(t)
+	createRelationTables(t, db)
+
+	for _, statement := range []string{
+		`CREATE TABLE tag (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(50))`,
+		`CREATE TABLE author_tags (author_id INTEGER, tag_id INTEGER)`,
+		`INSERT INTO tag (name) VALUES ('go'), ('sql'), ('web')`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化多对多数据失败: %v", err)
+		}
+	}
+
+	alice := &Author{ID: 1}
+	if err := db.Model(alice).Association("Tags").Attach(int64(1), &Tag{ID: 2}, int64(1)); err != nil {
+		t.Fatalf("Attach失败: %v", err)
+	}
+	// 重复添加被忽略
+	if err := db.Model(alice).Association("Tags").Attach(int64(2)); err != nil {
+		t.Fatalf("重复Attach失败: %v", err)
+	}
+	if err := db.Model(&Author{ID: 2}).Association("Tags").Attach(int64(3)); err != nil {
+		t.Fatalf("Attach失败: %v", err)
+	}
+
+	count, err := db.Table("author_tags").Count()
+	if err != nil || count != 3 {
+		t.Fatalf("期望3条中间表记录，实际为 %d, err: %v", count, err)
+	}
+
+	var authors []Author
+	if err := db.Model(&Author{}).Preload("Tags").OrderBy("id").Get(&authors); err != nil {
+		t.Fatalf("预加载多对多失败: %v", err)
+	}
+	if len(authors[0].Tags) != 2 || len(authors[1].Tags) != 1 || len(authors[2].Tags) != 0 {
+		t.Fatalf("标签数量不符合预期: %d %d %d", len(authors[0].Tags), len(authors[1].Tags), len(authors[2].Tags))
+	}
+	if authors[1].Tags[0].Name != "web" {
+		t.Errorf("期望bob的标签为web，实际为 %s", authors[1].Tags[0].Name)
+	}
+	var batched []Author
+	if err := db.Model(&Author{}).BatchSize(1).Preload("Tags").OrderBy("id").Get(&batched); err != nil {
+		t.Fatalf("分批预加载多对多失败: %v", err)
+	}
+	if len(batched[0].Tags) != 2 || len(batched[1].Tags) != 1 || len(batched[2].Tags) != 0 {
+		t.Errorf("分批预加载的标签数量不符合预期: %d %d %d", len(batched[0].Tags), len(batched[1].Tags), len(batched[2].Tags))
+	}
+
+	// Sync 替换为 {2, 3}
+	if err := db.Model(alice).Association("Tags").Sync(int64(2), int64(3)); err != nil {
+		t.Fatalf("Sync失败: %v", err)
+	}
+	var tagIDs []int64
+	if err := db.Table("author_tags").Where("author_id = ?", 1).OrderBy("tag_id").Pluck("tag_id", &tagIDs); err != nil {
+		t.Fatalf("查询中间表失败: %v", err)
+	}
+	if len(tagIDs) != 2 || tagIDs[0] != 2 || tagIDs[1] != 3 {
+		t.Errorf("Sync后的标签不符合预期: %v", tagIDs)
+	}
+
+	if err := db.Model(alice).Association("Tags").Detach(int64(2)); err != nil {
+		t.Fatalf("Detach失败: %v", err)
+	}
+	if err := db.Model(alice).Association("Tags").Detach(); err != nil {
+		t.Fatalf("Detach全部失败: %v", err)
+	}
+	count, _ = db.Table("author_tags").Where("author_id = ?", 1).Count()
+	if count != 0 {
+		t.Errorf("期望alice的关联已全部移除，实际剩余 %d", count)
+	}
+
+	if err := db.Model(alice).Association("Posts").Attach(int64(1)); err == nil {
+		t.Error("非多对多关联应返回错误")
+	}
+}
+
+// Member 使用orm标签声明多对多关联的模型
+type Member struct {
+	ID    int64   `orm:"id,primary,auto_increment"`
+	Name  string  `orm:"name"`
+	Roles []*Role `orm:"many2many:member_roles"`
+}
+
+// Role 角色模型
+type Role struct {
+	ID   int64  `orm:"id,primary,auto_increment"`
+	Name string `orm:"name"`
+}
+
+// Team 使用默认中间表的多对多关联模型
+type Team struct {
+	ID    int64   `orm:"id,primary,auto_increment"`
+	Name  string  `orm:"name"`
+	Roles []*Role `relation:"many2many"`
+}
+
+// keyNaming 自定义外键命名的命名策略
+type keyNaming struct {
+	orm.PluralNaming
+}
+
+// ForeignKey 外键列使用 fk_ 前缀
+func (keyNaming) ForeignKey(name string) string {
+	return "fk_" + strings.ToLower(name)
+}
+
+// TestMany2ManyNaming 测试默认中间表名和键列遵循表前缀与命名策略
+func TestMany2ManyNaming(t *testing.T) {
+	db := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, TablePrefix: "app_", NamingStrategy: orm.PluralNaming{}})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer db.Close()
+
+	if err := orm.NewModelManager(db).AutoMigrate(&Team{}, &Role{}); err != nil {
+		t.Fatalf("自动迁移失败: %v", err)
+	}
+	schema := orm.NewSchema(db)
+	for _, column := range []string{"team_id", "role_id"} {
+		if exists, _ := schema.HasColumn("app_team_roles", column); !exists {
+			t.Errorf("中间表 app_team_roles 应包含列 %s", column)
+		}
+	}
+
+	if err := db.Model(&Role{}).Insert(&Role{Name: "admin"}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if err := db.Model(&Team{}).Insert(&Team{Name: "core"}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if err := db.Model(&Team{ID: 1}).Association("Roles").Attach(int64(1)); err != nil {
+		t.Fatalf("Attach失败: %v", err)
+	}
+	var teams []Team
+	if err := db.Model(&Team{}).Preload("Roles").Get(&teams); err != nil {
+		t.Fatalf("预加载失败: %v", err)
+	}
+	if len(teams) != 1 || len(teams[0].Roles) != 1 || teams[0].Roles[0].Name != "admin" {
+		t.Errorf("预加载的角色不符合预期: %+v", teams)
+	}
+
+	// 命名策略实现ForeignKeyNaming时键列按其规则生成
+	custom := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, TablePrefix: "app_", NamingStrategy: keyNaming{}})
+	if err := custom.Connect(); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer custom.Close()
+	if err := orm.NewModelManager(custom).AutoMigrate(&Team{}, &Role{}); err != nil {
+		t.Fatalf("自动迁移失败: %v", err)
+	}
+	if exists, _ := orm.NewSchema(custom).HasColumn("app_team_roles", "fk_team"); !exists {
+		t.Error("中间表键列应使用命名策略的ForeignKey规则")
+	}
+}
+
+// TestMany2ManyTagAndJoinTable 测试orm标签声明多对多、自动迁移创建中间表及Append/Replace/Clear
+func TestMany2ManyTagAndJoinTable(t *testing.T) {
+	db := newTestORM(t)
+	mm := orm.NewModelManager(db)
+
+	if err := mm.AutoMigrate(&Member{}, &Role{}); err != nil {
+		t.Fatalf("自动迁移失败: %v", err)
+	}
+	if plan, err := mm.AutoMigratePlan(&Member{}, &Role{}); err != nil || len(plan) != 0 {
+		t.Errorf("迁移后不应再有变更: %v, err: %v", plan, err)
+	}
+	if exists, _ := orm.NewSchema(db).HasColumn("member_roles", "role_id"); !exists {
+		t.Fatal("自动迁移应创建中间表 member_roles")
+	}
+
+	member := &Member{Name: "alice"}
+	if err := db.Model(&Member{}).Insert(member); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	member.ID = 1
+	for _, name := range []string{"admin", "editor", "viewer"} {
+		if err := db.Model(&Role{}).Insert(&Role{Name: name}); err != nil {
+			t.Fatalf("插入失败: %v", err)
+		}
+	}
+
+	if err := db.Model(member).Association("Roles").Append(int64(1), &Role{ID: 2}); err != nil {
+		t.Fatalf("Append失败: %v", err)
+	}
+	if err := db.Model(member).Association("Roles").Replace(int64(2), int64(3)); err != nil {
+		t.Fatalf("Replace失败: %v", err)
+	}
+
+	var loaded []Member
+	if err := db.Model(&Member{}).Preload("Roles").Get(&loaded); err != nil {
+		t.Fatalf("预加载失败: %v", err)
+	}
+	if len(loaded) != 1 || len(loaded[0].Roles) != 2 || loaded[0].Roles[0].Name != "editor" {
+		t.Errorf("Replace后的角色不符合预期: %+v", loaded)
+	}
+
+	if err := db.Model(member).Association("Roles").Clear(); err != nil {
+		t.Fatalf("Clear失败: %v", err)
+	}
+	if count, _ := db.Table("member_roles").Count(); count != 0 {
+		t.Errorf("Clear后中间表应为空，实际为 %d", count)
+	}
+	if count, _ := db.Table("role").Count(); count != 3 {
+		t.Errorf("Clear不应删除角色记录，实际为 %d", count)
+	}
+}
+
+// TestNestedPreload 测试嵌套预加载及预加载条件
+func TestNestedPreload(t *testing.T) {
+	db := newTestORM(t)
+	createRelationTables(t, db)
+
+	for _, statement := range []string{
+		`CREATE TABLE comment (id INTEGER PRIMARY KEY AUTOINCREMENT, post_id INTEGER, body TEXT, approved BOOLEAN)`,
+		`INSERT INTO comment (post_id, body, approved) VALUES (1, 'c1', 1), (1, 'c2', 0), (2, 'c3', 1), (3, 'c4', 1)`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化评论数据失败: %v", err)
+		}
+	}
+
+	var authors []Author
+	err := db.Model(&Author{}).
+		Preload("Posts", func(q orm.QueryBuilder) orm.QueryBuilder {
+			return q.OrderBy("id", "DESC")
+		}).
+		Preload("Posts.Comments", func(q orm.QueryBuilder) orm.QueryBuilder {
+			return q.Where("approved = ?", true)
+		}).
+		Preload("Posts.Author").
+		Where("id = ?", 1).
+		Get(&authors)
+	if err != nil {
+		t.Fatalf("嵌套预加载失败: %v", err)
+	}
+
+	if len(authors) != 1 || len(authors[0].Posts) != 2 {
+		t.Fatalf("期望alice有2篇文章，实际为 %+v", authors)
+	}
+	posts := authors[0].Posts
+	if posts[0].Title != "a2" || posts[1].Title != "a1" {
+		t.Errorf("预加载排序未生效: %s, %s", posts[0].Title, posts[1].Title)
+	}
+	if len(posts[1].Comments) != 1 || posts[1].Comments[0].Body != "c1" {
+		t.Errorf("预加载条件未生效: %+v", posts[1].Comments)
+	}
+	if len(posts[0].Comments) != 1 || posts[0].Comments[0].Body != "c3" {
+		t.Errorf("a2的评论加载错误: %+v", posts[0].Comments)
+	}
+	if posts[0].Author == nil || posts[0].Author.Name != "alice" {
+		t.Errorf("嵌套的所属关联加载错误: %+v", posts[0].Author)
+	}
+}
+
+// TenantOrder 复合主键模型
+type TenantOrder struct {
+	TenantID int64       `orm:"tenant_id,primary"`
+	ID       int64       `orm:"id,primary"`
+	Title    string      `orm:"title"`
+	Lines    []OrderLine `relation:"has_many,foreign_key:tenant_id+order_id,references:tenant_id+id"`
+}
+
+// OrderLine 通过复合外键关联订单
+type OrderLine struct {
+	ID       int64  `orm:"id,primary,auto_increment"`
+	TenantID int64  `orm:"tenant_id"`
+	OrderID  int64  `orm:"order_id"`
+	SKU      string `orm:"sku"`
+}
+
+// TestCompositePrimaryKey 测试复合主键的建表、查询、按模型更新删除和关联匹配
+func TestCompositePrimaryKey(t *testing.T) {
+	db := newTestORM(t)
+	mm := orm.NewModelManager(db)
+	if err := mm.CreateTable(&TenantOrder{}); err != nil {
+		t.Fatalf("创建复合主键表失败: %v", err)
+	}
+	if err := mm.CreateTable(&OrderLine{}); err != nil {
+		t.Fatalf("创建明细表失败: %v", err)
+	}
+	if keys := mm.GetTableInfo(&TenantOrder{}).GetPrimaryKeys(); len(keys) != 2 || keys[1].Name != "id" {
+		t.Errorf("复合主键列不符合预期: %+v", keys)
+	}
+
+	orders := []TenantOrder{{TenantID: 1, ID: 1, Title: "t1-o1"}, {TenantID: 2, ID: 1, Title: "t2-o1"}, {TenantID: 1, ID: 2, Title: "t1-o2"}}
+	if err := db.Model(&TenantOrder{}).InsertBatch(orders); err != nil {
+		t.Fatalf("插入订单失败: %v", err)
+	}
+	if err := db.Model(&TenantOrder{}).Insert(&TenantOrder{TenantID: 1, ID: 1}); err == nil {
+		t.Error("重复的复合主键应插入失败")
+	}
+	lines := []OrderLine{{TenantID: 1, OrderID: 1, SKU: "a"}, {TenantID: 2, OrderID: 1, SKU: "b"}, {TenantID: 1, OrderID: 1, SKU: "c"}}
+	if err := db.Model(&OrderLine{}).InsertBatch(lines); err != nil {
+		t.Fatalf("插入明细失败: %v", err)
+	}
+
+	var order TenantOrder
+	if err := db.Model(&TenantOrder{}).FindByKey(&order, 2, 1); err != nil || order.Title != "t2-o1" {
+		t.Errorf("按复合主键查询失败: %+v, err: %v", order, err)
+	}
+	if err := db.Model(&TenantOrder{}).FindByID([]interface{}{1, 2}, &order); err != nil || order.Title != "t1-o2" {
+		t.Errorf("FindByID传入复合主键失败: %+v, err: %v", order, err)
+	}
+	if err := db.Model(&TenantOrder{}).FindByKey(&order, 1); err == nil {
+		t.Error("主键值数量不匹配时应返回错误")
+	}
+
+	// 未设置条件时按模型主键更新和删除
+	order.Title = "renamed"
+	if err := db.Model(&TenantOrder{}).Update(&order); err != nil {
+		t.Fatalf("按主键更新失败: %v", err)
+	}
+	if count, _ := db.Model(&TenantOrder{}).Where("title = ?", "renamed").Count(); count != 1 {
+		t.Errorf("应只更新一条记录，实际为 %d", count)
+	}
+	if err := db.Model(&TenantOrder{TenantID: 2, ID: 1}).Delete(); err != nil {
+		t.Fatalf("按主键删除失败: %v", err)
+	}
+	if count, _ := db.Model(&TenantOrder{}).Count(); count != 2 {
+		t.Errorf("删除后期望2条记录，实际为 %d", count)
+	}
+
+	var loaded []TenantOrder
+	if err := db.Model(&TenantOrder{}).Preload("Lines").OrderBy("tenant_id").OrderBy("id").Get(&loaded); err != nil {
+		t.Fatalf("复合键预加载失败: %v", err)
+	}
+	if len(loaded) != 2 || len(loaded[0].Lines) != 2 || len(loaded[1].Lines) != 0 {
+		t.Errorf("复合键关联匹配错误: %+v", loaded)
+	}
+}
@@ -0,0 +1,284 @@
+package orm_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fastgox/utils/orm"
+)
+
+// Contact 包含可空列的模型
+type Contact struct {
+	ID       int64          `orm:"id,primary,auto_increment"`
+	Name     string         `orm:"name"`
+	Email    *string        `orm:"email"`
+	Phone    sql.NullString `orm:"phone"`
+	Age      int            `orm:"age,nullable"`
+	Nickname string         `orm:"nickname,nullable"`
+	Score    *float64       `orm:"score"`
+}
+
+// TestNullableScanning 测试NULL列扫描到指针、sql.Null类型和nullable字段
+func TestNullableScanning(t *testing.T) {
+	db := newTestORM(t)
+	for _, statement := range []string{
+		`CREATE TABLE contact (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, email TEXT, phone TEXT, age INTEGER, nickname TEXT, score REAL)`,
+		`INSERT INTO contact (name, email, phone, age, nickname, score) VALUES ('full', 'a@example.com', '123', 30, 'f', 9.5)`,
+		`INSERT INTO contact (name) VALUES ('empty')`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化数据失败: %v", err)
+		}
+	}
+
+	var contacts []Contact
+	if err := db.Model(&Contact{}).OrderBy("id").Get(&contacts); err != nil {
+		t.Fatalf("包含NULL的查询失败: %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("期望2条记录，实际为 %d", len(contacts))
+	}
+
+	full, empty := contacts[0], contacts[1]
+	if full.Email == nil || *full.Email != "a@example.com" || !full.Phone.Valid || full.Phone.String != "123" ||
+		full.Age != 30 || full.Nickname != "f" || full.Score == nil || *full.Score != 9.5 {
+		t.Errorf("非NULL值扫描错误: %+v", full)
+	}
+	if empty.Email != nil || empty.Phone.Valid || empty.Age != 0 || empty.Nickname != "" || empty.Score != nil {
+		t.Errorf("NULL值应映射为nil或零值: %+v", empty)
+	}
+
+	// 游标复用结构体时，NULL列覆盖上一行的值
+	cursor, err := db.Model(&Contact{}).OrderBy("id").Cursor()
+	if err != nil {
+		t.Fatalf("创建游标失败: %v", err)
+	}
+	defer cursor.Close()
+
+	var contact Contact
+	for cursor.Next() {
+		if err := cursor.Scan(&contact); err != nil {
+			t.Fatalf("游标扫描失败: %v", err)
+		}
+	}
+	if contact.Name != "empty" || contact.Age != 0 || contact.Email != nil {
+		t.Errorf("游标扫描NULL值错误: %+v", contact)
+	}
+}
+
+// Cents 以字符串存储的金额，测试自定义Valuer/Scanner
+type Cents int64
+
+// Value 实现driver.Valuer
+func (c Cents) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", c/100, c%100), nil
+}
+
+// Scan 实现sql.Scanner
+func (c *Cents) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("unsupported cents value %T", src)
+	}
+	var whole, frac int64
+	if _, err := fmt.Sscanf(text, "%d.%d", &whole, &frac); err != nil {
+		return err
+	}
+	*c = Cents(whole*100 + frac)
+	return nil
+}
+
+// OrderItem 订单明细
+type OrderItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+// Purchase 包含自定义类型和JSON列的模型
+type Purchase struct {
+	ID       int64             `orm:"id,primary,auto_increment"`
+	Total    Cents             `orm:"total,type:TEXT"`
+	Items    []OrderItem       `orm:"items,type:JSON"`
+	Meta     map[string]string `orm:"meta,type:json"`
+	Shipping *OrderItem        `orm:"shipping,type:JSON"`
+}
+
+// TestCustomTypesAndJSONColumns 测试Valuer/Scanner字段和JSON列的读写
+func TestCustomTypesAndJSONColumns(t *testing.T) {
+	db := newTestORM(t)
+	if err := orm.NewModelManager(db).CreateTable(&Purchase{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+
+	purchase := &Purchase{
+		Total: 1205,
+		Items: []OrderItem{{SKU: "A", Qty: 2}, {SKU: "B", Qty: 1}},
+		Meta:  map[string]string{"channel": "web"},
+	}
+	if err := db.Model(&Purchase{}).Insert(purchase); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	var rawTotal, rawItems string
+	var rawShipping sql.NullString
+	if err := db.QueryRow("SELECT total, items, shipping FROM purchase").Scan(&rawTotal, &rawItems, &rawShipping); err != nil {
+		t.Fatalf("读取原始值失败: %v", err)
+	}
+	if rawTotal != "12.05" || rawItems != `[{"sku":"A","qty":2},{"sku":"B","qty":1}]` || rawShipping.Valid {
+		t.Errorf("写入的原始值不符合预期: %s, %s, %v", rawTotal, rawItems, rawShipping)
+	}
+
+	var loaded Purchase
+	if err := db.Model(&Purchase{}).FindByID(1, &loaded); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if loaded.Total != 1205 || len(loaded.Items) != 2 || loaded.Items[1].SKU != "B" ||
+		loaded.Meta["channel"] != "web" || loaded.Shipping != nil {
+		t.Errorf("读取结果不符合预期: %+v", loaded)
+	}
+
+	loaded.Shipping = &OrderItem{SKU: "SHIP", Qty: 1}
+	if err := db.Model(&Purchase{}).Where("id = ?", loaded.ID).Update(&loaded); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	var updated Purchase
+	if err := db.Model(&Purchase{}).FindByID(loaded.ID, &updated); err != nil {
+		t.Fatalf("查询更新结果失败: %v", err)
+	}
+	if updated.Shipping == nil || updated.Shipping.SKU != "SHIP" {
+		t.Errorf("JSON指针字段更新失败: %+v", updated.Shipping)
+	}
+
+	if _, err := db.Exec("UPDATE purchase SET items = 'not json'"); err != nil {
+		t.Fatalf("写入非法JSON失败: %v", err)
+	}
+	if err := db.Model(&Purchase{}).FindByID(loaded.ID, &Purchase{}); err == nil {
+		t.Error("非法JSON应返回反序列化错误")
+	}
+}
+
+// Event 时间列以不同格式存储的模型
+type Event struct {
+	ID        int64      `orm:"id,primary,auto_increment"`
+	Name      string     `orm:"name"`
+	StartsAt  time.Time  `orm:"starts_at"`
+	EndsAt    *time.Time `orm:"ends_at"`
+	CreatedAt time.Time  `orm:"created_at"`
+}
+
+// TestTimeScanning 测试时间字符串、Unix时间戳和时区配置的解析
+func TestTimeScanning(t *testing.T) {
+	db := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, Timezone: "Asia/Shanghai"})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range []string{
+		`CREATE TABLE event (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, starts_at TEXT, ends_at TEXT, created_at INTEGER)`,
+		`INSERT INTO event (name, starts_at, ends_at, created_at) VALUES ('local', '2024-03-01 08:30:00', '2024-03-01T10:00:00Z', 1709253000)`,
+		`INSERT INTO event (name, starts_at, ends_at, created_at) VALUES ('millis', '2024-03-01', NULL, 1709253000123)`,
+		`INSERT INTO event (name, starts_at, created_at) VALUES ('zero', '0000-00-00 00:00:00', NULL)`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("初始化数据失败: %v", err)
+		}
+	}
+
+	var events []Event
+	if err := db.Model(&Event{}).OrderBy("id").Get(&events); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("期望3条记录，实际为 %d", len(events))
+	}
+
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	local := events[0]
+	if !local.StartsAt.Equal(time.Date(2024, 3, 1, 8, 30, 0, 0, shanghai)) || local.StartsAt.Location().String() != "Asia/Shanghai" {
+		t.Errorf("不带时区的时间应按配置时区解析: %v", local.StartsAt)
+	}
+	if local.EndsAt == nil || !local.EndsAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339时间解析错误: %v", local.EndsAt)
+	}
+	if local.CreatedAt.Unix() != 1709253000 {
+		t.Errorf("秒级时间戳解析错误: %v", local.CreatedAt)
+	}
+
+	millis := events[1]
+	if millis.EndsAt != nil || millis.CreatedAt.UnixMilli() != 1709253000123 || millis.StartsAt.Day() != 1 {
+		t.Errorf("日期或毫秒时间戳解析错误: %+v", millis)
+	}
+	if zero := events[2]; !zero.StartsAt.IsZero() || !zero.CreatedAt.IsZero() {
+		t.Errorf("零值日期和NULL应解析为零值时间: %+v", zero)
+	}
+
+	if _, err := db.Exec(`UPDATE event SET starts_at = 'yesterday' WHERE id = 1`); err != nil {
+		t.Fatalf("写入非法时间失败: %v", err)
+	}
+	if err := db.Model(&Event{}).Get(&events); err == nil {
+		t.Error("无法解析的时间应返回错误")
+	}
+
+	invalid := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", Timezone: "Mars/Olympus"})
+	if err := invalid.Connect(); err == nil {
+		invalid.Close()
+		t.Error("无效时区应导致连接失败")
+	}
+}
+
+// TestScanMaps 测试查询结果扫描到map
+func TestScanMaps(t *testing.T) {
+	db := newTestORM(t)
+	seedAccounts(t, db,
+		Account{Name: "a", Balance: 10.5, Status: "active"},
+		Account{Name: "b", Balance: 20, Status: "frozen"},
+	)
+	if _, err := db.Exec("CREATE TABLE blobs (id INTEGER PRIMARY KEY, data BLOB)"); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO blobs (data) VALUES (?)", []byte{0x01, 0x02}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+
+	rows, err := db.Table("accounts").Select("status", "COUNT(*) AS total", "SUM(balance) AS amount").
+		GroupBy("status").OrderBy("status").GetMaps()
+	if err != nil {
+		t.Fatalf("查询map失败: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("期望2行，实际为 %v", rows)
+	}
+	if rows[0]["status"] != "active" || rows[0]["total"] != int64(1) || rows[0]["amount"] != 10.5 {
+		t.Errorf("map结果不符合预期: %#v", rows[0])
+	}
+
+	row, err := db.Table("accounts").Where("name = ?", "b").FirstMap()
+	if err != nil {
+		t.Fatalf("查询单条map失败: %v", err)
+	}
+	if row["name"] != "b" || row["id"] != int64(2) {
+		t.Errorf("单条map结果不符合预期: %#v", row)
+	}
+
+	blob, err := db.Table("blobs").FirstMap()
+	if data, ok := blob["data"].([]byte); err != nil || !ok || len(data) != 2 {
+		t.Errorf("二进制列应保留[]byte: %#v, err: %v", blob, err)
+	}
+
+	if _, err := db.Table("accounts").Where("name = ?", "none").FirstMap(); !errors.Is(err, orm.ErrRecordNotFound) {
+		t.Errorf("没有记录时应返回ErrRecordNotFound: %v", err)
+	}
+	if empty, err := db.Table("accounts").Where("name = ?", "none").GetMaps(); err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("没有记录时应返回空切片: %#v, err: %v", empty, err)
+	}
+}
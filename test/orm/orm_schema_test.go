@@ -0,0 +1,671 @@
+package orm_test
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fastgox/utils/orm"
+)
+
+// Invoice 用于测试结构生成的模型
+type Invoice struct {
+	ID         int64   `orm:"id,primary,auto_increment"`
+	AccountID  int64   `orm:"account_id,not_null,references:accounts.id,index"`
+	Number     string  `orm:"number,size:32,unique"`
+	Amount     float64 `orm:"amount,precision:12,scale:2"`
+	Currency   string  `orm:"currency,size:3,unique_index:uidx_invoice_number_currency"`
+	Status     string  `orm:"status,size:20,default:'draft',index:idx_invoice_status_created"`
+	CreatedRef int64   `orm:"created_ref,index:idx_invoice_status_created"`
+}
+
+// TestSchemaTags 测试size、精度、索引和外键标签
+func TestSchemaTags(t *testing.T) {
+	mysqlDB := orm.New(&orm.Config{Type: orm.MySQL})
+	info := orm.NewModelManager(mysqlDB).GetTableInfo(&Invoice{})
+
+	number := info.GetColumnByName("number")
+	if number == nil || number.Size != 32 || number.Type != "VARCHAR(32)" {
+		t.Errorf("size标签未生效: %+v", number)
+	}
+	amount := info.GetColumnByName("amount")
+	if amount == nil || amount.Type != "DECIMAL(12,2)" {
+		t.Errorf("precision标签未生效: %+v", amount)
+	}
+
+	indexes := info.Indexes()
+	if len(indexes) != 3 {
+		t.Fatalf("期望3个索引，实际为 %+v", indexes)
+	}
+	if indexes[0].Name != "idx_invoice_account_id" || indexes[0].Unique {
+		t.Errorf("默认索引名不符合预期: %+v", indexes[0])
+	}
+	if indexes[2].Name != "idx_invoice_status_created" || len(indexes[2].Columns) != 2 {
+		t.Errorf("复合索引不符合预期: %+v", indexes[2])
+	}
+
+	db := newTestORM(t)
+	if err := orm.NewModelManager(db).CreateTable(&Invoice{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+
+	var createSQL string
+	if err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'invoice'").Scan(&createSQL); err != nil {
+		t.Fatalf("读取建表语句失败: %v", err)
+	}
+	for _, part := range []string{"`number` TEXT UNIQUE", "DECIMAL(12,2)", "DEFAULT 'draft'", "FOREIGN KEY (`account_id`) REFERENCES `accounts` (`id`)"} {
+		if !strings.Contains(createSQL, part) {
+			t.Errorf("建表语句缺少 %s: %s", part, createSQL)
+		}
+	}
+
+	var indexCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = 'invoice' AND name LIKE '%idx_invoice%'").Scan(&indexCount); err != nil {
+		t.Fatalf("查询索引失败: %v", err)
+	}
+	if indexCount != 3 {
+		t.Errorf("期望创建3个索引，实际为 %d", indexCount)
+	}
+}
+
+// InvoiceLine 带级联删除外键的模型
+type InvoiceLine struct {
+	ID        int64 `orm:"id,primary,auto_increment"`
+	InvoiceID int64 `orm:"invoice_id,not_null,references:invoice.id,on_delete:cascade,on_update:cascade"`
+}
+
+// TestForeignKeyActions 测试外键的删除、更新动作
+func TestForeignKeyActions(t *testing.T) {
+	db := newTestORM(t)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatalf("开启外键约束失败: %v", err)
+	}
+
+	err := orm.NewSchema(db).CreateTable("transfers", func(table orm.TableInterface) {
+		table.Integer("id").Primary("id")
+		table.Integer("from_id").Foreign("from_id", "accounts.id").OnDelete("cascade")
+		table.Integer("to_id").Nullable().Foreign("to_id", "accounts.id").OnDelete("SET NULL")
+	})
+	if err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO accounts (id, name, balance, status) VALUES (1, 'a', 0, 1), (2, 'b', 0, 1), (3, 'c', 0, 1)"); err != nil {
+		t.Fatalf("插入账户失败: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO transfers (id, from_id, to_id) VALUES (1, 1, 2), (2, 3, 1)"); err != nil {
+		t.Fatalf("插入转账失败: %v", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM accounts WHERE id = 1"); err != nil {
+		t.Fatalf("删除账户失败: %v", err)
+	}
+	var toID sql.NullInt64
+	if err := db.QueryRow("SELECT to_id FROM transfers WHERE id = 2").Scan(&toID); err != nil || toID.Valid {
+		t.Errorf("SET NULL外键应被置空: %v, err: %v", toID, err)
+	}
+	if count, _ := db.Table("transfers").Count(); count != 1 {
+		t.Errorf("CASCADE外键应删除关联记录，剩余 %d 条", count)
+	}
+
+	if err := orm.NewModelManager(db).CreateTable(&InvoiceLine{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	var createSQL string
+	if err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'invoice_line'").Scan(&createSQL); err != nil {
+		t.Fatalf("读取建表语句失败: %v", err)
+	}
+	if !strings.Contains(createSQL, "REFERENCES `invoice` (`id`) ON DELETE CASCADE ON UPDATE CASCADE") {
+		t.Errorf("标签声明的外键动作未生效: %s", createSQL)
+	}
+}
+
+// AuditLog 声明表级选项的模型
+type AuditLog struct {
+	ID      int64  `orm:"id,primary,auto_increment"`
+	Message string `orm:"message"`
+}
+
+// TableOptions 表级选项
+func (AuditLog) TableOptions() orm.TableOptions {
+	return orm.TableOptions{Engine: "InnoDB", Charset: "utf8mb4", Comment: "审计日志"}
+}
+
+// TestTableOptions 测试存储引擎、字符集、排序规则和表注释
+func TestTableOptions(t *testing.T) {
+	mysqlDB := orm.New(&orm.Config{Type: orm.MySQL})
+	table := orm.NewTableBuilder("events", mysqlDB)
+	table.BigInteger("id").Primary("id")
+	table.Engine("InnoDB").Charset("utf8mb4").Collation("utf8mb4_unicode_ci").TableComment("事件")
+	createSQL := table.(interface{ ToSQL() string }).ToSQL()
+	if !strings.HasSuffix(createSQL, ") ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_unicode_ci COMMENT = '事件'") {
+		t.Errorf("MySQL表级选项不符合预期: %s", createSQL)
+	}
+
+	if options := orm.NewModelManager(mysqlDB).GetTableInfo(&AuditLog{}).Options; options.Engine != "InnoDB" || options.Comment != "审计日志" {
+		t.Errorf("模型声明的表级选项未生效: %+v", options)
+	}
+
+	// SQLite不支持表级选项，建表时忽略
+	db := newTestORM(t)
+	if err := orm.NewModelManager(db).CreateTable(&AuditLog{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if exists, _ := orm.NewSchema(db).HasTable("audit_log"); !exists {
+		t.Error("应创建audit_log表")
+	}
+}
+
+// Category 未实现TableName()的模型，表名由命名策略生成
+type Category struct {
+	ID   int64  `orm:"id,primary,auto_increment"`
+	Name string `orm:"name,size:50"`
+}
+
+// TestNamingStrategy 测试表前缀和命名策略
+func TestNamingStrategy(t *testing.T) {
+	plural := map[string]string{"Category": "categories", "UserProfile": "user_profiles", "Box": "boxes", "Status": "statuses", "Key": "keys"}
+	for name, want := range plural {
+		if got := (orm.PluralNaming{}).TableName(name); got != want {
+			t.Errorf("%s: 期望复数表名为 %s，实际为 %s", name, want, got)
+		}
+	}
+
+	db := orm.New(&orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1, TablePrefix: "app_", NamingStrategy: orm.PluralNaming{}})
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if err := orm.NewModelManager(db).CreateTable(&Category{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+	if exists, _ := orm.NewSchema(db).HasTable("app_categories"); !exists {
+		t.Fatal("应按表前缀和复数命名创建app_categories表")
+	}
+	if err := db.Model(&Category{}).Insert(&Category{Name: "书籍"}); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	err := orm.NewTransactionManager(db).WithTransaction(func(tx orm.Tx) error {
+		return tx.Model(&Category{}).Insert(&Category{Name: "音乐"})
+	})
+	if err != nil {
+		t.Fatalf("事务中插入失败: %v", err)
+	}
+	if count, err := db.Table("app_categories").Count(); err != nil || count != 2 {
+		t.Errorf("期望2条记录，实际 %d 条, err: %v", count, err)
+	}
+
+	// 实现了TableName()的模型不受前缀和命名策略影响
+	if query, _ := db.Model(&Account{}).ToSQL(); !strings.Contains(query, "`accounts`") {
+		t.Errorf("TableName()返回的表名应原样使用: %s", query)
+	}
+
+	custom := orm.New(&orm.Config{Type: orm.SQLite, NamingStrategy: orm.NamingFunc(func(name string) string {
+		return "t_" + strings.ToLower(name)
+	})})
+	if query, _ := custom.Model(&Category{}).ToSQL(); !strings.Contains(query, "`t_category`") {
+		t.Errorf("自定义命名策略未生效: %s", query)
+	}
+}
+
+// BadSizeTag 带无效size标签的模型
+type BadSizeTag struct {
+	ID   int64  `orm:"id,primary"`
+	Code string `orm:"code,size:abc"`
+}
+
+// BadScaleTag scale大于precision的模型
+type BadScaleTag struct {
+	ID     int64   `orm:"id,primary"`
+	Amount float64 `orm:"amount,precision:4,scale:6"`
+}
+
+// BadEmbeddedTag 嵌入结构体中带无效precision标签的模型
+type BadEmbeddedTag struct {
+	ID int64 `orm:"id,primary"`
+	BadPrecision
+}
+
+// BadPrecision 带无效precision标签的嵌入结构体
+type BadPrecision struct {
+	Rate float64 `orm:"rate,precision:0"`
+}
+
+// TestInvalidNumericTags 测试无效的size、precision、scale标签在建表前报错
+func TestInvalidNumericTags(t *testing.T) {
+	db := newTestORM(t)
+	mm := orm.NewModelManager(db)
+
+	if err := mm.CreateTable(&BadSizeTag{}); err == nil || !strings.Contains(err.Error(), "size:abc") {
+		t.Errorf("无效的size标签应返回错误: %v", err)
+	}
+	if _, err := mm.AutoMigratePlan(&BadScaleTag{}); err == nil {
+		t.Error("scale大于precision时应返回错误")
+	}
+	if exists, _ := mm.HasTable(&BadSizeTag{}); exists {
+		t.Error("标签无效时不应建表")
+	}
+	if err := mm.AutoMigrate(&BadEmbeddedTag{}); err == nil || !strings.Contains(err.Error(), "precision:0") {
+		t.Errorf("嵌入结构体中的无效标签应返回错误: %v", err)
+	}
+	if err := orm.RegisterModel(&Invoice{}, &BadScaleTag{}); err == nil || !strings.Contains(err.Error(), "BadScaleTag.Amount") {
+		t.Errorf("注册带无效标签的模型应返回错误: %v", err)
+	}
+	if err := orm.RegisterModel(&Invoice{}); err != nil {
+		t.Errorf("注册有效模型不应返回错误: %v", err)
+	}
+}
+
+// TestAutoMigrateDiff 测试自动迁移补齐缺失的列和索引
+func TestAutoMigrateDiff(t *testing.T) {
+	config := &orm.Config{Type: orm.SQLite, Database: ":memory:", MaxOpenConns: 1, MaxIdleConns: 1}
+	db := orm.New(config)
+	if err := db.Connect(); err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE invoice (id INTEGER PRIMARY KEY AUTOINCREMENT, account_id INTEGER, number TEXT)`); err != nil {
+		t.Fatalf("创建旧表失败: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO invoice (account_id, number) VALUES (1, 'A-1')`); err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+
+	mm := orm.NewModelManager(db)
+	plan, err := mm.AutoMigratePlan(&Invoice{})
+	if err != nil {
+		t.Fatalf("生成迁移计划失败: %v", err)
+	}
+
+	// 缺少 amount、currency、status、created_ref 四列和三个索引
+	if len(plan) != 7 {
+		t.Fatalf("期望7条迁移语句，实际为 %d: %v", len(plan), plan)
+	}
+	if !strings.Contains(plan[2], "ADD COLUMN `status` TEXT DEFAULT 'draft'") {
+		t.Errorf("新增列语句不符合预期: %s", plan[2])
+	}
+
+	// 安全模式执行新增列和索引的语句
+	config.MigrateSafeMode = true
+	if err := mm.AutoMigrate(&Invoice{}); err != nil {
+		t.Fatalf("安全模式自动迁移失败: %v", err)
+	}
+	plan, err = mm.AutoMigratePlan(&Invoice{})
+	if err != nil || len(plan) != 0 {
+		t.Errorf("迁移后不应再有变更: %v, err: %v", plan, err)
+	}
+
+	var status string
+	if err := db.QueryRow("SELECT status FROM invoice WHERE id = 1").Scan(&status); err != nil || status != "draft" {
+		t.Errorf("已有数据应使用默认值，实际为 %q, err: %v", status, err)
+	}
+
+	// 列类型变化时生成修改列语句，SQLite不支持修改列
+	column := orm.ColumnDefinition{Name: "number", Type: "VARCHAR(64)", NotNull: true}
+	expected := map[orm.DatabaseType]string{
+		orm.MySQL:      "ALTER TABLE `invoice` MODIFY COLUMN `number` VARCHAR(64) NOT NULL",
+		orm.PostgreSQL: `ALTER TABLE "invoice" ALTER COLUMN "number" TYPE VARCHAR(64)`,
+		orm.SQLite:     "",
+		orm.SQLServer:  "ALTER TABLE [invoice] ALTER COLUMN [number] VARCHAR(64) NOT NULL",
+		orm.ClickHouse: "ALTER TABLE `invoice` MODIFY COLUMN `number` VARCHAR(64)",
+		orm.Oracle:     `ALTER TABLE "invoice" MODIFY ("number" VARCHAR(64))`,
+	}
+	for dbType, want := range expected {
+		dialect := orm.NewDatabaseManager(orm.New(&orm.Config{Type: dbType})).GetDialect()
+		if got := dialect.ModifyColumnSQL("invoice", "number", column); got != want {
+			t.Errorf("%s: 期望SQL为 %q，实际为 %q", dbType, want, got)
+		}
+	}
+}
+
+// TestSchemaIntrospection 测试读取已有表、列和索引的结构
+func TestSchemaIntrospection(t *testing.T) {
+	db := newTestORM(t)
+	for _, statement := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, account_id INTEGER NOT NULL, code VARCHAR(20) NOT NULL UNIQUE, state VARCHAR(10) DEFAULT 'new', note TEXT)",
+		"CREATE INDEX idx_orders_account_state ON orders (account_id, state)",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("建表失败: %v", err)
+		}
+	}
+	schema := orm.NewSchema(db)
+
+	tables, err := schema.ListTables()
+	if err != nil || strings.Join(tables, ",") != "accounts,orders" {
+		t.Errorf("表名列表不符合预期: %v, err: %v", tables, err)
+	}
+
+	columns, err := schema.ListColumns("orders")
+	if err != nil || len(columns) != 5 {
+		t.Fatalf("列信息不符合预期: %+v, err: %v", columns, err)
+	}
+	if columns[0].Name != "id" || !columns[0].Primary || columns[0].Nullable {
+		t.Errorf("主键列信息不符合预期: %+v", columns[0])
+	}
+	if columns[2].Type != "VARCHAR(20)" || columns[2].Nullable {
+		t.Errorf("code列信息不符合预期: %+v", columns[2])
+	}
+	if columns[3].Default == nil || *columns[3].Default != "'new'" || !columns[3].Nullable {
+		t.Errorf("state列应有默认值且可空: %+v", columns[3])
+	}
+	if columns[4].Default != nil {
+		t.Errorf("note列不应有默认值: %v", *columns[4].Default)
+	}
+
+	indexes, err := schema.ListIndexes("orders")
+	if err != nil || len(indexes) != 2 {
+		t.Fatalf("索引信息不符合预期: %+v, err: %v", indexes, err)
+	}
+	composite := indexes[0]
+	if composite.Name != "idx_orders_account_state" || composite.Unique || strings.Join(composite.Columns, ",") != "account_id,state" {
+		t.Errorf("组合索引信息不符合预期: %+v", composite)
+	}
+	if !indexes[1].Unique || strings.Join(indexes[1].Columns, ",") != "code" {
+		t.Errorf("唯一约束生成的索引不符合预期: %+v", indexes[1])
+	}
+}
+
+// TestSQLMigrations 测试SQL迁移及从文件系统加载迁移文件
+func TestSQLMigrations(t *testing.T) {
+	db := newTestORM(t)
+
+	files := fstest.MapFS{
+		"migrations/0001_create_tags.up.sql": {Data: []byte(`
+-- 标签表
+CREATE TABLE tags (id INTEGER PRIMARY KEY, name VARCHAR(50));
+INSERT INTO tags (name) VALUES ('a;b');
+`)},
+		"migrations/0001_create_tags.down.sql": {Data: []byte("DROP TABLE tags;")},
+		"migrations/0002_add_tag_color.up.sql": {Data: []byte("ALTER TABLE tags ADD COLUMN color VARCHAR(20)")},
+		"migrations/README.md":                 {Data: []byte("ignored")},
+	}
+
+	manager := orm.NewMigrationManager(db)
+	if err := manager.LoadSQLMigrations(files, "migrations"); err != nil {
+		t.Fatalf("加载迁移文件失败: %v", err)
+	}
+	manager.AddMigration(orm.NewSQLMigration("0003_seed_tags",
+		"INSERT INTO tags (name, color) VALUES ('go', 'blue')",
+		"DELETE FROM tags WHERE name = 'go'"))
+
+	if err := manager.Run(); err != nil {
+		t.Fatalf("运行迁移失败: %v", err)
+	}
+
+	var names []string
+	if err := db.Table("tags").OrderBy("id").Pluck("name", &names); err != nil {
+		t.Fatalf("查询迁移结果失败: %v", err)
+	}
+	if len(names) != 2 || names[0] != "a;b" || names[1] != "go" {
+		t.Errorf("迁移结果不符合预期: %v", names)
+	}
+
+	if err := manager.Rollback(1); err != nil {
+		t.Fatalf("回滚迁移失败: %v", err)
+	}
+	if count, _ := db.Table("tags").Count(); count != 1 {
+		t.Errorf("回滚后期望1条记录，实际为 %d", count)
+	}
+
+	// 0002没有下迁移文件
+	if err := manager.Rollback(1); err == nil {
+		t.Error("缺少下迁移SQL时应返回错误")
+	}
+
+	missingUp := fstest.MapFS{"0001_only_down.down.sql": {Data: []byte("SELECT 1")}}
+	if err := orm.NewMigrationManager(db).LoadSQLMigrations(missingUp, "."); err == nil {
+		t.Error("缺少上迁移文件时应返回错误")
+	}
+}
+
+// TestSQLMigrationStatements 测试SQL迁移脚本按语句拆分时保留函数体、触发器块和条件注释
+func TestSQLMigrationStatements(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name: "美元符号引用",
+			script: "CREATE FUNCTION touch() RETURNS trigger AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;\n" +
+				"CREATE FUNCTION noop() RETURNS void AS $body$ SELECT 1; $body$ LANGUAGE sql; SELECT $1",
+			want: []string{
+				"CREATE FUNCTION touch() RETURNS trigger AS $$ BEGIN NEW.updated_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql",
+				"CREATE FUNCTION noop() RETURNS void AS $body$ SELECT 1; $body$ LANGUAGE sql",
+				"SELECT $1",
+			},
+		},
+		{
+			name: "触发器语句块",
+			script: "CREATE TRIGGER audit AFTER INSERT ON labels FOR EACH ROW BEGIN\n" +
+				"  INSERT INTO audit (name) VALUES (CASE WHEN NEW.name = '' THEN 'none' ELSE NEW.name END);\n" +
+				"  IF NEW.id > 0 THEN SET @n = 1; END IF;\nEND;\nBEGIN; COMMIT",
+			want: []string{
+				"CREATE TRIGGER audit AFTER INSERT ON labels FOR EACH ROW BEGIN\n" +
+					"  INSERT INTO audit (name) VALUES (CASE WHEN NEW.name = '' THEN 'none' ELSE NEW.name END);\n" +
+					"  IF NEW.id > 0 THEN SET @n = 1; END IF;\nEND",
+				"BEGIN",
+				"COMMIT",
+			},
+		},
+		{
+			name:   "条件注释和提示",
+			script: "/* 普通注释; */ CREATE TABLE t (id INT) /*!50100 ENGINE=InnoDB */; SELECT /*+ MAX_EXECUTION_TIME(1000) */ id FROM t",
+			want:   []string{"CREATE TABLE t (id INT) /*!50100 ENGINE=InnoDB */", "SELECT /*+ MAX_EXECUTION_TIME(1000) */ id FROM t"},
+		},
+		{
+			name:   "不拆分指令",
+			script: "-- orm:no-split\nDELIMITER ;; SELECT 1;; SELECT 2",
+			want:   []string{"-- orm:no-split\nDELIMITER ;; SELECT 1;; SELECT 2"},
+		},
+	}
+	for _, c := range cases {
+		got := orm.NewSQLMigration("0001", c.script, "").UpStatements()
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: 拆分结果不符合预期:\n%q\n期望:\n%q", c.name, got, c.want)
+		}
+	}
+
+	// SQLite触发器作为一条语句执行
+	db := newTestORM(t)
+	manager := orm.NewMigrationManager(db)
+	manager.AddMigration(orm.NewSQLMigration("0001_audit", `
+CREATE TABLE labels (id INTEGER PRIMARY KEY, name VARCHAR(50));
+CREATE TABLE audit (name VARCHAR(50));
+CREATE TRIGGER labels_audit AFTER INSERT ON labels BEGIN
+    INSERT INTO audit (name) VALUES (NEW.name);
+    INSERT INTO audit (name) VALUES (CASE WHEN NEW.name = 'a' THEN 'first' ELSE 'other' END);
+END;
+INSERT INTO labels (name) VALUES ('a');`, ""))
+	if err := manager.Run(); err != nil {
+		t.Fatalf("运行触发器迁移失败: %v", err)
+	}
+	if count, _ := db.Table("audit").Count(); count != 2 {
+		t.Errorf("触发器应写入2条审计记录，实际为 %d", count)
+	}
+}
+
+// TestMigrationPlan 测试迁移计划和DryRun不修改数据库
+func TestMigrationPlan(t *testing.T) {
+	db := newTestORM(t)
+
+	manager := orm.NewMigrationManager(db)
+	manager.AddMigration(orm.NewSQLMigration("0002_seed_labels", "INSERT INTO labels (name) VALUES ('a;b'); INSERT INTO labels (name) VALUES ('c')", ""))
+	manager.AddMigration(orm.NewSQLMigration("0001_create_labels", "CREATE TABLE labels (id INTEGER PRIMARY KEY, name VARCHAR(50))", "DROP TABLE labels"))
+	manager.AddMigration(orm.NewBaseMigration("0003_code", db))
+
+	plans, err := manager.Plan()
+	if err != nil {
+		t.Fatalf("生成迁移计划失败: %v", err)
+	}
+	if len(plans) != 3 || plans[0].Version != "0001_create_labels" || plans[2].Version != "0003_code" {
+		t.Fatalf("迁移计划应按版本排序: %+v", plans)
+	}
+	if len(plans[1].Statements) != 2 || plans[1].Statements[0] != "INSERT INTO labels (name) VALUES ('a;b')" {
+		t.Errorf("SQL迁移应列出拆分后的语句: %q", plans[1].Statements)
+	}
+	if plans[2].Statements != nil {
+		t.Errorf("代码迁移无法预览SQL: %q", plans[2].Statements)
+	}
+
+	if err := manager.DryRun().Run(); err != nil {
+		t.Fatalf("DryRun失败: %v", err)
+	}
+	for _, table := range []string{"labels", "migrations"} {
+		if count, _ := db.Table("sqlite_master").Where("type = 'table' AND name = ?", table).Count(); count != 0 {
+			t.Errorf("DryRun不应创建表 %s", table)
+		}
+	}
+}
+
+// TestSeeders 测试数据填充的环境选择和重复运行
+func TestSeeders(t *testing.T) {
+	db := newTestORM(t)
+
+	seedAccount := func(name string) func(tx orm.Tx) error {
+		return func(tx orm.Tx) error {
+			_, err := tx.Exec("INSERT INTO accounts (name, balance, status) VALUES (?, 0, 1)", name)
+			return err
+		}
+	}
+	seeders := []orm.Seeder{
+		orm.NewSeeder("admin", seedAccount("admin")),
+		orm.NewSeeder("demo", seedAccount("demo"), "development", "testing"),
+		orm.NewSeeder("broken", func(tx orm.Tx) error {
+			if _, err := tx.Exec("INSERT INTO accounts (name, balance, status) VALUES ('partial', 0, 1)"); err != nil {
+				return err
+			}
+			return errors.New("填充失败")
+		}, "staging"),
+	}
+
+	run := func(environment string) error {
+		manager := orm.NewSeedManager(db).Environment(environment)
+		manager.AddSeeder(seeders...)
+		return manager.Run()
+	}
+	names := func() []string {
+		var names []string
+		db.Table("accounts").OrderBy("id").Pluck("name", &names)
+		return names
+	}
+
+	if err := run("production"); err != nil {
+		t.Fatalf("数据填充失败: %v", err)
+	}
+	if got := names(); len(got) != 1 || got[0] != "admin" {
+		t.Errorf("production环境只应执行未限定环境的填充: %v", got)
+	}
+
+	// 已执行的填充不重复执行
+	if err := run("testing"); err != nil {
+		t.Fatalf("数据填充失败: %v", err)
+	}
+	if got := names(); len(got) != 2 || got[1] != "demo" {
+		t.Errorf("testing环境应只新增demo: %v", got)
+	}
+
+	// 失败的填充整体回滚，不记录为已执行
+	if err := run("staging"); err == nil {
+		t.Fatal("填充返回错误时Run应失败")
+	}
+	if got := names(); len(got) != 2 {
+		t.Errorf("失败的填充应回滚: %v", got)
+	}
+	if count, _ := db.Table("seeds").Count(); count != 2 {
+		t.Errorf("应记录2个已执行的填充，实际为 %d", count)
+	}
+}
+
+// TestCreateSQLMigrationFiles 测试创建迁移文件模板
+func TestCreateSQLMigrationFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	upPath, downPath, err := orm.CreateSQLMigrationFiles(dir, "AddUserPhone")
+	if err != nil {
+		t.Fatalf("创建迁移文件失败: %v", err)
+	}
+	if !strings.HasSuffix(upPath, "_add_user_phone.up.sql") || !strings.HasSuffix(downPath, "_add_user_phone.down.sql") {
+		t.Errorf("迁移文件名不符合预期: %s, %s", upPath, downPath)
+	}
+
+	if err := orm.NewMigrationManager(newTestORM(t)).LoadSQLMigrationsFromDir(dir); err != nil {
+		t.Errorf("加载新建的迁移文件失败: %v", err)
+	}
+
+	if _, _, err := orm.CreateSQLMigrationFiles(dir, " "); err == nil {
+		t.Error("迁移名称为空时应返回错误")
+	}
+}
+
+// UserSession 使用UUID主键的模型
+type UserSession struct {
+	ID     string `orm:"id,primary,uuid"`
+	Token  string `orm:"token,uuid:v7"`
+	UserID int64  `orm:"user_id"`
+}
+
+// TestUUIDPrimaryKey 测试UUID主键的列类型和插入时自动生成
+func TestUUIDPrimaryKey(t *testing.T) {
+	postgres := orm.NewModelManager(orm.New(&orm.Config{Type: orm.PostgreSQL})).GetTableInfo(&UserSession{})
+	if column := postgres.GetColumnByName("id"); column == nil || column.Type != "UUID" {
+		t.Errorf("PostgreSQL的UUID列类型应为UUID: %+v", column)
+	}
+	mysql := orm.NewModelManager(orm.New(&orm.Config{Type: orm.MySQL})).GetTableInfo(&UserSession{})
+	if column := mysql.GetColumnByName("token"); column == nil || column.Type != "CHAR(36)" {
+		t.Errorf("MySQL的UUID列类型应为CHAR(36): %+v", column)
+	}
+
+	db := newTestORM(t)
+	if err := orm.NewModelManager(db).CreateTable(&UserSession{}); err != nil {
+		t.Fatalf("创建表失败: %v", err)
+	}
+
+	session := &UserSession{UserID: 1}
+	if err := db.Model(&UserSession{}).Insert(session); err != nil {
+		t.Fatalf("插入失败: %v", err)
+	}
+	if len(session.ID) != 36 || session.ID[14] != '4' || len(session.Token) != 36 || session.Token[14] != '7' {
+		t.Errorf("UUID未按版本生成: %+v", session)
+	}
+
+	batch := []*UserSession{{UserID: 2}, {ID: "00000000-0000-4000-8000-000000000001", UserID: 3}}
+	if err := db.Model(&UserSession{}).InsertBatch(batch); err != nil {
+		t.Fatalf("批量插入失败: %v", err)
+	}
+	if batch[0].ID == "" || batch[0].ID == session.ID || batch[1].ID != "00000000-0000-4000-8000-000000000001" {
+		t.Errorf("批量插入应只为空主键生成UUID: %+v, %+v", batch[0], batch[1])
+	}
+
+	var loaded UserSession
+	if err := db.Model(&UserSession{}).FindByID(session.ID, &loaded); err != nil || loaded.Token != session.Token {
+		t.Errorf("按UUID主键查询失败: %+v, err: %v", loaded, err)
+	}
+}
+
+// TestModelMetadataCache 测试模型元数据缓存
+func TestModelMetadataCache(t *testing.T) {
+	orm.RegisterModel(&Invoice{}, &UserSession{})
+
+	mm := orm.NewModelManager(orm.New(&orm.Config{Type: orm.MySQL}))
+	first := mm.GetTableInfo(&Invoice{})
+	first.Columns[0].Name = "changed"
+
+	second := mm.GetTableInfo(Invoice{})
+	if second.Columns[0].Name != "id" || len(second.Columns) != len(first.Columns) {
+		t.Errorf("修改返回的表信息不应影响缓存: %+v", second.Columns[0])
+	}
+	if column := second.GetColumnByName("number"); column == nil || column.Type != "VARCHAR(32)" {
+		t.Errorf("缓存的列信息不符合预期: %+v", column)
+	}
+
+	sqlite := orm.NewModelManager(orm.New(&orm.Config{Type: orm.SQLite})).GetTableInfo(&Invoice{})
+	if column := sqlite.GetColumnByName("number"); column == nil || column.Type != "TEXT" {
+		t.Errorf("不同数据库应分别缓存列类型: %+v", column)
+	}
+}